@@ -0,0 +1,97 @@
+package main
+
+// Verifica a integridade (checksum e cadeia do histórico do ApiInfo) de uma coleção do MongoDB ou de uma tabela do MySQL.
+//
+// Uso:
+//
+//	go run main/integrity.go -driver mongo -database app -collection account
+//	go run main/integrity.go -driver mysql -table account -id id
+//
+// As conexões utilizam as variáveis de ambiente MONGODB_URI e MYSQL_URI, carregadas também do arquivo .env, e os
+// checksums são verificados com a chave API_CHECKSUM_SECRET utilizada pela aplicação.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/mongo"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"github.com/joho/godotenv"
+	"log"
+	"os"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run verifica a integridade e retorna o código de saída: 0 sem falhas, 1 com falhas de integridade e 2 em caso de erro.
+// As conexões são fechadas antes do retorno.
+func run() int {
+	driver := flag.String("driver", "mongo", "banco de dados: mongo ou mysql")
+	database := flag.String("database", "", "banco de dados do MongoDB")
+	collection := flag.String("collection", "", "coleção do MongoDB")
+	table := flag.String("table", "", "tabela do MySQL")
+	idColumn := flag.String("id", "id", "coluna identificadora da tabela do MySQL")
+	batchSize := flag.Int("batch", 500, "quantidade de linhas lidas por lote no MySQL")
+	flag.Parse()
+
+	// O arquivo .env é opcional, as variáveis podem estar definidas no ambiente
+	_ = godotenv.Load()
+
+	ctx := context.Background()
+	var (
+		violations []info.Violation
+		count      int
+		err        error
+	)
+
+	switch *driver {
+	case "mongo":
+		if *database == "" || *collection == "" {
+			log.Print("Informe -database e -collection")
+			return 2
+		}
+		db, connectErr := mongo.NewDB(os.Getenv("MONGODB_URI"))
+		if connectErr != nil {
+			log.Printf("Falha ao conectar-se ao MongoDB: %v", connectErr)
+			return 2
+		}
+		defer db.Close()
+		violations, count, err = info.NewMongoWriter(db.Collection(*database, *collection)).Verify(ctx)
+	case "mysql":
+		if *table == "" {
+			log.Print("Informe -table")
+			return 2
+		}
+		db, connectErr := mysql.NewDB(os.Getenv("MYSQL_URI"))
+		if connectErr != nil {
+			log.Printf("Falha ao conectar-se ao MySQL: %v", connectErr)
+			return 2
+		}
+		defer db.Close()
+		violations, count, err = info.NewMysqlWriter(db, *table, *idColumn).Verify(ctx, *batchSize)
+	default:
+		log.Printf("Banco de dados não suportado: %s", *driver)
+		return 2
+	}
+	if err != nil {
+		log.Printf("Falha ao verificar a integridade: %v", err)
+		return 2
+	}
+
+	for _, violation := range violations {
+		if violation.Index >= 0 {
+			fmt.Printf("%s\t%s\thistory[%d]\n", violation.ID, violation.Reason, violation.Index)
+		} else {
+			fmt.Printf("%s\t%s\n", violation.ID, violation.Reason)
+		}
+	}
+	fmt.Printf("Documentos verificados: %d, falhas: %d\n", count, len(violations))
+
+	if len(violations) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -23,6 +23,10 @@ type ActionModel struct {
 	Package  string
 	Project  string
 	Type     string
+	// Lifecycle é a ação de ciclo de vida (disable, enable ou restore) das mutações geradas pelo campo status
+	Lifecycle string
+	// Clone indica a mutação de clonagem gerada pelo campo cloneType
+	Clone bool
 }
 
 type ArgModel struct {
@@ -48,6 +52,9 @@ type ResultModel struct {
 	isList         bool
 	isRequerid     bool
 	isListRequerid bool
+	errorField     string
+	errorType      string
+	isErrorList    bool
 }
 
 var re = regexp.MustCompile(`type\s[\s\S].*\s\{`)
@@ -65,6 +72,21 @@ var reIsListRequired = regexp.MustCompile(`\[\w+!\]!|\[\w+\]!`)
 var reApi = regexp.MustCompile(`Api`)
 var regexRemoveSchemas = regexp.MustCompile(`\\schemas`)
 var regexIsImplemented = regexp.MustCompile(`not implemented`)
+var regexFindError = regexp.MustCompile(`\s(errors?):\s*(\[?\w+!?\]?!?)`)
+
+const importApiError = "api_error \"github.com/coocree/coocree_apiconnect_go/modules/api_connect/error\""
+const importI18n = "\"github.com/coocree/coocree_apiconnect_go/modules/api_connect/i18n\""
+const importAudit = "\"github.com/coocree/coocree_apiconnect_go/modules/api_connect/audit\""
+const importLifecycle = "\"github.com/coocree/coocree_apiconnect_go/modules/api_connect/lifecycle\""
+
+// lifecycleActions são as mutações geradas para os módulos com o campo status: StatusEnabledDisabledEnum
+var lifecycleActions = []string{"Disable", "Enable", "Restore"}
+
+var regexLifecycleStatus = regexp.MustCompile(`\bstatus\s*:\s*StatusEnabledDisabledEnum\b`)
+
+const importClone = "\"github.com/coocree/coocree_apiconnect_go/modules/api_connect/clone\""
+
+var regexCloneType = regexp.MustCompile(`\bcloneType\s*:\s*StatusCloneTypeEnum\b`)
 
 func contentClean(content []byte) string {
 	// Converte o conteúdo de bytes para string
@@ -191,6 +213,96 @@ func createFileModel(fileType string) {
 	}
 }
 
+// createLifecycleActions adiciona as mutações de ciclo de vida (disable, enable e restore) aos módulos
+// que declaram o campo status: StatusEnabledDisabledEnum no type.graphqls
+func createLifecycleActions() {
+	for _, module := range findModulesType(regexLifecycleStatus) {
+		for _, action := range lifecycleActions {
+			addMutationAction(module, ActionModel{
+				Name:      actionName(module.Project, module.Package, action),
+				Args:      []ArgModel{{Name: "input", Type: "ApiLifecycleInput", isRequerid: true}},
+				Response:  "ApiChangeResponse",
+				Lifecycle: action,
+			})
+		}
+	}
+}
+
+// createCloneActions adiciona a mutação de clonagem aos módulos que declaram o campo
+// cloneType: StatusCloneTypeEnum no type.graphqls
+func createCloneActions() {
+	for _, module := range findModulesType(regexCloneType) {
+		addMutationAction(module, ActionModel{
+			Name:     actionName(module.Project, module.Package, "Clone"),
+			Args:     []ArgModel{{Name: "input", Type: "ApiCloneInput", isRequerid: true}},
+			Response: "ApiChangeResponse",
+			Clone:    true,
+		})
+	}
+}
+
+// findModulesType retorna os módulos, exceto os do api_connect, cujo type.graphqls atende à expressão regular
+func findModulesType(regex *regexp.Regexp) []MutationQueryFileModel {
+	var modules []MutationQueryFileModel
+	files, _ := findFilesType("./", "type.graphqls")
+	for _, pathFile := range files {
+		content, err := os.ReadFile(pathFile)
+		if err != nil || !regex.MatchString(contentClean(content)) {
+			continue
+		}
+
+		// O caminho do arquivo é modules/<projeto>/<pacote>/schemas/type.graphqls
+		path := filepath.Dir(filepath.Dir(pathFile))
+		project := filepath.Base(filepath.Dir(path))
+		if project == "api_connect" {
+			continue
+		}
+		modules = append(modules, MutationQueryFileModel{
+			Name:    "service_mutation.go",
+			Type:    "mutation",
+			Path:    path,
+			Package: filepath.Base(path),
+			Project: project,
+		})
+	}
+	return modules
+}
+
+// addMutationAction adiciona a ação ao modelo de mutações do módulo, ou a um novo modelo caso o módulo
+// não declare mutações, exceto se a ação já estiver declarada no mutation.graphqls
+func addMutationAction(module MutationQueryFileModel, action ActionModel) {
+	index := -1
+	for i, fileModel := range listMutationQueryFile {
+		if fileModel.Type == "mutation" && fileModel.Project == module.Project && fileModel.Package == module.Package {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		listMutationQueryFile = append(listMutationQueryFile, module)
+		index = len(listMutationQueryFile) - 1
+	}
+
+	for _, actionModel := range listMutationQueryFile[index].Actions {
+		if actionModel.Name == action.Name {
+			return
+		}
+	}
+	listMutationQueryFile[index].Actions = append(listMutationQueryFile[index].Actions, action)
+}
+
+// actionName retorna o nome da mutação gerada para o projeto/pacote, por exemplo app, user_profile e Disable:
+// appUserProfileDisable. O projeto evita mutações duplicadas entre pacotes de mesmo nome em projetos diferentes.
+func actionName(project string, packageName string, action string) string {
+	parts := strings.Split(project+"_"+packageName, "_")
+	for index := 1; index < len(parts); index++ {
+		if parts[index] != "" {
+			parts[index] = fistUpperCase(parts[index])
+		}
+	}
+	return strings.Join(parts, "") + action
+}
+
 func createListResult() {
 	// Expressão regular para encontrar a definição de tipo de resultado
 	var regexFindResult = regexp.MustCompile(`type[\s|\S].+\{[\s|\S]+?\}`)
@@ -361,6 +473,14 @@ func processResponse(values []string, pathFile string) {
 			resultModel.isListRequerid = reIsListRequired.MatchString(itemResult)
 			resultModel.isRequerid = reIsRequired.MatchString(itemResult)
 			resultModel.Name = strings.TrimSpace(re12.ReplaceAllString(itemResult, ""))
+
+			// Identifica o campo de erro do response (error: String, error: ApiErrorType ou errors: [ApiErrorType!])
+			errorMatch := regexFindError.FindStringSubmatch(item)
+			if len(errorMatch) > 2 {
+				resultModel.errorField = errorMatch[1]
+				resultModel.errorType = strings.TrimSpace(re12.ReplaceAllString(errorMatch[2], ""))
+				resultModel.isErrorList = reIsList.MatchString(errorMatch[2])
+			}
 			listResponseModel[responseName] = resultModel
 
 			// Verifica se o modelo de resultado possui a palavra "Result" no nome
@@ -647,29 +767,23 @@ func renderServiceItem(buffer *bytes.Buffer, actionModel ActionModel, nameMethod
 	_, _ = fmt.Fprint(buffer, "\t_success := true\n")
 
 	// Inicializa a variável que indicará se a ação teve um erro
-	_, _ = fmt.Fprint(buffer, "\t_error := \"\"\n\n")
-
-	// Adiciona um panic para indicar que o método ainda não foi implementado
-	_, _ = fmt.Fprint(buffer, "\tpanic(fmt.Errorf(\"not implemented\"))\n\n")
+	errorDeclare, errorAssign, errorField := renderServiceError(actionModel)
+	_, _ = fmt.Fprint(buffer, errorDeclare)
+
+	// Adiciona a chamada do pacote lifecycle ou clone nas mutações geradas
+	if actionModel.Lifecycle != "" {
+		_, _ = fmt.Fprint(buffer, renderServiceLifecycle(actionModel))
+	} else if actionModel.Clone {
+		_, _ = fmt.Fprint(buffer, renderServiceClone(actionModel))
+	} else {
+		// Adiciona um panic para indicar que o método ainda não foi implementado
+		_, _ = fmt.Fprint(buffer, "\tpanic(fmt.Errorf(\"not implemented\"))\n\n")
 
-	// Adiciona um comentário indicando que o método ainda não foi implementado
-	_, _ = fmt.Fprint(buffer, "\t//TODO::necessário implementar o método service."+actionModelName+"()\n")
+		// Adiciona um comentário indicando que o método ainda não foi implementado
+		_, _ = fmt.Fprint(buffer, "\t//TODO::necessário implementar o método service."+actionModelName+"()\n")
 
-	// Adiciona a declaração do ação default
-	if actionModel.Type == "query" {
-		if len(actionModel.Args) == 1 {
-			_, _ = fmt.Fprint(buffer, "\t_result, err := service."+actionModelName+"(r, ctx, filter)\n")
-		} else {
-			_, _ = fmt.Fprint(buffer, "\t_result, err := service."+actionModelName+"(r, ctx)\n")
-		}
-	} else if actionModel.Type == "mutation" || actionModel.Type == "subscription" {
-		if len(actionModel.Args) == 1 {
-			_, _ = fmt.Fprint(buffer, "\t_result, err := service."+actionModelName+"(r, ctx, input)\n")
-		} else if len(actionModel.Args) == 2 {
-			_, _ = fmt.Fprint(buffer, "\t_result, err := service."+actionModelName+"(r, ctx, filter, input)\n")
-		} else {
-			_, _ = fmt.Fprint(buffer, "\t_result, err := service."+actionModelName+"(r, ctx)\n")
-		}
+		// Adiciona a declaração do ação default
+		_, _ = fmt.Fprint(buffer, renderServiceDefault(actionModel))
 	}
 
 	// Verifica o tipo de resultado a partir do modelo de resultado obtido
@@ -678,9 +792,12 @@ func renderServiceItem(buffer *bytes.Buffer, actionModel ActionModel, nameMethod
 	// Adiciona a verificação de erro
 	_, _ = fmt.Fprint(buffer, "\tif err != nil {\n")
 	_, _ = fmt.Fprint(buffer, "\t\t_success = false\n")
-	_, _ = fmt.Fprint(buffer, "\t\t_error = fmt.Sprintf(\"%v\", err.Error())\n")
+	_, _ = fmt.Fprint(buffer, errorAssign)
 	_, _ = fmt.Fprint(buffer, "\t}\n")
 
+	// Adiciona o registro da mutação no log da aplicação
+	_, _ = fmt.Fprint(buffer, renderServiceAudit(actionModel))
+
 	// Gera a declaração do objeto de resposta
 	_, _ = fmt.Fprint(buffer, "\t_response := "+createParamsResponse(actionModel.Response)+"{\n")
 
@@ -692,7 +809,7 @@ func renderServiceItem(buffer *bytes.Buffer, actionModel ActionModel, nameMethod
 	}*/
 
 	// Adiciona a informação sobre o erro da ação ao objeto de resposta
-	_, _ = fmt.Fprint(buffer, "\t\tError: \t\t &_error,\n")
+	_, _ = fmt.Fprint(buffer, errorField)
 
 	// Adiciona a informação sobre o sucesso da ação ao objeto de resposta
 	_, _ = fmt.Fprint(buffer, "\t\tResult:      _result,\n")
@@ -708,6 +825,9 @@ func renderServiceItem(buffer *bytes.Buffer, actionModel ActionModel, nameMethod
 		_, _ = fmt.Fprint(buffer, "\tch := make(chan *model.KdldnTimeResponse)\n")
 		_, _ = fmt.Fprint(buffer, "\tch <- &_response\n")
 		_, _ = fmt.Fprint(buffer, "\treturn ch, nil\n")
+	} else if isGenerated(actionModel) {
+		// Retorna o erro da mutação gerada, pois o ApiChangeResponse não possui o campo error
+		_, _ = fmt.Fprint(buffer, "\treturn &_response, err\n")
 	} else {
 		// Retorna o objeto de resposta e um erro, caso ocorra
 		_, _ = fmt.Fprint(buffer, "\treturn &_response, nil\n")
@@ -718,6 +838,129 @@ func renderServiceItem(buffer *bytes.Buffer, actionModel ActionModel, nameMethod
 	return buffer
 }
 
+// renderServiceDefault retorna a chamada padrão do service da ação, que precisa ser implementada.
+func renderServiceDefault(actionModel ActionModel) string {
+	actionModelName := fistUpperCase(actionModel.Name)
+	if actionModel.Type == "query" {
+		if len(actionModel.Args) == 1 {
+			return "\t_result, err := service." + actionModelName + "(r, ctx, filter)\n"
+		}
+		return "\t_result, err := service." + actionModelName + "(r, ctx)\n"
+	} else if actionModel.Type == "mutation" || actionModel.Type == "subscription" {
+		if len(actionModel.Args) == 1 {
+			return "\t_result, err := service." + actionModelName + "(r, ctx, input)\n"
+		} else if len(actionModel.Args) == 2 {
+			return "\t_result, err := service." + actionModelName + "(r, ctx, filter, input)\n"
+		}
+		return "\t_result, err := service." + actionModelName + "(r, ctx)\n"
+	}
+	return ""
+}
+
+// renderServiceLifecycle retorna a chamada do pacote lifecycle de uma mutação de ciclo de vida.
+func renderServiceLifecycle(actionModel ActionModel) string {
+	module := actionModel.Project + "/" + actionModel.Package
+	return "\t_result, err := lifecycle." + actionModel.Lifecycle + "(ctx, \"" + module + "\", input.ID, input.Version)\n"
+}
+
+// renderServiceClone retorna a chamada do pacote clone de uma mutação de clonagem.
+func renderServiceClone(actionModel ActionModel) string {
+	module := actionModel.Project + "/" + actionModel.Package
+	return "\t_result, err := clone.Clone(ctx, \"" + module + "\", input.ID)\n"
+}
+
+// isGenerated verifica se a ação é uma mutação gerada pelo campo status ou cloneType, implementada pelos pacotes
+// lifecycle ou clone
+func isGenerated(actionModel ActionModel) bool {
+	return actionModel.Lifecycle != "" || actionModel.Clone
+}
+
+// renderServiceError retorna a declaração, a atribuição e o campo de resposta do erro de uma ação,
+// de acordo com o tipo do campo error/errors declarado no response (String ou ApiErrorType).
+// As mutações de ciclo de vida e de clonagem retornam o ApiChangeResponse, sem o campo error.
+func renderServiceError(actionModel ActionModel) (declare string, assign string, field string) {
+	if isGenerated(actionModel) {
+		return "\n", "", ""
+	}
+
+	resultModel := listResponseModel[actionModel.Response]
+	module := actionModel.Project + "/" + actionModel.Package
+
+	if resultModel.errorType != "ApiErrorType" {
+		declare = "\t_error := \"\"\n\n"
+		assign = "\t\t_error = fmt.Sprintf(\"%v\", err.Error())\n"
+		field = "\t\tError: \t\t &_error,\n"
+		return declare, assign, field
+	}
+
+	fieldName := fistUpperCase(resultModel.errorField)
+	if resultModel.isErrorList {
+		declare = "\tvar _error []*api_error.ApiErrorType\n\n"
+		assign = "\t\t_error = api_error.ToTypes(i18n.Localize(ctx, err), \"" + module + "\", \"" + actionModel.Name + "\")\n"
+	} else {
+		declare = "\tvar _error *api_error.ApiErrorType\n\n"
+		assign = "\t\t_error = api_error.ToType(i18n.Localize(ctx, err), \"" + module + "\", \"" + actionModel.Name + "\")\n"
+	}
+	field = "\t\t" + fieldName + ": \t\t _error,\n"
+	return declare, assign, field
+}
+
+// renderServiceAudit retorna o registro da mutação no log da aplicação, com os argumentos da ação.
+// Queries e subscriptions não são registradas.
+func renderServiceAudit(actionModel ActionModel) string {
+	if actionModel.Type != "mutation" {
+		return ""
+	}
+
+	var arguments []string
+	for _, arg := range actionModel.Args {
+		arguments = append(arguments, "\""+arg.Name+"\": "+arg.Name)
+	}
+	module := actionModel.Project + "/" + actionModel.Package
+	return "\taudit.Record(ctx, audit.Mutation(\"" + module + "\", \"" + actionModel.Name + "\", map[string]interface{}{" +
+		strings.Join(arguments, ", ") + "}, _result, err, _timeStart))\n"
+}
+
+// hasApiErrorType verifica se algum response das ações do arquivo utiliza o tipo ApiErrorType
+func hasApiErrorType(item MutationQueryFileModel) bool {
+	for _, actionModel := range item.Actions {
+		if listResponseModel[actionModel.Response].errorType == "ApiErrorType" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNotImplemented verifica se alguma ação do arquivo é gerada sem implementação, utilizando o pacote fmt
+func hasNotImplemented(item MutationQueryFileModel) bool {
+	for _, actionModel := range item.Actions {
+		if !isGenerated(actionModel) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLifecycle verifica se alguma ação do arquivo é uma mutação de ciclo de vida
+func hasLifecycle(item MutationQueryFileModel) bool {
+	for _, actionModel := range item.Actions {
+		if actionModel.Lifecycle != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasClone verifica se alguma ação do arquivo é uma mutação de clonagem
+func hasClone(item MutationQueryFileModel) bool {
+	for _, actionModel := range item.Actions {
+		if actionModel.Clone {
+			return true
+		}
+	}
+	return false
+}
+
 // renderServiceExist é responsável por renderizar o serviço existente com as alterações necessárias para uma nova versão.
 func renderServiceExist(fileByte []byte, item MutationQueryFileModel, pathFilename string) {
 	// Expressão regular para capturar a declaração de import do pacote.
@@ -726,7 +969,7 @@ func renderServiceExist(fileByte []byte, item MutationQueryFileModel, pathFilena
 	// Expressão regular para capturar todos os métodos da struct que retornam o tipo "response".
 	var regexGetAllMethods = regexp.MustCompile(`(func\s(\w.+?)\([\s\S].*{)([\s\S]+?return\s&_response[\s\S]+?)}`)
 
-	// Cria um buffer para armazenar o conteúdo do arquivo.
+	// Cria um buffer para armazenar os métodos do arquivo.
 	buffer := bytes.NewBuffer(nil)
 
 	// Converte o conteúdo do arquivo em uma string.
@@ -735,8 +978,14 @@ func renderServiceExist(fileByte []byte, item MutationQueryFileModel, pathFilena
 	// Captura a declaração do pacote do arquivo original.
 	packageImport := regexPackageImport.FindString(content)
 
-	// Adiciona a declaração do pacote no buffer.
-	_, _ = fmt.Fprint(buffer, packageImport+"\n\n")
+	// Adiciona a importação dos pacotes api_error e i18n, caso algum response utilize ApiErrorType
+	if hasApiErrorType(item) {
+		for _, importPath := range []string{importApiError, importI18n} {
+			if !strings.Contains(packageImport, importPath) {
+				packageImport = strings.TrimSuffix(packageImport, ")") + "\t" + importPath + "\n)"
+			}
+		}
+	}
 
 	// Captura todos os métodos do arquivo original que retornam o tipo "response".
 	listMethodsExist := regexGetAllMethods.FindAllString(content, -1)
@@ -753,12 +1002,19 @@ func renderServiceExist(fileByte []byte, item MutationQueryFileModel, pathFilena
 		return item.Actions[i].Name < item.Actions[j].Name
 	})
 
+	// Indica se algum método gerado registra a mutação no log da aplicação ou chama os pacotes lifecycle ou clone
+	hasAudit := false
+	hasLifecycle := false
+	hasClone := false
+
 	// Percorre todas as ações e gera o código correspondente para cada uma delas.
 	for _, actionModel := range item.Actions {
 		name := fistUpperCase(actionModel.Name)
 		nameType := fistUpperCase(item.Type)
 		nameMethod := name + nameType
 		actionModel.Type = item.Type
+		actionModel.Project = item.Project
+		actionModel.Package = item.Package
 
 		// Expressão regular para capturar o método correspondente à ação atual.
 		var regexGetMethods = regexp.MustCompile(`(func\s(` + nameMethod + `)[\s\S]+?{)([\s\S]+?return\s&_response[\s\S]+?)}`)
@@ -783,6 +1039,9 @@ func renderServiceExist(fileByte []byte, item MutationQueryFileModel, pathFilena
 		// Renderiza a ação atual no buffer, caso o método correspondente não esteja implementado.
 		if isNotImplemented {
 			buffer = renderServiceItem(buffer, actionModel, nameMethod)
+			hasAudit = hasAudit || actionModel.Type == "mutation"
+			hasLifecycle = hasLifecycle || actionModel.Lifecycle != ""
+			hasClone = hasClone || actionModel.Clone
 		} else if isSimilarMethod {
 			// Renderiza o método correspondente no buffer, caso o método correspondente seja semelhante à declaração da função atual.
 			_, _ = fmt.Fprint(buffer, listMatchMethods[0]+"\n\n")
@@ -803,7 +1062,25 @@ func renderServiceExist(fileByte []byte, item MutationQueryFileModel, pathFilena
 		saveBackup(item.Package, pathFilename, bkpItem)
 	}
 
-	if err := os.WriteFile(pathFilename+".go", buffer.Bytes(), 0644); err != nil {
+	// Adiciona a importação do pacote audit, caso algum método gerado registre a mutação
+	if hasAudit && !strings.Contains(packageImport, importAudit) {
+		packageImport = strings.TrimSuffix(packageImport, ")") + "\t" + importAudit + "\n)"
+	}
+
+	// Adiciona a importação do pacote lifecycle, caso alguma mutação de ciclo de vida tenha sido gerada
+	if hasLifecycle && !strings.Contains(packageImport, importLifecycle) {
+		packageImport = strings.TrimSuffix(packageImport, ")") + "\t" + importLifecycle + "\n)"
+	}
+
+	// Adiciona a importação do pacote clone, caso alguma mutação de clonagem tenha sido gerada
+	if hasClone && !strings.Contains(packageImport, importClone) {
+		packageImport = strings.TrimSuffix(packageImport, ")") + "\t" + importClone + "\n)"
+	}
+
+	// Adiciona a declaração do pacote antes dos métodos.
+	content = packageImport + "\n\n" + buffer.String()
+
+	if err := os.WriteFile(pathFilename+".go", []byte(content), 0644); err != nil {
 		panic(err)
 	}
 }
@@ -815,11 +1092,26 @@ func renderServiceNotExist(item MutationQueryFileModel, pathFilename string) {
 	_, _ = fmt.Fprint(buffer, "import (\n")
 	_, _ = fmt.Fprint(buffer, "\t\"coocree_kdl_go_apiconnect/graph/model\"\n")
 	_, _ = fmt.Fprint(buffer, "\t\"coocree_kdl_go_apiconnect/modules/api_connect\"\n")
+	if hasApiErrorType(item) {
+		_, _ = fmt.Fprint(buffer, "\t"+importApiError+"\n")
+		_, _ = fmt.Fprint(buffer, "\t"+importI18n+"\n")
+	}
+	if item.Type == "mutation" {
+		_, _ = fmt.Fprint(buffer, "\t"+importAudit+"\n")
+	}
+	if hasLifecycle(item) {
+		_, _ = fmt.Fprint(buffer, "\t"+importLifecycle+"\n")
+	}
+	if hasClone(item) {
+		_, _ = fmt.Fprint(buffer, "\t"+importClone+"\n")
+	}
 	if item.hasUpload {
 		_, _ = fmt.Fprint(buffer, "\t\"github.com/99designs/gqlgen/graphql\"\n")
 	}
 	_, _ = fmt.Fprint(buffer, "\t\"context\"\n")
-	_, _ = fmt.Fprint(buffer, "\t\"fmt\"\n")
+	if hasNotImplemented(item) {
+		_, _ = fmt.Fprint(buffer, "\t\"fmt\"\n")
+	}
 	_, _ = fmt.Fprint(buffer, "\t\"time\"\n")
 	_, _ = fmt.Fprint(buffer, "\t)\n\n")
 
@@ -832,6 +1124,8 @@ func renderServiceNotExist(item MutationQueryFileModel, pathFilename string) {
 		nameType := fistUpperCase(item.Type)
 		nameMethod := name + nameType
 		actionModel.Type = item.Type
+		actionModel.Project = item.Project
+		actionModel.Package = item.Package
 		//resultModel := listResponseModel[actionModel.Response]
 		actionModelName := fistUpperCase(actionModel.Name)
 
@@ -845,42 +1139,35 @@ func renderServiceNotExist(item MutationQueryFileModel, pathFilename string) {
 		_, _ = fmt.Fprint(buffer, "\t_success := true\n")
 
 		// Inicializa a variável que indicará se a ação teve um erro
-		_, _ = fmt.Fprint(buffer, "\t_error := \"\"\n\n")
-
-		// Adiciona um panic para indicar que o método ainda não foi implementado
-		_, _ = fmt.Fprint(buffer, "\tpanic(fmt.Errorf(\"not implemented\"))\n\n")
-
-		// Adiciona um comentário indicando que o método ainda não foi implementado
-		_, _ = fmt.Fprint(buffer, "\t//TODO::necessário implementar o método service."+actionModelName+"()\n")
-
-		// Adiciona a declaração do ação default
-		//_, _ = fmt.Fprint(buffer, resultCheckType(resultModel))
-		//_, _ = fmt.Fprint(buffer, "\t_result, err := service."+actionModelName+"(r, ctx, input)\n")
+		errorDeclare, errorAssign, errorField := renderServiceError(actionModel)
+		_, _ = fmt.Fprint(buffer, errorDeclare)
+
+		// Adiciona a chamada do pacote lifecycle ou clone nas mutações geradas
+		if actionModel.Lifecycle != "" {
+			_, _ = fmt.Fprint(buffer, renderServiceLifecycle(actionModel))
+		} else if actionModel.Clone {
+			_, _ = fmt.Fprint(buffer, renderServiceClone(actionModel))
+		} else {
+			// Adiciona um panic para indicar que o método ainda não foi implementado
+			_, _ = fmt.Fprint(buffer, "\tpanic(fmt.Errorf(\"not implemented\"))\n\n")
 
-		// Adiciona a declaração do ação default
-		if actionModel.Type == "query" {
-			if len(actionModel.Args) == 1 {
-				_, _ = fmt.Fprint(buffer, "\t_result, err := service."+actionModelName+"(r, ctx, filter)\n")
-			} else {
-				_, _ = fmt.Fprint(buffer, "\t_result, err := service."+actionModelName+"(r, ctx)\n")
-			}
-		} else if actionModel.Type == "mutation" || actionModel.Type == "subscription" {
-			if len(actionModel.Args) == 1 {
-				_, _ = fmt.Fprint(buffer, "\t_result, err := service."+actionModelName+"(r, ctx, input)\n")
-			} else if len(actionModel.Args) == 2 {
-				_, _ = fmt.Fprint(buffer, "\t_result, err := service."+actionModelName+"(r, ctx, filter, input)\n")
-			} else {
-				_, _ = fmt.Fprint(buffer, "\t_result, err := service."+actionModelName+"(r, ctx)\n")
-			}
+			// Adiciona um comentário indicando que o método ainda não foi implementado
+			_, _ = fmt.Fprint(buffer, "\t//TODO::necessário implementar o método service."+actionModelName+"()\n")
 
+			// Adiciona a declaração do ação default
+			//_, _ = fmt.Fprint(buffer, resultCheckType(resultModel))
+			_, _ = fmt.Fprint(buffer, renderServiceDefault(actionModel))
 		}
 
 		// Adiciona a verificação de erro
 		_, _ = fmt.Fprint(buffer, "\tif err != nil {\n")
 		_, _ = fmt.Fprint(buffer, "\t\t_success = false\n")
-		_, _ = fmt.Fprint(buffer, "\t\t_error = fmt.Sprintf(\"%v\", err.Error())\n")
+		_, _ = fmt.Fprint(buffer, errorAssign)
 		_, _ = fmt.Fprint(buffer, "\t}\n")
 
+		// Adiciona o registro da mutação no log da aplicação
+		_, _ = fmt.Fprint(buffer, renderServiceAudit(actionModel))
+
 		// Gera a declaração do objeto de resposta
 		_, _ = fmt.Fprint(buffer, "\t_response := "+createParamsResponse(actionModel.Response)+"{\n")
 
@@ -891,7 +1178,7 @@ func renderServiceNotExist(item MutationQueryFileModel, pathFilename string) {
 		}*/
 
 		// Adiciona a informação sobre o erro da ação ao objeto de resposta
-		_, _ = fmt.Fprint(buffer, "\t\tError: \t\t &_error,\n")
+		_, _ = fmt.Fprint(buffer, errorField)
 
 		// Adiciona a informação sobre o sucesso da ação ao objeto de resposta
 		_, _ = fmt.Fprint(buffer, "\t\tResult:      _result,\n")
@@ -908,6 +1195,9 @@ func renderServiceNotExist(item MutationQueryFileModel, pathFilename string) {
 			_, _ = fmt.Fprint(buffer, "\tch := make(chan *model.KdldnTimeResponse)\n")
 			_, _ = fmt.Fprint(buffer, "\tch <- &_response\n")
 			_, _ = fmt.Fprint(buffer, "\treturn ch, nil\n")
+		} else if isGenerated(actionModel) {
+			// Retorna o erro da mutação gerada, pois o ApiChangeResponse não possui o campo error
+			_, _ = fmt.Fprint(buffer, "\treturn &_response, err\n")
 		} else {
 			// Retorna o objeto de resposta e um erro, caso ocorra
 			_, _ = fmt.Fprint(buffer, "\treturn &_response, nil\n")
@@ -1048,6 +1338,8 @@ func main() {
 	createFileModel("query")
 	createFileModel("mutation")
 	//createFileModel("subscription")
+	createLifecycleActions()
+	createCloneActions()
 	createListResult()
 	renderResolver()
 	renderService()
@@ -46,6 +46,7 @@ func renderMutation(buffer *bytes.Buffer) *bytes.Buffer {
 	// Renderiza as mutações GraphQL em um buffer e retorna o buffer
 	_, _ = fmt.Fprint(buffer, "type Mutation {\n")
 	files, _ := findFiles("./", "mutation.graphqls")
+	declared := ""
 	for _, file := range files {
 		content, err := os.ReadFile(file)
 		if err == nil {
@@ -56,12 +57,90 @@ func renderMutation(buffer *bytes.Buffer) *bytes.Buffer {
 			pos := index[0][1]
 			result := text[pos+1 : len(text)-1]
 			_, _ = fmt.Fprint(buffer, result)
+			declared += result
 		}
 	}
+	buffer = renderLifecycle(buffer, declared)
+	buffer = renderClone(buffer, declared)
 	_, _ = fmt.Fprint(buffer, "}\n\r")
 	return buffer
 }
 
+func renderLifecycle(buffer *bytes.Buffer, declared string) *bytes.Buffer {
+	// Renderiza as mutações de ciclo de vida (disable, enable e restore) dos módulos que declaram o campo
+	// status: StatusEnabledDisabledEnum no type.graphqls, exceto as mutações já declaradas no mutation.graphqls
+	reStatus := regexp.MustCompile(`\bstatus\s*:\s*StatusEnabledDisabledEnum\b`)
+	descriptions := map[string]string{
+		"Disable": "Desabilita o documento",
+		"Enable":  "Ativa o documento ainda não definido",
+		"Restore": "Reativa o documento desabilitado",
+	}
+
+	files, _ := findFiles("./", "type.graphqls")
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil || !reStatus.MatchString(string(content)) {
+			continue
+		}
+
+		// O caminho do arquivo é modules/<projeto>/<pacote>/schemas/type.graphqls
+		path := filepath.Dir(filepath.Dir(file))
+		if filepath.Base(filepath.Dir(path)) == "api_connect" {
+			continue
+		}
+
+		for _, action := range []string{"Disable", "Enable", "Restore"} {
+			name := actionName(filepath.Base(filepath.Dir(path)), filepath.Base(path), action)
+			if regexp.MustCompile(`\b` + name + `\s*\(`).MatchString(declared) {
+				continue
+			}
+			_, _ = fmt.Fprint(buffer, "    \"\"\""+descriptions[action]+"\"\"\"\n")
+			_, _ = fmt.Fprint(buffer, "    "+name+"(input: ApiLifecycleInput!): ApiChangeResponse!\n")
+		}
+	}
+	return buffer
+}
+
+func renderClone(buffer *bytes.Buffer, declared string) *bytes.Buffer {
+	// Renderiza a mutação de clonagem dos módulos que declaram o campo cloneType: StatusCloneTypeEnum
+	// no type.graphqls, exceto quando a mutação já está declarada no mutation.graphqls
+	reCloneType := regexp.MustCompile(`\bcloneType\s*:\s*StatusCloneTypeEnum\b`)
+
+	files, _ := findFiles("./", "type.graphqls")
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil || !reCloneType.MatchString(string(content)) {
+			continue
+		}
+
+		// O caminho do arquivo é modules/<projeto>/<pacote>/schemas/type.graphqls
+		path := filepath.Dir(filepath.Dir(file))
+		if filepath.Base(filepath.Dir(path)) == "api_connect" {
+			continue
+		}
+
+		name := actionName(filepath.Base(filepath.Dir(path)), filepath.Base(path), "Clone")
+		if regexp.MustCompile(`\b` + name + `\s*\(`).MatchString(declared) {
+			continue
+		}
+		_, _ = fmt.Fprint(buffer, "    \"\"\"Clona o documento e as suas relações filhas\"\"\"\n")
+		_, _ = fmt.Fprint(buffer, "    "+name+"(input: ApiCloneInput!): ApiChangeResponse!\n")
+	}
+	return buffer
+}
+
+// actionName retorna o nome da mutação gerada para o projeto/pacote, por exemplo app, user_profile e Disable:
+// appUserProfileDisable. O projeto evita mutações duplicadas entre pacotes de mesmo nome em projetos diferentes.
+func actionName(project string, packageName string, action string) string {
+	parts := strings.Split(project+"_"+packageName, "_")
+	for index := 1; index < len(parts); index++ {
+		if parts[index] != "" {
+			parts[index] = strings.ToUpper(parts[index][:1]) + parts[index][1:]
+		}
+	}
+	return strings.Join(parts, "") + action
+}
+
 func renderSubscription(buffer *bytes.Buffer) *bytes.Buffer {
 	// Renderiza as mutações GraphQL em um buffer e retorna o buffer
 	_, _ = fmt.Fprint(buffer, "type Subscription {\n")
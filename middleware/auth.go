@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// ErrInvalidCredentials is wrapped by the Authenticator errors of credentials present but invalid,
+// for example an expired bearer token. The request is rejected with 401 Unauthorized.
+var ErrInvalidCredentials = errors.New("middleware: invalid credentials")
+
+// Authenticator identifies the user of a request. Returns false when the request has no credentials
+// of its kind, and an error wrapping ErrInvalidCredentials when the credentials are invalid.
+type Authenticator interface {
+	Authenticate(w http.ResponseWriter, r *http.Request) (User, bool, error)
+}
+
+// Authenticate runs the authenticators in the given order and packs the user of the first one
+// that identifies the request into context, for example Authenticate(jwt, sessions) prefers the
+// bearer token over the session cookie. Requests not identified continue with the anonymous principal.
+func Authenticate(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			user := AnonymousUser()
+			for _, authenticator := range authenticators {
+				if manager, ok := authenticator.(*SessionManager); ok {
+					ctx = context.WithValue(ctx, sessionCtxKey, &sessionWriter{manager: manager, writer: w})
+				}
+			}
+
+			for _, authenticator := range authenticators {
+				current, ok, err := authenticator.Authenticate(w, r)
+				if errors.Is(err, ErrInvalidCredentials) {
+					if _, bearer := authenticator.(*JWTAuthenticator); bearer {
+						w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+					}
+					http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+					return
+				}
+				if err != nil {
+					log.Printf("middleware: failed to authenticate the request: %v", err)
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+				if ok {
+					user = current
+					break
+				}
+			}
+
+			ctx = context.WithValue(ctx, userCtxKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,487 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported JWT signature algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+// DefaultClockSkew is the tolerance of exp and nbf when JWTOptions.ClockSkew is zero
+const DefaultClockSkew = time.Minute
+
+// DefaultAdminRole is the role that sets User.IsAdmin when JWTOptions.AdminRole is empty
+const DefaultAdminRole = "ADMIN"
+
+// verificationKey is a key of the KeySet and the algorithm it accepts
+type verificationKey struct {
+	algorithm string
+	key       interface{}
+}
+
+// KeySet holds the keys that verify the token signatures, identified by the kid header
+type KeySet struct {
+	mu   sync.RWMutex
+	keys map[string]verificationKey
+}
+
+// NewKeySet creates an empty KeySet
+func NewKeySet() *KeySet {
+	return &KeySet{keys: map[string]verificationKey{}}
+}
+
+// Add adds a key: []byte for HS256, *rsa.PublicKey for RS256 or a P-256 *ecdsa.PublicKey for ES256
+func (s *KeySet) Add(kid string, key interface{}) error {
+	value, err := newVerificationKey(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[kid] = value
+	return nil
+}
+
+// newVerificationKey checks the key and returns it with the algorithm it verifies
+func newVerificationKey(key interface{}) (verificationKey, error) {
+	var algorithm string
+	switch value := key.(type) {
+	case []byte:
+		if len(value) == 0 {
+			return verificationKey{}, errors.New("middleware: empty HMAC key")
+		}
+		algorithm = AlgorithmHS256
+	case *rsa.PublicKey:
+		algorithm = AlgorithmRS256
+	case *ecdsa.PublicKey:
+		if value.Curve != elliptic.P256() {
+			return verificationKey{}, errors.New("middleware: ES256 requires a P-256 key")
+		}
+		algorithm = AlgorithmES256
+	default:
+		return verificationKey{}, fmt.Errorf("middleware: unsupported key type %T", key)
+	}
+	return verificationKey{algorithm: algorithm, key: key}, nil
+}
+
+// AddSecretFile adds the HS256 secret read from the file, without the trailing line break
+func (s *KeySet) AddSecretFile(kid string, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return s.Add(kid, []byte(strings.TrimRight(string(content), "\r\n")))
+}
+
+// AddPublicKeyFile adds the RS256 or ES256 public key of the PEM file (PUBLIC KEY, RSA PUBLIC KEY or CERTIFICATE)
+func (s *KeySet) AddPublicKeyFile(kid string, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return fmt.Errorf("middleware: no PEM block in %s", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var certificate *x509.Certificate
+		if certificate, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = certificate.PublicKey
+		}
+	default:
+		return fmt.Errorf("middleware: unsupported PEM block %s in %s", block.Type, path)
+	}
+	if err != nil {
+		return err
+	}
+	return s.Add(kid, key)
+}
+
+// jwk is a key of a JWKS document
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// AddJWKSFile adds the signature keys (RSA, EC P-256 and oct) of a local JWKS JSON file.
+// All the keys are validated before any of them is added, so an invalid file leaves the KeySet unchanged.
+func (s *KeySet) AddJWKSFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = json.Unmarshal(content, &document); err != nil {
+		return err
+	}
+
+	keys := map[string]verificationKey{}
+	for _, item := range document.Keys {
+		if item.Use == "enc" {
+			continue
+		}
+		key, err := item.publicKey()
+		if err != nil {
+			return fmt.Errorf("middleware: JWKS key %q: %w", item.Kid, err)
+		}
+		value, err := newVerificationKey(key)
+		if err != nil {
+			return fmt.Errorf("middleware: JWKS key %q: %w", item.Kid, err)
+		}
+		if item.Alg != "" && item.Alg != value.algorithm {
+			return fmt.Errorf("middleware: JWKS key %q: unsupported algorithm %s", item.Kid, item.Alg)
+		}
+		keys[item.Kid] = value
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for kid, value := range keys {
+		s.keys[kid] = value
+	}
+	return nil
+}
+
+// publicKey decodes the key of the JWK
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("point is not on the P-256 curve")
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+// lookup returns the key of the kid. Tokens without kid are accepted when the KeySet has a single key.
+func (s *KeySet) lookup(kid string) (verificationKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if key, ok := s.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	return verificationKey{}, false
+}
+
+// JWTOptions configures the bearer token validation and the mapping of the claims onto User
+type JWTOptions struct {
+	// Keys verifies the signatures. Required.
+	Keys *KeySet
+
+	// Issuer is the expected iss claim. When empty, iss is not validated.
+	Issuer string
+
+	// Audience are the accepted aud claims. When empty, aud is not validated.
+	Audience []string
+
+	// ClockSkew is the tolerance of exp and nbf
+	ClockSkew time.Duration
+
+	// NameClaim is the claim of User.Name, "name" by default. Tokens without it use sub.
+	NameClaim string
+
+	// RolesClaim is the claim of User.Roles, "roles" by default: an array or a space-separated string
+	RolesClaim string
+
+	// AdminRole is the role that sets User.IsAdmin
+	AdminRole string
+
+	// Loader optionally loads the user of the sub claim; the token roles are added to the loaded user
+	Loader UserLoader
+}
+
+// JWTAuthenticator authenticates the requests with the Authorization: Bearer header
+type JWTAuthenticator struct {
+	options JWTOptions
+	now     func() time.Time
+}
+
+// NewJWTAuthenticator creates a JWTAuthenticator, filling the default options
+func NewJWTAuthenticator(options JWTOptions) (*JWTAuthenticator, error) {
+	if options.Keys == nil {
+		return nil, errors.New("middleware: JWT key set is required")
+	}
+	if options.ClockSkew <= 0 {
+		options.ClockSkew = DefaultClockSkew
+	}
+	if options.NameClaim == "" {
+		options.NameClaim = "name"
+	}
+	if options.RolesClaim == "" {
+		options.RolesClaim = "roles"
+	}
+	if options.AdminRole == "" {
+		options.AdminRole = DefaultAdminRole
+	}
+	return &JWTAuthenticator{options: options, now: time.Now}, nil
+}
+
+// Middleware validates the bearer token and packs the user into context
+func (a *JWTAuthenticator) Middleware() func(http.Handler) http.Handler {
+	return Authenticate(a)
+}
+
+// Authenticate validates the bearer token. Returns false when the request has no Authorization: Bearer header.
+func (a *JWTAuthenticator) Authenticate(w http.ResponseWriter, r *http.Request) (User, bool, error) {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return AnonymousUser(), false, nil
+	}
+
+	claims, err := a.Verify(strings.TrimSpace(header[7:]))
+	if err != nil {
+		return AnonymousUser(), false, err
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return AnonymousUser(), false, invalidToken("missing sub claim")
+	}
+	user := User{ID: subject, Name: subject}
+	if a.options.Loader != nil {
+		if user, err = a.options.Loader.LoadUser(r.Context(), subject); err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				return AnonymousUser(), false, invalidToken("unknown sub claim")
+			}
+			return AnonymousUser(), false, err
+		}
+		if user.ID == "" {
+			user.ID = subject
+		}
+	} else {
+		if name, _ := claims[a.options.NameClaim].(string); name != "" {
+			user.Name = name
+		}
+		user.Locale, _ = claims["locale"].(string)
+	}
+
+	for _, role := range stringList(claims[a.options.RolesClaim]) {
+		if !contains(user.Roles, role) {
+			user.Roles = append(user.Roles, role)
+		}
+	}
+	user.IsAdmin = user.IsAdmin || contains(user.Roles, a.options.AdminRole)
+	user.Anonymous = false
+	return user, true, nil
+}
+
+// Verify validates the signature, exp, nbf, iss and aud of the token and returns its claims
+func (a *JWTAuthenticator) Verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalidToken("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, invalidToken("malformed header")
+	}
+	key, ok := a.options.Keys.lookup(header.Kid)
+	if !ok {
+		return nil, invalidToken("unknown key")
+	}
+	if header.Alg != key.algorithm {
+		return nil, invalidToken("unexpected algorithm " + header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !verifySignature(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, invalidToken("invalid signature")
+	}
+
+	var claims map[string]interface{}
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, invalidToken("malformed claims")
+	}
+	if err = a.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validate checks the time, issuer and audience claims
+func (a *JWTAuthenticator) validate(claims map[string]interface{}) error {
+	now := a.now()
+	expiration, ok := claims["exp"].(float64)
+	if !ok {
+		return invalidToken("missing exp claim")
+	}
+	if !now.Before(unixTime(expiration).Add(a.options.ClockSkew)) {
+		return invalidToken("token expired")
+	}
+	if value, ok := claims["nbf"]; ok {
+		notBefore, isNumber := value.(float64)
+		if !isNumber || now.Add(a.options.ClockSkew).Before(unixTime(notBefore)) {
+			return invalidToken("token not yet valid")
+		}
+	}
+	if a.options.Issuer != "" {
+		if issuer, _ := claims["iss"].(string); issuer != a.options.Issuer {
+			return invalidToken("unexpected issuer")
+		}
+	}
+	if len(a.options.Audience) > 0 {
+		accepted := false
+		for _, audience := range audienceList(claims["aud"]) {
+			if contains(a.options.Audience, audience) {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			return invalidToken("unexpected audience")
+		}
+	}
+	return nil
+}
+
+// verifySignature checks the signature of the signing input with the key
+func verifySignature(key verificationKey, input []byte, signature []byte) bool {
+	switch key.algorithm {
+	case AlgorithmHS256:
+		mac := hmac.New(sha256.New, key.key.([]byte))
+		mac.Write(input)
+		return hmac.Equal(signature, mac.Sum(nil))
+	case AlgorithmRS256:
+		digest := sha256.Sum256(input)
+		return rsa.VerifyPKCS1v15(key.key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case AlgorithmES256:
+		if len(signature) != 64 {
+			return false
+		}
+		digest := sha256.Sum256(input)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key.key.(*ecdsa.PublicKey), digest[:], r, s)
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url JSON segment of the token
+func decodeSegment(segment string, value interface{}) error {
+	content, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, value)
+}
+
+// invalidToken returns an error wrapping ErrInvalidCredentials
+func invalidToken(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidCredentials, reason)
+}
+
+// unixTime converts a NumericDate claim
+func unixTime(value float64) time.Time {
+	seconds := int64(value)
+	return time.Unix(seconds, int64((value-float64(seconds))*1e9))
+}
+
+// stringList reads a claim that is a string, a space-separated string or an array of strings
+func stringList(value interface{}) []string {
+	switch item := value.(type) {
+	case string:
+		return strings.Fields(item)
+	case []interface{}:
+		result := make([]string, 0, len(item))
+		for _, element := range item {
+			if text, ok := element.(string); ok {
+				result = append(result, text)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// audienceList reads the aud claim, which is a single audience when it is a string (RFC 7519, section 4.1.3)
+func audienceList(value interface{}) []string {
+	if item, ok := value.(string); ok {
+		return []string{item}
+	}
+	return stringList(value)
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,355 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testNow    = time.Unix(1700000000, 0)
+	testSecret = []byte("jwt test secret")
+)
+
+// signingInput encodes the header and the claims of a token
+func signingInput(t *testing.T, header map[string]interface{}, claims map[string]interface{}) string {
+	t.Helper()
+	encode := func(value interface{}) string {
+		content, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(content)
+	}
+	return encode(header) + "." + encode(claims)
+}
+
+// hs256 returns a token signed with the HMAC secret
+func hs256(t *testing.T, secret []byte, header map[string]interface{}, claims map[string]interface{}) string {
+	t.Helper()
+	input := signingInput(t, header, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// claimsAt returns valid claims for testNow, replaced by the overrides. A nil override removes the claim.
+func claimsAt(overrides map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub": "user-1",
+		"iss": "https://auth.example.com",
+		"aud": "api",
+		"exp": testNow.Add(time.Hour).Unix(),
+		"nbf": testNow.Add(-time.Hour).Unix(),
+	}
+	for key, value := range overrides {
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+	}
+	return claims
+}
+
+// newAuthenticator creates a JWTAuthenticator with the keys and the clock at testNow
+func newAuthenticator(t *testing.T, keys *KeySet) *JWTAuthenticator {
+	t.Helper()
+	authenticator, err := NewJWTAuthenticator(JWTOptions{
+		Keys:     keys,
+		Issuer:   "https://auth.example.com",
+		Audience: []string{"api", "admin"},
+	})
+	if err != nil {
+		t.Fatalf("NewJWTAuthenticator: %v", err)
+	}
+	authenticator.now = func() time.Time { return testNow }
+	return authenticator
+}
+
+func TestVerifyHS256(t *testing.T) {
+	keys := NewKeySet()
+	if err := keys.Add("hs", testSecret); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	authenticator := newAuthenticator(t, keys)
+	header := map[string]interface{}{"alg": AlgorithmHS256, "typ": "JWT", "kid": "hs"}
+
+	tests := []struct {
+		name   string
+		token  string
+		reason string
+	}{
+		{"valid", hs256(t, testSecret, header, claimsAt(nil)), ""},
+		{"without kid", hs256(t, testSecret, map[string]interface{}{"alg": AlgorithmHS256}, claimsAt(nil)), ""},
+		{"audience list", hs256(t, testSecret, header, claimsAt(map[string]interface{}{"aud": []string{"web", "admin"}})), ""},
+		{"expired within clock skew", hs256(t, testSecret, header, claimsAt(map[string]interface{}{"exp": testNow.Add(-30 * time.Second).Unix()})), ""},
+		{"expired", hs256(t, testSecret, header, claimsAt(map[string]interface{}{"exp": testNow.Add(-2 * time.Minute).Unix()})), "token expired"},
+		{"without exp", hs256(t, testSecret, header, claimsAt(map[string]interface{}{"exp": nil})), "missing exp claim"},
+		{"not yet valid", hs256(t, testSecret, header, claimsAt(map[string]interface{}{"nbf": testNow.Add(2 * time.Minute).Unix()})), "token not yet valid"},
+		{"issuer", hs256(t, testSecret, header, claimsAt(map[string]interface{}{"iss": "https://other.example.com"})), "unexpected issuer"},
+		{"audience", hs256(t, testSecret, header, claimsAt(map[string]interface{}{"aud": "web"})), "unexpected audience"},
+		{"audience with spaces", hs256(t, testSecret, header, claimsAt(map[string]interface{}{"aud": "web admin"})), "unexpected audience"},
+		{"signature", hs256(t, []byte("other secret"), header, claimsAt(nil)), "invalid signature"},
+		{"unknown key", hs256(t, testSecret, map[string]interface{}{"alg": AlgorithmHS256, "kid": "other"}, claimsAt(nil)), "unknown key"},
+		{"algorithm none", signingInput(t, map[string]interface{}{"alg": "none", "kid": "hs"}, claimsAt(nil)) + ".", "unexpected algorithm none"},
+		{"malformed", "header.claims", "malformed token"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims, err := authenticator.Verify(test.token)
+			if test.reason == "" {
+				if err != nil {
+					t.Fatalf("Verify = %v", err)
+				}
+				if claims["sub"] != "user-1" {
+					t.Errorf("sub = %v, want user-1", claims["sub"])
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidCredentials) || !strings.HasSuffix(err.Error(), test.reason) {
+				t.Errorf("Verify = %v, want %s", err, test.reason)
+			}
+		})
+	}
+}
+
+func TestVerifyAsymmetric(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey: %v", err)
+	}
+
+	keys := NewKeySet()
+	if err = keys.Add("rs", &rsaKey.PublicKey); err != nil {
+		t.Fatalf("Add RSA: %v", err)
+	}
+	if err = keys.Add("es", &ecKey.PublicKey); err != nil {
+		t.Fatalf("Add ECDSA: %v", err)
+	}
+	authenticator := newAuthenticator(t, keys)
+
+	rs256 := func(kid string, claims map[string]interface{}) string {
+		input := signingInput(t, map[string]interface{}{"alg": AlgorithmRS256, "kid": kid}, claims)
+		digest := sha256.Sum256([]byte(input))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("rsa.SignPKCS1v15: %v", err)
+		}
+		return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+	es256 := func(kid string, claims map[string]interface{}) string {
+		input := signingInput(t, map[string]interface{}{"alg": AlgorithmES256, "kid": kid}, claims)
+		digest := sha256.Sum256([]byte(input))
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			t.Fatalf("ecdsa.Sign: %v", err)
+		}
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+
+	if _, err = authenticator.Verify(rs256("rs", claimsAt(nil))); err != nil {
+		t.Errorf("Verify RS256 = %v", err)
+	}
+	if _, err = authenticator.Verify(es256("es", claimsAt(nil))); err != nil {
+		t.Errorf("Verify ES256 = %v", err)
+	}
+
+	// The algorithm of the key is fixed: an RS256 key does not verify ES256 or HS256 signatures
+	if _, err = authenticator.Verify(es256("rs", claimsAt(nil))); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Verify ES256 with the RSA key = %v, want invalid credentials", err)
+	}
+	hs := hs256(t, rsaKey.PublicKey.N.Bytes(), map[string]interface{}{"alg": AlgorithmHS256, "kid": "rs"}, claimsAt(nil))
+	if _, err = authenticator.Verify(hs); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Verify HS256 with the RSA key = %v, want invalid credentials", err)
+	}
+
+	// Tokens without kid are rejected when the KeySet has more than one key
+	if _, err = authenticator.Verify(rs256("", claimsAt(nil))); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Verify without kid = %v, want invalid credentials", err)
+	}
+
+	tampered := strings.Split(rs256("rs", claimsAt(nil)), ".")
+	tampered[1] = strings.Split(signingInput(t, nil, claimsAt(map[string]interface{}{"sub": "admin"})), ".")[1]
+	if _, err = authenticator.Verify(strings.Join(tampered, ".")); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Verify with changed claims = %v, want invalid credentials", err)
+	}
+}
+
+func TestKeySetAdd(t *testing.T) {
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey: %v", err)
+	}
+	keys := NewKeySet()
+	for name, key := range map[string]interface{}{"empty secret": []byte{}, "P-384": &p384.PublicKey, "string": "secret"} {
+		if err := keys.Add("kid", key); err == nil {
+			t.Errorf("Add %s = nil, want error", name)
+		}
+	}
+}
+
+func TestAddJWKSFile(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey: %v", err)
+	}
+	encode := base64.RawURLEncoding.EncodeToString
+	oct := map[string]interface{}{"kty": "oct", "kid": "hs", "alg": AlgorithmHS256, "k": encode(testSecret)}
+	ec := map[string]interface{}{"kty": "EC", "kid": "es", "crv": "P-256", "x": encode(ecKey.X.Bytes()), "y": encode(ecKey.Y.Bytes())}
+	enc := map[string]interface{}{"kty": "RSA", "kid": "enc", "use": "enc", "n": "", "e": ""}
+
+	write := func(keys ...map[string]interface{}) string {
+		content, err := json.Marshal(map[string]interface{}{"keys": keys})
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		path := filepath.Join(t.TempDir(), "jwks.json")
+		if err = os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatalf("os.WriteFile: %v", err)
+		}
+		return path
+	}
+
+	keys := NewKeySet()
+	if err := keys.AddJWKSFile(write(oct, ec, enc)); err != nil {
+		t.Fatalf("AddJWKSFile: %v", err)
+	}
+	for kid, algorithm := range map[string]string{"hs": AlgorithmHS256, "es": AlgorithmES256} {
+		if key, ok := keys.lookup(kid); !ok || key.algorithm != algorithm {
+			t.Errorf("lookup(%s) = %+v, %v, want %s", kid, key, ok, algorithm)
+		}
+	}
+	if _, ok := keys.lookup("enc"); ok {
+		t.Error("AddJWKSFile added an encryption key")
+	}
+
+	// An invalid entry leaves the KeySet unchanged, including the valid entries before it
+	mismatch := map[string]interface{}{"kty": "oct", "kid": "rs", "alg": AlgorithmRS256, "k": encode(testSecret)}
+	invalid := map[string]interface{}{"kty": "EC", "kid": "p384", "crv": "P-384", "x": "", "y": ""}
+	for name, entry := range map[string]map[string]interface{}{"algorithm": mismatch, "curve": invalid} {
+		keys := NewKeySet()
+		if err := keys.AddJWKSFile(write(oct, entry)); err == nil {
+			t.Errorf("AddJWKSFile with an invalid %s = nil, want error", name)
+		}
+		if len(keys.keys) != 0 {
+			t.Errorf("AddJWKSFile with an invalid %s added %d keys", name, len(keys.keys))
+		}
+	}
+}
+
+func TestJWTAuthenticate(t *testing.T) {
+	keys := NewKeySet()
+	if err := keys.Add("hs", testSecret); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	header := map[string]interface{}{"alg": AlgorithmHS256, "kid": "hs"}
+
+	tests := []struct {
+		name          string
+		authorization string
+		loader        UserLoader
+		status        int
+		user          User
+	}{
+		{
+			name:   "without token",
+			status: http.StatusOK,
+			user:   AnonymousUser(),
+		},
+		{
+			name: "claims",
+			authorization: "Bearer " + hs256(t, testSecret, header, claimsAt(map[string]interface{}{
+				"name": "Ana", "locale": "en", "roles": "EDITOR ADMIN",
+			})),
+			status: http.StatusOK,
+			user:   User{ID: "user-1", Name: "Ana", Locale: "en", Roles: []string{"EDITOR", "ADMIN"}, IsAdmin: true},
+		},
+		{
+			name:          "without name",
+			authorization: "bearer " + hs256(t, testSecret, header, claimsAt(map[string]interface{}{"roles": []string{"EDITOR"}})),
+			status:        http.StatusOK,
+			user:          User{ID: "user-1", Name: "user-1", Roles: []string{"EDITOR"}},
+		},
+		{
+			name:          "loader",
+			authorization: "Bearer " + hs256(t, testSecret, header, claimsAt(map[string]interface{}{"name": "Token", "roles": []string{"EDITOR"}})),
+			loader: UserLoaderFunc(func(ctx context.Context, id string) (User, error) {
+				return User{ID: id, Name: "Ana", Roles: []string{"EDITOR", "REVIEWER"}}, nil
+			}),
+			status: http.StatusOK,
+			user:   User{ID: "user-1", Name: "Ana", Roles: []string{"EDITOR", "REVIEWER"}},
+		},
+		{
+			name:          "unknown user",
+			authorization: "Bearer " + hs256(t, testSecret, header, claimsAt(nil)),
+			loader: UserLoaderFunc(func(ctx context.Context, id string) (User, error) {
+				return User{}, ErrUserNotFound
+			}),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:          "without sub",
+			authorization: "Bearer " + hs256(t, testSecret, header, claimsAt(map[string]interface{}{"sub": nil})),
+			status:        http.StatusUnauthorized,
+		},
+		{
+			name:          "expired",
+			authorization: "Bearer " + hs256(t, testSecret, header, claimsAt(map[string]interface{}{"exp": testNow.Add(-time.Hour).Unix()})),
+			status:        http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authenticator := newAuthenticator(t, keys)
+			authenticator.options.Loader = test.loader
+
+			var user User
+			handler := authenticator.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user, _ = ForContext(r.Context())
+			}))
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.status)
+			}
+			if test.status == http.StatusUnauthorized {
+				if got := recorder.Header().Get("WWW-Authenticate"); got != `Bearer error="invalid_token"` {
+					t.Errorf("WWW-Authenticate = %q", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(user, test.user) {
+				t.Errorf("user = %+v, want %+v", user, test.user)
+			}
+		})
+	}
+}
@@ -2,7 +2,19 @@ package middleware
 
 import (
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -10,100 +22,364 @@ import (
 // to prevent collisions between different context uses
 var userCtxKey = &contextKey{"user"}
 
-//var cookieAccessKeyCtx = &contextKey{"cookies"}
+var sessionCtxKey = &contextKey{"session"}
 
 type contextKey struct {
 	name string
 }
 
-// A stand-in for our database backed user object
+// DefaultCookieName is the name of the session cookie when SessionOptions.CookieName is empty
+const DefaultCookieName = "auth-cookie"
+
+// DefaultMaxAge is the lifetime of a session when SessionOptions.MaxAge is zero
+const DefaultMaxAge = 24 * time.Hour
+
+// DefaultMaxLifetime is the absolute lifetime of a session, counted from the login, when SessionOptions.MaxLifetime is zero
+const DefaultMaxLifetime = 7 * 24 * time.Hour
+
+// ErrUserNotFound is returned by a UserLoader when the session user no longer exists.
+// The session cookie is cleared and the request continues as anonymous.
+var ErrUserNotFound = errors.New("middleware: user not found")
+
+var errInvalidSession = errors.New("middleware: invalid session cookie")
+
+// User is the principal of the request, loaded by the UserLoader
 type User struct {
-	Name    string
-	IsAdmin bool
-}
-
-// Middleware decodes the share session cookie and packs the session into context
-func Session() func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			c, err := r.Cookie("auth-cookie")
-
-			//fmt.Println("auth-cookie--->>>CCCCCC", c, "<<---", r.Cookies())
-
-			// Allow unauthenticated users in
-			if err != nil || c == nil {
-				//next.ServeHTTP(w, r)
-				//return
-			}
-
-			/*
-				userId, err := validateAndGetUserID(c)
-				if err != nil {
-					http.Error(w, "Invalid cookie", http.StatusForbidden)
-					return
-				}
-			*/
-
-			// get the user from the database
-			//user := getUserByID(db, userId)
-
-			user := User{
-				Name:    "Israel",
-				IsAdmin: false,
-			}
-
-			// put it in context
-			ctx := context.WithValue(r.Context(), userCtxKey, user)
-
-			//fmt.Println("USER--->>>AAAAAAAA", user)
-
-			expire := time.Now().AddDate(0, 0, 1)
-			cookie := http.Cookie{}
-			cookie.Name = "test"
-			cookie.Value = "tcookie"
-			cookie.Path = "/"
-			cookie.Domain = "192.168.1.34"
-			cookie.Expires = expire
-			cookie.RawExpires = expire.Format(time.UnixDate)
-			cookie.MaxAge = 86400
-			cookie.Secure = false
-			cookie.HttpOnly = true
-			//cookie.SameSite =
-			cookie.Unparsed = []string{"test=tcookie"}
-
-			//fmt.Println("COOKIE--->>>MMMMMMMMM", cookie)
-
-			http.SetCookie(w, &http.Cookie{
-				Name:     "auth-cookie",
-				Value:    "sssss",
-				HttpOnly: true,
-				Path:     "/",
-				Domain:   "192.168.1.34",
-			})
-
-			//"test", "tcookie", "/", "www.domain.com", expire, expire.Format(time.UnixDate), 86400, true, true, "test=tcookie", []string{"test=tcookie"}
-
-			r.AddCookie(&cookie)
-			//io.WriteString(w, "Hello world!")
-
-			// and call the next with our new context
-			r = r.WithContext(ctx)
-			next.ServeHTTP(w, r)
-		})
-	}
-}
-
-// ForContext finds the user from the context. REQUIRES Middleware to have run.
-func ForContext(ctx context.Context) User {
-
-	//fmt.Println("USER--->>>KKKKKKKKK", userCtxKey)
-	//fmt.Println("USER--->>>EEEEEEEEEE", ctx.Value(userCtxKey))
-	//x := ctx.Value(userCtxKey)
-	//fmt.Println("USER--->>>XXXXXXXXXXXXXX", x, x.(User).Name)
-
-	raw, err := ctx.Value(userCtxKey).(User)
-	if err {
-		//fmt.Println("USER--->>>FFFFFFFFFFFFFFFF", err)
-	}
-	return raw
+	// ID identifies the user and the documents it owns (ApiInfo.owner)
+	ID string
+
+	// Name is the display name, which is not unique
+	Name      string
+	IsAdmin   bool
+	Locale    string
+	Roles     []string
+	Anonymous bool
+
+	// SessionEpoch is the revocation counter of the user sessions, loaded by the UserLoader. Session cookies
+	// issued with another epoch are rejected, so incrementing it in the database revokes all the sessions of the user.
+	SessionEpoch int64
+}
+
+// AnonymousUser returns the principal of requests without a valid session
+func AnonymousUser() User {
+	return User{Anonymous: true}
+}
+
+// UserLoader loads the user of a session, usually from the database
+type UserLoader interface {
+	LoadUser(ctx context.Context, id string) (User, error)
+}
+
+// UserLoaderFunc adapts a function to the UserLoader interface
+type UserLoaderFunc func(ctx context.Context, id string) (User, error)
+
+// LoadUser calls f(ctx, id)
+func (f UserLoaderFunc) LoadUser(ctx context.Context, id string) (User, error) {
+	return f(ctx, id)
+}
+
+// SessionRevoker revokes the sessions of a user, usually incrementing the User.SessionEpoch stored in the database
+type SessionRevoker interface {
+	RevokeSessions(ctx context.Context, id string) error
+}
+
+// SessionOptions configures the session cookies
+type SessionOptions struct {
+	// Secret signs (HMAC-SHA256) or encrypts (AES-256-GCM) the cookies. When empty, reads the
+	// API_SESSION_SECRET environment variable or generates a random secret, which invalidates
+	// the sessions on restart.
+	Secret []byte
+
+	// Encrypt hides the session content from the client; otherwise the content is only signed
+	Encrypt bool
+
+	// Loader loads the session user. Required.
+	Loader UserLoader
+
+	// Revoker optionally revokes the sessions of the user on Logout
+	Revoker SessionRevoker
+
+	// MaxAge is the lifetime of the cookie, renewed after half of it while the session is used
+	MaxAge time.Duration
+
+	// MaxLifetime is the absolute lifetime of a session counted from the login; renewals never extend it
+	MaxLifetime time.Duration
+
+	CookieName string
+	Domain     string
+	Path       string
+	Secure     bool
+	SameSite   http.SameSite
+}
+
+// SessionManager issues and validates the session cookies
+type SessionManager struct {
+	options SessionOptions
+	aead    cipher.AEAD
+	now     func() time.Time
+}
+
+// session is the content of the cookie
+type session struct {
+	UserID    string `json:"uid"`
+	Epoch     int64  `json:"epoch,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// NewSessionManager creates a SessionManager, filling the default options
+func NewSessionManager(options SessionOptions) (*SessionManager, error) {
+	if options.Loader == nil {
+		return nil, errors.New("middleware: session user loader is required")
+	}
+	if len(options.Secret) == 0 {
+		options.Secret = defaultSecret()
+	}
+	if options.CookieName == "" {
+		options.CookieName = DefaultCookieName
+	}
+	if options.MaxAge <= 0 {
+		options.MaxAge = DefaultMaxAge
+	}
+	if options.MaxLifetime <= 0 {
+		options.MaxLifetime = DefaultMaxLifetime
+	}
+	if options.MaxLifetime < options.MaxAge {
+		options.MaxLifetime = options.MaxAge
+	}
+	if options.Path == "" {
+		options.Path = "/"
+	}
+	if options.SameSite == 0 {
+		options.SameSite = http.SameSiteLaxMode
+	}
+
+	manager := &SessionManager{options: options, now: time.Now}
+	if options.Encrypt {
+		key := sha256.Sum256(options.Secret)
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return nil, err
+		}
+		if manager.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	return manager, nil
+}
+
+// defaultSecret reads the API_SESSION_SECRET environment variable or generates a random secret
+func defaultSecret() []byte {
+	if secret := os.Getenv("API_SESSION_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return secret
+}
+
+// encode serializes, signs or encrypts the session
+func (m *SessionManager) encode(value session) (string, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	if m.aead != nil {
+		nonce := make([]byte, m.aead.NonceSize())
+		if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+			return "", err
+		}
+		sealed := m.aead.Seal(nonce, nonce, payload, []byte(m.options.CookieName))
+		return base64.RawURLEncoding.EncodeToString(sealed), nil
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(m.sign(payload)), nil
+}
+
+// decode validates the signature or decrypts the cookie and checks the expiration and the absolute lifetime
+func (m *SessionManager) decode(value string, now time.Time) (session, error) {
+	var payload []byte
+	if m.aead != nil {
+		sealed, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(sealed) < m.aead.NonceSize() {
+			return session{}, errInvalidSession
+		}
+		nonce, ciphertext := sealed[:m.aead.NonceSize()], sealed[m.aead.NonceSize():]
+		if payload, err = m.aead.Open(nil, nonce, ciphertext, []byte(m.options.CookieName)); err != nil {
+			return session{}, errInvalidSession
+		}
+	} else {
+		parts := strings.Split(value, ".")
+		if len(parts) != 2 {
+			return session{}, errInvalidSession
+		}
+		var err error
+		if payload, err = base64.RawURLEncoding.DecodeString(parts[0]); err != nil {
+			return session{}, errInvalidSession
+		}
+		signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil || !hmac.Equal(signature, m.sign(payload)) {
+			return session{}, errInvalidSession
+		}
+	}
+
+	var result session
+	if err := json.Unmarshal(payload, &result); err != nil || result.UserID == "" || result.IssuedAt == 0 {
+		return session{}, errInvalidSession
+	}
+	if now.Unix() >= result.ExpiresAt || !now.Before(time.Unix(result.IssuedAt, 0).Add(m.options.MaxLifetime)) {
+		return session{}, errInvalidSession
+	}
+	return result, nil
+}
+
+// sign computes the HMAC-SHA256 of the session content
+func (m *SessionManager) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, m.options.Secret)
+	mac.Write([]byte(m.options.CookieName))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// expiration returns the expiration of a cookie issued or renewed at now, limited to the absolute lifetime of the session
+func (m *SessionManager) expiration(value session, now time.Time) time.Time {
+	expires := now.Add(m.options.MaxAge)
+	if limit := time.Unix(value.IssuedAt, 0).Add(m.options.MaxLifetime); limit.Before(expires) {
+		return limit
+	}
+	return expires
+}
+
+// write sets the session cookie, keeping the login time and the epoch of the session
+func (m *SessionManager) write(w http.ResponseWriter, value session, now time.Time) error {
+	expires := m.expiration(value, now)
+	value.ExpiresAt = expires.Unix()
+	encoded, err := m.encode(value)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, m.cookie(encoded, int(expires.Sub(now).Seconds()), expires))
+	return nil
+}
+
+// clear removes the session cookie
+func (m *SessionManager) clear(w http.ResponseWriter) {
+	http.SetCookie(w, m.cookie("", -1, time.Unix(0, 0)))
+}
+
+func (m *SessionManager) cookie(value string, maxAge int, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     m.options.CookieName,
+		Value:    value,
+		Path:     m.options.Path,
+		Domain:   m.options.Domain,
+		Expires:  expires,
+		MaxAge:   maxAge,
+		Secure:   m.options.Secure,
+		HttpOnly: true,
+		SameSite: m.options.SameSite,
+	}
+}
+
+// Authenticate reads the session cookie and loads the user. Returns false when the request has no valid session,
+// or when the session epoch differs from the User.SessionEpoch of the loaded user (revoked sessions).
+// Cookies past half of MaxAge are renewed up to the absolute lifetime of the session.
+func (m *SessionManager) Authenticate(w http.ResponseWriter, r *http.Request) (User, bool, error) {
+	c, err := r.Cookie(m.options.CookieName)
+	if err != nil || c.Value == "" {
+		return AnonymousUser(), false, nil
+	}
+
+	now := m.now()
+	value, err := m.decode(c.Value, now)
+	if err != nil {
+		m.clear(w)
+		return AnonymousUser(), false, nil
+	}
+
+	user, err := m.options.Loader.LoadUser(r.Context(), value.UserID)
+	if errors.Is(err, ErrUserNotFound) {
+		m.clear(w)
+		return AnonymousUser(), false, nil
+	}
+	if err != nil {
+		return AnonymousUser(), false, err
+	}
+	if user.SessionEpoch != value.Epoch {
+		m.clear(w)
+		return AnonymousUser(), false, nil
+	}
+	if user.ID == "" {
+		user.ID = value.UserID
+	}
+	user.Anonymous = false
+
+	expires := time.Unix(value.ExpiresAt, 0)
+	if expires.Sub(now) < m.options.MaxAge/2 && m.expiration(value, now).Unix() > value.ExpiresAt {
+		if err = m.write(w, value, now); err != nil {
+			log.Printf("middleware: failed to renew the session: %v", err)
+		}
+	}
+	return user, true, nil
+}
+
+// Middleware decodes the session cookie and packs the user into context.
+// Requests without a valid session continue with the anonymous principal.
+func (m *SessionManager) Middleware() func(http.Handler) http.Handler {
+	return Authenticate(m)
+}
+
+// Session returns the session middleware of the manager
+func Session(manager *SessionManager) func(http.Handler) http.Handler {
+	return manager.Middleware()
+}
+
+// sessionWriter allows the resolvers to issue and clear the session cookie
+type sessionWriter struct {
+	manager *SessionManager
+	writer  http.ResponseWriter
+}
+
+// Login issues the session cookie for the user, usually in the login mutation. The user is loaded by the
+// UserLoader to bind the session to its current User.SessionEpoch. REQUIRES Middleware to have run.
+func Login(ctx context.Context, userID string) error {
+	current, ok := ctx.Value(sessionCtxKey).(*sessionWriter)
+	if !ok {
+		return errors.New("middleware: session middleware not configured")
+	}
+	if userID == "" {
+		return errors.New("middleware: user id is required")
+	}
+	user, err := current.manager.options.Loader.LoadUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	now := current.manager.now()
+	return current.manager.write(current.writer, session{UserID: userID, Epoch: user.SessionEpoch, IssuedAt: now.Unix()}, now)
+}
+
+// Logout clears the session cookie and, when SessionOptions.Revoker is set, revokes all the sessions of the
+// authenticated user, including copies of the cookie. REQUIRES Middleware to have run.
+func Logout(ctx context.Context) error {
+	current, ok := ctx.Value(sessionCtxKey).(*sessionWriter)
+	if !ok {
+		return errors.New("middleware: session middleware not configured")
+	}
+	current.manager.clear(current.writer)
+	if revoker := current.manager.options.Revoker; revoker != nil {
+		if user, authenticated := ForContext(ctx); authenticated {
+			return revoker.RevokeSessions(ctx, user.ID)
+		}
+	}
+	return nil
+}
+
+// ForContext finds the user from the context. Returns the anonymous principal and false
+// when the request is not authenticated. Authenticated users always have an ID, which
+// identifies the owner of the documents; Name is only a display name.
+func ForContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userCtxKey).(User)
+	if !ok || user.Anonymous || user.ID == "" {
+		return AnonymousUser(), false
+	}
+	return user, true
 }
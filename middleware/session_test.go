@@ -0,0 +1,311 @@
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// users is a UserLoader of fixed users, which counts the calls of RevokeSessions
+type users struct {
+	items   map[string]User
+	revoked []string
+}
+
+func (u *users) LoadUser(ctx context.Context, id string) (User, error) {
+	user, ok := u.items[id]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (u *users) RevokeSessions(ctx context.Context, id string) error {
+	u.revoked = append(u.revoked, id)
+	user := u.items[id]
+	user.SessionEpoch++
+	u.items[id] = user
+	return nil
+}
+
+// newSessions creates a SessionManager with a 1 hour cookie, a 3 hour absolute lifetime and the clock at *now
+func newSessions(t *testing.T, secret string, encrypt bool, loader *users, now *time.Time) *SessionManager {
+	t.Helper()
+	manager, err := NewSessionManager(SessionOptions{
+		Secret:      []byte(secret),
+		Encrypt:     encrypt,
+		Loader:      loader,
+		Revoker:     loader,
+		MaxAge:      time.Hour,
+		MaxLifetime: 3 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewSessionManager: %v", err)
+	}
+	manager.now = func() time.Time { return *now }
+	return manager
+}
+
+// serve runs the session middleware with the cookie and the handler, returning the user and the response
+func serve(manager *SessionManager, cookie string, handler func(ctx context.Context)) (User, *httptest.ResponseRecorder) {
+	var user User
+	middleware := manager.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ = ForContext(r.Context())
+		if handler != nil {
+			handler(r.Context())
+		}
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if cookie != "" {
+		request.AddCookie(&http.Cookie{Name: DefaultCookieName, Value: cookie})
+	}
+	recorder := httptest.NewRecorder()
+	middleware.ServeHTTP(recorder, request)
+	return user, recorder
+}
+
+// responseCookie returns the session cookie set by the response
+func responseCookie(t *testing.T, recorder *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == DefaultCookieName {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestSessionEncodeDecode(t *testing.T) {
+	now := testNow
+	loader := &users{}
+	for _, encrypt := range []bool{false, true} {
+		manager := newSessions(t, "session secret", encrypt, loader, &now)
+		value := session{UserID: "user-1", Epoch: 2, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+		encoded, err := manager.encode(value)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		decoded, err := manager.decode(encoded, now)
+		if err != nil || decoded != value {
+			t.Errorf("decode (encrypt %v) = %+v, %v, want %+v", encrypt, decoded, err, value)
+		}
+
+		if encrypt {
+			raw, _ := base64.RawURLEncoding.DecodeString(encoded)
+			if strings.Contains(string(raw), "user-1") {
+				t.Error("the encrypted cookie exposes the user id")
+			}
+			other, err := manager.encode(value)
+			if err != nil || other == encoded {
+				t.Error("encode reused the AES-GCM nonce")
+			}
+		}
+	}
+}
+
+func TestSessionDecodeInvalid(t *testing.T) {
+	now := testNow
+	loader := &users{}
+	valid := session{UserID: "user-1", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name    string
+		encrypt bool
+		value   session
+		change  func(encoded string) string
+		manager func() *SessionManager
+		at      time.Time
+	}{
+		{
+			name:   "tampered signature",
+			value:  valid,
+			change: func(encoded string) string { return encoded[:len(encoded)-2] + "AA" },
+		},
+		{
+			name:  "tampered content",
+			value: valid,
+			change: func(encoded string) string {
+				other := session{UserID: "admin", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+				payload, _ := newSessions(t, "session secret", false, loader, &now).encode(other)
+				return strings.Split(payload, ".")[0] + "." + strings.Split(encoded, ".")[1]
+			},
+		},
+		{
+			name:    "wrong key",
+			value:   valid,
+			manager: func() *SessionManager { return newSessions(t, "other secret", false, loader, &now) },
+		},
+		{
+			name:    "encrypted with the wrong key",
+			encrypt: true,
+			value:   valid,
+			manager: func() *SessionManager { return newSessions(t, "other secret", true, loader, &now) },
+		},
+		{
+			name:    "tampered ciphertext",
+			encrypt: true,
+			value:   valid,
+			change: func(encoded string) string {
+				raw, _ := base64.RawURLEncoding.DecodeString(encoded)
+				raw[len(raw)-1] ^= 1
+				return base64.RawURLEncoding.EncodeToString(raw)
+			},
+		},
+		{
+			name:    "signed cookie on an encrypted manager",
+			value:   valid,
+			manager: func() *SessionManager { return newSessions(t, "session secret", true, loader, &now) },
+		},
+		{
+			name:  "expired",
+			value: valid,
+			at:    now.Add(time.Hour),
+		},
+		{
+			name:  "past the absolute lifetime",
+			value: session{UserID: "user-1", IssuedAt: now.Add(-3 * time.Hour).Unix(), ExpiresAt: now.Add(time.Hour).Unix()},
+		},
+		{
+			name:  "without iat",
+			value: session{UserID: "user-1", ExpiresAt: now.Add(time.Hour).Unix()},
+		},
+		{
+			name:   "malformed",
+			value:  valid,
+			change: func(encoded string) string { return strings.Replace(encoded, ".", "", 1) },
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded, err := newSessions(t, "session secret", test.encrypt, loader, &now).encode(test.value)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			if test.change != nil {
+				encoded = test.change(encoded)
+			}
+			manager := newSessions(t, "session secret", test.encrypt, loader, &now)
+			if test.manager != nil {
+				manager = test.manager()
+			}
+			at := now
+			if !test.at.IsZero() {
+				at = test.at
+			}
+			if _, err = manager.decode(encoded, at); !errors.Is(err, errInvalidSession) {
+				t.Errorf("decode = %v, want invalid session", err)
+			}
+		})
+	}
+}
+
+func TestSessionAuthenticate(t *testing.T) {
+	now := testNow
+	loader := &users{items: map[string]User{"user-1": {ID: "user-1", Name: "Ana"}}}
+	manager := newSessions(t, "session secret", false, loader, &now)
+
+	_, recorder := serve(manager, "", func(ctx context.Context) {
+		if err := Login(ctx, "user-1"); err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+	})
+	cookie := responseCookie(t, recorder)
+	if cookie == nil || !cookie.HttpOnly || cookie.MaxAge != 3600 {
+		t.Fatalf("Login cookie = %+v", cookie)
+	}
+
+	// Before half of MaxAge the cookie is not renewed
+	now = testNow.Add(20 * time.Minute)
+	user, recorder := serve(manager, cookie.Value, nil)
+	if user.ID != "user-1" || user.Name != "Ana" {
+		t.Fatalf("user = %+v, want user-1", user)
+	}
+	if renewed := responseCookie(t, recorder); renewed != nil {
+		t.Errorf("cookie renewed before half of MaxAge")
+	}
+
+	// After half of MaxAge the cookie is renewed, keeping the login time
+	for step := 1; step <= 3; step++ {
+		now = testNow.Add(time.Duration(step) * 40 * time.Minute)
+		user, recorder = serve(manager, cookie.Value, nil)
+		if user.ID != "user-1" {
+			t.Fatalf("user at %d = %+v, want user-1", step, user)
+		}
+		renewed := responseCookie(t, recorder)
+		if renewed == nil {
+			t.Fatalf("cookie not renewed at %s", now.Sub(testNow))
+		}
+		cookie = renewed
+	}
+	value, err := manager.decode(cookie.Value, now)
+	if err != nil || value.IssuedAt != testNow.Unix() || value.ExpiresAt != testNow.Add(3*time.Hour).Unix() {
+		t.Errorf("renewed session = %+v, %v, want the login time and the absolute expiration", value, err)
+	}
+
+	// The renewal does not extend the session past the absolute lifetime
+	now = testNow.Add(170 * time.Minute)
+	if _, recorder = serve(manager, cookie.Value, nil); responseCookie(t, recorder) != nil {
+		t.Error("cookie renewed at the absolute lifetime")
+	}
+	now = testNow.Add(3 * time.Hour)
+	user, recorder = serve(manager, cookie.Value, nil)
+	if !user.Anonymous {
+		t.Errorf("user past the absolute lifetime = %+v, want anonymous", user)
+	}
+	if cleared := responseCookie(t, recorder); cleared == nil || cleared.MaxAge >= 0 {
+		t.Errorf("cookie past the absolute lifetime was not cleared")
+	}
+}
+
+func TestSessionRevocation(t *testing.T) {
+	now := testNow
+	loader := &users{items: map[string]User{"user-1": {ID: "user-1"}, "user-2": {ID: "user-2", SessionEpoch: 4}}}
+	manager := newSessions(t, "session secret", true, loader, &now)
+
+	login := func(id string) string {
+		_, recorder := serve(manager, "", func(ctx context.Context) {
+			if err := Login(ctx, id); err != nil {
+				t.Fatalf("Login: %v", err)
+			}
+		})
+		return responseCookie(t, recorder).Value
+	}
+	first, copied, other := login("user-1"), login("user-1"), login("user-2")
+
+	// Logout revokes every session of the user, including copies of the cookie
+	_, recorder := serve(manager, first, func(ctx context.Context) {
+		if err := Logout(ctx); err != nil {
+			t.Fatalf("Logout: %v", err)
+		}
+	})
+	if cleared := responseCookie(t, recorder); cleared == nil || cleared.Value != "" {
+		t.Error("Logout did not clear the cookie")
+	}
+	if len(loader.revoked) != 1 || loader.revoked[0] != "user-1" {
+		t.Errorf("revoked = %v, want [user-1]", loader.revoked)
+	}
+	for _, cookie := range []string{first, copied} {
+		if user, recorder := serve(manager, cookie, nil); !user.Anonymous || responseCookie(t, recorder) == nil {
+			t.Errorf("revoked session = %+v, want anonymous with the cookie cleared", user)
+		}
+	}
+	if user, _ := serve(manager, other, nil); user.ID != "user-2" {
+		t.Errorf("session of another user = %+v, want user-2", user)
+	}
+
+	// A new login uses the current epoch
+	if user, _ := serve(manager, login("user-1"), nil); user.ID != "user-1" {
+		t.Errorf("new session = %+v, want user-1", user)
+	}
+
+	// Deleted users lose the session
+	delete(loader.items, "user-2")
+	if user, recorder := serve(manager, other, nil); !user.Anonymous || responseCookie(t, recorder) == nil {
+		t.Errorf("session of a deleted user = %+v, want anonymous with the cookie cleared", user)
+	}
+}
@@ -0,0 +1,433 @@
+// Package audit registra o log da aplicação com todas as mutações: ação, módulo, usuário da sessão,
+// argumentos com os segredos ocultados, resultado, tempo de execução e identificadores afetados.
+// Os registros são gravados em um Store (coleção do MongoDB, tabela do MySQL ou arquivo JSONL local),
+// consultados pela query apiAuditLog e removidos pela política de retenção.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/search"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const module = "api_connect/audit"
+
+// Redacted é o valor gravado no lugar dos argumentos secretos.
+const Redacted = "[REDACTED]"
+
+// DefaultRetention é o período padrão de retenção dos registros.
+const DefaultRetention = 180 * 24 * time.Hour
+
+// DefaultRetentionInterval é o intervalo de StartRetention quando o intervalo informado não é positivo.
+const DefaultRetentionInterval = 24 * time.Hour
+
+// DefaultSecrets são os trechos de nomes de argumentos cujos valores são ocultados, sem diferenciar maiúsculas.
+var DefaultSecrets = []string{
+	"password", "passwd", "secret", "token", "authorization", "cookie",
+	"apikey", "api_key", "credential", "privatekey", "private_key", "codechallenge", "code_challenge",
+}
+
+// Entry é um registro do log da aplicação.
+type Entry struct {
+	ID          string                 `json:"_id" bson:"_id"`
+	Action      string                 `json:"action" bson:"action"`
+	Module      string                 `json:"module" bson:"module"`
+	User        string                 `json:"user" bson:"user"`
+	Arguments   map[string]interface{} `json:"arguments" bson:"arguments"`
+	Success     bool                   `json:"success" bson:"success"`
+	Error       string                 `json:"error,omitempty" bson:"error,omitempty"`
+	ElapsedTime string                 `json:"elapsedTime" bson:"elapsedTime"`
+	AffectedIds []string               `json:"affectedIds" bson:"affectedIds"`
+	Changes     []interface{}          `json:"changes,omitempty" bson:"changes,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt" bson:"createdAt"`
+}
+
+// ApiAuditFilter é a representação Go do input GraphQL ApiAuditFilter.
+type ApiAuditFilter struct {
+	Action  *string `json:"action"`
+	Module  *string `json:"module"`
+	User    *string `json:"user"`
+	Success *bool   `json:"success"`
+}
+
+// ApiAuditResult é a representação Go do tipo GraphQL ApiAuditResult.
+type ApiAuditResult struct {
+	Items    []*Entry            `json:"items"`
+	PageInfo *params.ApiPageInfo `json:"pageInfo"`
+}
+
+// ApiAuditResponse é a representação Go do tipo GraphQL ApiAuditResponse.
+type ApiAuditResponse struct {
+	Success     bool            `json:"success"`
+	Result      *ApiAuditResult `json:"result"`
+	ElapsedTime string          `json:"elapsedTime"`
+}
+
+// Query é uma consulta aos registros, filtrando a data de criação por ApiDateSearchInput.created.
+type Query struct {
+	Filter     *ApiAuditFilter
+	Date       *params.ApiDateSearchInput
+	Pagination *params.ApiPaginationInput
+}
+
+// expression converte o filtro e a data da consulta em uma expressão sobre os campos
+// action, module, user, success e createdAt.
+func (q Query) expression() (filter.Expression, error) {
+	var expressions []filter.Expression
+	if q.Filter != nil {
+		if q.Filter.Action != nil {
+			expressions = append(expressions, filter.Condition("action", params.ComparisonQueryOperatorsEqualEq, *q.Filter.Action))
+		}
+		if q.Filter.Module != nil {
+			expressions = append(expressions, filter.Condition("module", params.ComparisonQueryOperatorsEqualEq, *q.Filter.Module))
+		}
+		if q.Filter.User != nil {
+			expressions = append(expressions, filter.Condition("user", params.ComparisonQueryOperatorsEqualEq, *q.Filter.User))
+		}
+		if q.Filter.Success != nil {
+			expressions = append(expressions, filter.Condition("success", params.ComparisonQueryOperatorsEqualEq, *q.Filter.Success))
+		}
+	}
+
+	date, err := search.DateExpression(q.Date, search.DateFields{Created: "createdAt", Updated: "createdAt"})
+	if err != nil {
+		return filter.Expression{}, err
+	}
+	if !date.IsEmpty() {
+		expressions = append(expressions, date)
+	}
+	return filter.And(expressions...), nil
+}
+
+// Store grava, consulta e remove registros do log da aplicação.
+type Store interface {
+	Write(ctx context.Context, entry *Entry) error
+	Find(ctx context.Context, query Query) ([]*Entry, *params.ApiPageInfo, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Logger grava os registros em um Store, ocultando os argumentos secretos.
+type Logger struct {
+	store     Store
+	retention time.Duration
+	secrets   []string
+}
+
+// NewLogger cria um Logger. Retention menor ou igual a zero utiliza DefaultRetention.
+// Se secrets não for informado, utiliza DefaultSecrets.
+func NewLogger(store Store, retention time.Duration, secrets ...string) *Logger {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	if len(secrets) == 0 {
+		secrets = DefaultSecrets
+	}
+	return &Logger{store: store, retention: retention, secrets: secrets}
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger *Logger
+)
+
+// SetDefault define o Logger utilizado por Record, normalmente na inicialização do servidor.
+func SetDefault(logger *Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = logger
+}
+
+// Default retorna o Logger padrão, ou nil se não foi definido.
+func Default() *Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+// Mutation cria o registro de uma mutação a partir do resultado do serviço.
+// Module é o projeto/pacote da ação, por exemplo "app/account".
+func Mutation(module string, action string, arguments map[string]interface{}, result interface{}, err error, start time.Time) *Entry {
+	entry := &Entry{
+		Action:      action,
+		Module:      module,
+		Arguments:   arguments,
+		Success:     err == nil,
+		ElapsedTime: time.Since(start).String(),
+		AffectedIds: AffectedIds(result),
+		Changes:     Changes(result),
+		CreatedAt:   start.UTC(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// Record grava o registro com o Logger padrão. Não faz nada se o Logger padrão não foi definido.
+func Record(ctx context.Context, entry *Entry) {
+	if logger := Default(); logger != nil {
+		logger.Record(ctx, entry)
+	}
+}
+
+// Record completa o registro com o identificador, o usuário da sessão e os argumentos ocultados e o grava.
+// Os valores dos argumentos secretos repetidos na mensagem de erro também são ocultados.
+// Falhas na gravação são escritas no log padrão e não interrompem a mutação.
+func (l *Logger) Record(ctx context.Context, entry *Entry) {
+	if entry.ID == "" {
+		entry.ID = primitive.NewObjectID().Hex()
+	}
+	if user, ok := middleware.ForContext(ctx); ok && entry.User == "" {
+		entry.User = user.ID
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+	entry.Error = l.redactText(entry.Error, entry.Arguments)
+	entry.Arguments = l.Redact(entry.Arguments)
+	for index, changes := range entry.Changes {
+		entry.Changes[index] = l.redactChanges(changes)
+	}
+
+	if err := l.store.Write(ctx, entry); err != nil {
+		log.Printf("Falha ao gravar o log da aplicação (%s): %v", entry.Action, err)
+	}
+}
+
+// Redact retorna uma cópia dos argumentos com os valores secretos substituídos por Redacted.
+// Estruturas são convertidas pela representação JSON.
+func (l *Logger) Redact(arguments map[string]interface{}) map[string]interface{} {
+	if arguments == nil {
+		return nil
+	}
+	result, _ := l.redact(toJSONValue(arguments)).(map[string]interface{})
+	return result
+}
+
+// redact oculta recursivamente os valores secretos.
+func (l *Logger) redact(value interface{}) interface{} {
+	switch item := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(item))
+		for key, entry := range item {
+			if l.isSecret(key) {
+				result[key] = Redacted
+			} else {
+				result[key] = l.redact(entry)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(item))
+		for index, entry := range item {
+			result[index] = l.redact(entry)
+		}
+		return result
+	}
+	return value
+}
+
+// redactChanges oculta os valores dos campos secretos de um ApiChangeDiff: as operações do patch
+// cujo caminho é secreto e os valores anteriores e posteriores indexados por caminho.
+func (l *Logger) redactChanges(changes interface{}) interface{} {
+	item, ok := changes.(map[string]interface{})
+	if !ok {
+		return l.redact(changes)
+	}
+	result := l.redact(item).(map[string]interface{})
+	if patch, ok := item["patch"].([]interface{}); ok {
+		operations := make([]interface{}, len(patch))
+		for index, entry := range patch {
+			operation, isMap := entry.(map[string]interface{})
+			path, _ := operation["path"].(string)
+			if isMap && l.isSecret(path) {
+				if _, hasValue := operation["value"]; hasValue {
+					copied := make(map[string]interface{}, len(operation))
+					for key, value := range operation {
+						copied[key] = value
+					}
+					copied["value"] = Redacted
+					entry = copied
+				}
+			}
+			operations[index] = l.redact(entry)
+		}
+		result["patch"] = operations
+	}
+	return result
+}
+
+// redactText substitui na mensagem os valores dos argumentos secretos por Redacted, dos mais longos aos mais curtos.
+func (l *Logger) redactText(text string, arguments map[string]interface{}) string {
+	if text == "" || arguments == nil {
+		return text
+	}
+
+	var values []string
+	var collect func(value interface{}, secret bool)
+	collect = func(value interface{}, secret bool) {
+		switch item := value.(type) {
+		case map[string]interface{}:
+			for key, entry := range item {
+				collect(entry, secret || l.isSecret(key))
+			}
+		case []interface{}:
+			for _, entry := range item {
+				collect(entry, secret)
+			}
+		case nil:
+		default:
+			if value := fmt.Sprint(item); secret && value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	collect(toJSONValue(arguments), false)
+
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, value := range values {
+		text = strings.ReplaceAll(text, value, Redacted)
+	}
+	return text
+}
+
+// isSecret verifica se o nome do argumento contém algum dos trechos secretos.
+func (l *Logger) isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range l.secrets {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// toJSONValue converte o valor para mapas, listas e escalares pela representação JSON.
+func toJSONValue(value interface{}) interface{} {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%T", value)
+	}
+	var result interface{}
+	if err = json.Unmarshal(content, &result); err != nil {
+		return fmt.Sprintf("%T", value)
+	}
+	return result
+}
+
+// AffectedIds extrai os identificadores (_id ou id) do resultado de uma mutação, inclusive de listas.
+func AffectedIds(result interface{}) []string {
+	ids := []string{}
+	var collect func(value interface{})
+	collect = func(value interface{}) {
+		switch item := value.(type) {
+		case map[string]interface{}:
+			for _, key := range []string{"_id", "id"} {
+				if id, ok := item[key]; ok && id != nil {
+					ids = append(ids, fmt.Sprint(id))
+					return
+				}
+			}
+		case []interface{}:
+			for _, entry := range item {
+				collect(entry)
+			}
+		}
+	}
+	if result != nil {
+		collect(toJSONValue(result))
+	}
+	return ids
+}
+
+// Changes extrai os campos alterados (diff) do resultado de uma mutação, inclusive de listas.
+func Changes(result interface{}) []interface{} {
+	var changes []interface{}
+	var collect func(value interface{})
+	collect = func(value interface{}) {
+		switch item := value.(type) {
+		case map[string]interface{}:
+			if changed, ok := item["diff"]; ok && changed != nil {
+				changes = append(changes, changed)
+				return
+			}
+			for _, entry := range item {
+				collect(entry)
+			}
+		case []interface{}:
+			for _, entry := range item {
+				collect(entry)
+			}
+		}
+	}
+	if result != nil {
+		collect(toJSONValue(result))
+	}
+	return changes
+}
+
+// Find consulta os registros. Somente administradores podem consultar o log da aplicação.
+func (l *Logger) Find(ctx context.Context, query Query) ([]*Entry, *params.ApiPageInfo, error) {
+	if user, _ := middleware.ForContext(ctx); !user.IsAdmin {
+		return nil, nil, api_error.New(api_error.CodePermissionDenied, module, "apiAuditLog", "somente administradores podem consultar o log da aplicação")
+	}
+	if query.Date != nil && query.Date.Updated != nil {
+		return nil, nil, api_error.New(api_error.CodeInvalidArgument, module, "date", "o log da aplicação permite filtrar somente a data de criação")
+	}
+	return l.store.Find(ctx, query)
+}
+
+// ApiAuditLogQuery implementa a query apiAuditLog, retornando o envelope ApiAuditResponse.
+func (l *Logger) ApiAuditLogQuery(ctx context.Context, filter *ApiAuditFilter, date *params.ApiDateSearchInput, pagination *params.ApiPaginationInput) (*ApiAuditResponse, error) {
+	_timeStart := time.Now()
+
+	items, pageInfo, err := l.Find(ctx, Query{Filter: filter, Date: date, Pagination: pagination})
+	if err != nil {
+		return nil, err
+	}
+
+	_response := ApiAuditResponse{
+		Result:      &ApiAuditResult{Items: items, PageInfo: pageInfo},
+		Success:     true,
+		ElapsedTime: time.Since(_timeStart).String(),
+	}
+	return &_response, nil
+}
+
+// Purge remove os registros anteriores ao período de retenção.
+func (l *Logger) Purge(ctx context.Context) (int64, error) {
+	return l.store.Purge(ctx, time.Now().Add(-l.retention))
+}
+
+// StartRetention executa Purge no intervalo informado até o contexto ser cancelado.
+// Um intervalo menor ou igual a zero utiliza DefaultRetentionInterval.
+func (l *Logger) StartRetention(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRetentionInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := l.Purge(ctx); err != nil {
+				log.Printf("Falha ao remover registros do log da aplicação: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,225 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/pagination"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileStore grava o log da aplicação em um arquivo local JSONL, um registro por linha.
+// Indicado para desenvolvimento e instalações pequenas, pois as consultas leem o arquivo inteiro.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore cria um Store para o arquivo informado. O arquivo é criado na primeira gravação.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Write adiciona o registro ao final do arquivo.
+func (s *FileStore) Write(_ context.Context, entry *Entry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(content, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Find consulta os registros, dos mais recentes aos mais antigos.
+func (s *FileStore) Find(_ context.Context, query Query) ([]*Entry, *params.ApiPageInfo, error) {
+	expression, err := query.expression()
+	if err != nil {
+		return nil, nil, err
+	}
+	page, err := pagination.Normalize(query.Pagination, pagination.MaxSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	entries, err := s.read()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var found []*Entry
+	for _, entry := range entries {
+		if match(expression, entry) {
+			found = append(found, entry)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if !found[i].CreatedAt.Equal(found[j].CreatedAt) {
+			return found[i].CreatedAt.After(found[j].CreatedAt)
+		}
+		return found[i].ID > found[j].ID
+	})
+
+	result := []*Entry{}
+	for position := page.Offset; position < len(found) && position < page.Offset+page.Size; position++ {
+		result = append(result, found[position])
+	}
+	return result, page.PageInfo(len(found)), nil
+}
+
+// Purge reescreve o arquivo sem os registros criados antes da data informada.
+func (s *FileStore) Purge(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil || len(entries) == 0 {
+		return 0, err
+	}
+
+	temp := s.path + ".tmp"
+	file, err := os.OpenFile(temp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+
+	var removed int64
+	for _, entry := range entries {
+		if entry.CreatedAt.Before(before) {
+			removed++
+			continue
+		}
+		if err = encoder.Encode(entry); err != nil {
+			_ = file.Close()
+			return 0, err
+		}
+	}
+	if err = writer.Flush(); err != nil {
+		_ = file.Close()
+		return 0, err
+	}
+	if err = file.Close(); err != nil {
+		return 0, err
+	}
+	return removed, os.Rename(temp, s.path)
+}
+
+// read lê todos os registros do arquivo. Requer o bloqueio do FileStore.
+func (s *FileStore) read() ([]*Entry, error) {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, scanner.Err()
+}
+
+// match avalia a expressão de consulta sobre o registro.
+func match(expression filter.Expression, entry *Entry) bool {
+	for _, item := range expression.And {
+		if !match(item, entry) {
+			return false
+		}
+	}
+	if len(expression.Or) > 0 {
+		found := false
+		for _, item := range expression.Or {
+			if match(item, entry) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if expression.Field == "" {
+		return true
+	}
+
+	var value interface{}
+	switch expression.Field {
+	case "action":
+		value = entry.Action
+	case "module":
+		value = entry.Module
+	case "user":
+		value = entry.User
+	case "success":
+		value = entry.Success
+	case "createdAt":
+		value = entry.CreatedAt
+	}
+	return compare(value, expression.Operator, expression.Value)
+}
+
+// compare aplica o operador de comparação entre o valor do registro e o valor da consulta.
+func compare(value interface{}, operator params.ComparisonQueryOperators, expected interface{}) bool {
+	if date, ok := value.(time.Time); ok {
+		target, ok := expected.(time.Time)
+		if !ok {
+			return false
+		}
+		switch operator {
+		case params.ComparisonQueryOperatorsEqualEq:
+			return date.Equal(target)
+		case params.ComparisonQueryOperatorsNotEqualNe:
+			return !date.Equal(target)
+		case params.ComparisonQueryOperatorsGreaterThanGt:
+			return date.After(target)
+		case params.ComparisonQueryOperatorsGreaterThanOrEqualGte:
+			return !date.Before(target)
+		case params.ComparisonQueryOperatorsLessThanLt:
+			return date.Before(target)
+		case params.ComparisonQueryOperatorsLessThanOrEqualLte:
+			return !date.After(target)
+		}
+		return false
+	}
+
+	switch operator {
+	case params.ComparisonQueryOperatorsEqualEq:
+		return fmt.Sprint(value) == fmt.Sprint(expected)
+	case params.ComparisonQueryOperatorsNotEqualNe:
+		return fmt.Sprint(value) != fmt.Sprint(expected)
+	}
+	return false
+}
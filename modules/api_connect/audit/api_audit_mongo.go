@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/pagination"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// mongoFields são os campos filtráveis da coleção do log da aplicação.
+var mongoFields = filter.Fields{
+	"action":    "action",
+	"module":    "module",
+	"user":      "user",
+	"success":   "success",
+	"createdAt": "createdAt",
+}
+
+// MongoStore grava o log da aplicação em uma coleção do MongoDB.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoStore cria um Store para a coleção informada.
+func NewMongoStore(collection *mongo.Collection) *MongoStore {
+	return &MongoStore{collection: collection}
+}
+
+// EnsureIndexes cria os índices de consulta por data, ação e usuário.
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "action", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "user", Value: 1}, {Key: "createdAt", Value: -1}}},
+	})
+	return err
+}
+
+// Write grava o registro.
+func (s *MongoStore) Write(ctx context.Context, entry *Entry) error {
+	_, err := s.collection.InsertOne(ctx, entry)
+	return err
+}
+
+// Find consulta os registros, dos mais recentes aos mais antigos.
+func (s *MongoStore) Find(ctx context.Context, query Query) ([]*Entry, *params.ApiPageInfo, error) {
+	expression, err := query.expression()
+	if err != nil {
+		return nil, nil, err
+	}
+	where, err := filter.NewCompiler(mongoFields).Mongo(expression)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := []*Entry{}
+	sort := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+	pageInfo, err := pagination.FindMongo(ctx, s.collection, where, query.Pagination, &entries, sort)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entries, pageInfo, nil
+}
+
+// Purge remove os registros criados antes da data informada.
+func (s *MongoStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.D{{Key: "createdAt", Value: bson.D{{Key: "$lt", Value: before}}}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
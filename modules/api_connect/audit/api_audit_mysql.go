@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/pagination"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"time"
+)
+
+// mysqlFields são as colunas filtráveis da tabela do log da aplicação.
+var mysqlFields = filter.Fields{
+	"action":    "action",
+	"module":    "module",
+	"user":      "user",
+	"success":   "success",
+	"createdAt": "created_at",
+}
+
+// mysqlColumns são as colunas lidas da tabela do log da aplicação.
+const mysqlColumns = "`id`, `action`, `module`, `user`, `arguments`, `success`, `error`, `elapsed_time`, `affected_ids`, `changes`, `created_at`"
+
+// MysqlStore grava o log da aplicação em uma tabela do MySQL.
+// O DSN deve conter parseTime=true para a leitura das datas.
+type MysqlStore struct {
+	db    *mysql.MysqlDB
+	table string
+}
+
+// NewMysqlStore cria um Store para a tabela informada.
+func NewMysqlStore(db *mysql.MysqlDB, table string) *MysqlStore {
+	return &MysqlStore{db: db, table: table}
+}
+
+// EnsureTable cria a tabela do log da aplicação, caso ainda não exista.
+func (s *MysqlStore) EnsureTable(ctx context.Context) error {
+	table, err := mysql.QuoteIdentifier(s.table)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Client().ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+table+" ("+
+		"`id` VARCHAR(24) NOT NULL PRIMARY KEY, "+
+		"`action` VARCHAR(255) NOT NULL, "+
+		"`module` VARCHAR(255) NOT NULL, "+
+		"`user` VARCHAR(255) NOT NULL, "+
+		"`arguments` JSON NULL, "+
+		"`success` BOOLEAN NOT NULL, "+
+		"`error` TEXT NULL, "+
+		"`elapsed_time` VARCHAR(64) NOT NULL, "+
+		"`affected_ids` JSON NULL, "+
+		"`changes` JSON NULL, "+
+		"`created_at` DATETIME(6) NOT NULL, "+
+		"INDEX `audit_created_at` (`created_at`), "+
+		"INDEX `audit_action_created_at` (`action`, `created_at`), "+
+		"INDEX `audit_user_created_at` (`user`, `created_at`))")
+	return err
+}
+
+// Write grava o registro.
+func (s *MysqlStore) Write(ctx context.Context, entry *Entry) error {
+	table, err := mysql.QuoteIdentifier(s.table)
+	if err != nil {
+		return err
+	}
+	arguments, err := json.Marshal(entry.Arguments)
+	if err != nil {
+		return err
+	}
+	affectedIds, err := json.Marshal(entry.AffectedIds)
+	if err != nil {
+		return err
+	}
+	var changes interface{}
+	if len(entry.Changes) > 0 {
+		content, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return err
+		}
+		changes = string(content)
+	}
+
+	_, err = s.db.Client().ExecContext(ctx, "INSERT INTO "+table+" ("+mysqlColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		entry.ID, entry.Action, entry.Module, entry.User, string(arguments), entry.Success, entry.Error,
+		entry.ElapsedTime, string(affectedIds), changes, entry.CreatedAt)
+	return err
+}
+
+// Find consulta os registros, dos mais recentes aos mais antigos.
+func (s *MysqlStore) Find(ctx context.Context, query Query) ([]*Entry, *params.ApiPageInfo, error) {
+	table, err := mysql.QuoteIdentifier(s.table)
+	if err != nil {
+		return nil, nil, err
+	}
+	expression, err := query.expression()
+	if err != nil {
+		return nil, nil, err
+	}
+	where, args, err := filter.NewCompiler(mysqlFields).Mysql(expression)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := []*Entry{}
+	sqlQuery := "SELECT " + mysqlColumns + " FROM " + table + " WHERE " + where + " ORDER BY `created_at` DESC, `id` DESC"
+	pageInfo, err := pagination.QueryMysql(ctx, s.db, sqlQuery, args, query.Pagination, func(rows *sql.Rows) error {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return entries, pageInfo, nil
+}
+
+// Purge remove os registros criados antes da data informada.
+func (s *MysqlStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	table, err := mysql.QuoteIdentifier(s.table)
+	if err != nil {
+		return 0, err
+	}
+	result, err := s.db.Client().ExecContext(ctx, "DELETE FROM "+table+" WHERE `created_at` < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// scanEntry lê um registro da linha atual.
+func scanEntry(rows *sql.Rows) (*Entry, error) {
+	var (
+		entry                           Entry
+		arguments, affectedIds, changes []byte
+		errorText                       sql.NullString
+	)
+	err := rows.Scan(&entry.ID, &entry.Action, &entry.Module, &entry.User, &arguments, &entry.Success, &errorText,
+		&entry.ElapsedTime, &affectedIds, &changes, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	entry.Error = errorText.String
+	if len(arguments) > 0 {
+		if err = json.Unmarshal(arguments, &entry.Arguments); err != nil {
+			return nil, err
+		}
+	}
+	if len(affectedIds) > 0 {
+		if err = json.Unmarshal(affectedIds, &entry.AffectedIds); err != nil {
+			return nil, err
+		}
+	}
+	if len(changes) > 0 {
+		if err = json.Unmarshal(changes, &entry.Changes); err != nil {
+			return nil, err
+		}
+	}
+	return &entry, nil
+}
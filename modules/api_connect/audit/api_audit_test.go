@@ -0,0 +1,261 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// authenticator identifica todas as requisições com o usuário informado.
+type authenticator struct {
+	user middleware.User
+}
+
+func (a authenticator) Authenticate(http.ResponseWriter, *http.Request) (middleware.User, bool, error) {
+	return a.user, true, nil
+}
+
+// contextFor retorna o contexto de uma requisição autenticada pelo middleware com o usuário informado.
+func contextFor(user middleware.User) context.Context {
+	var ctx context.Context
+	handler := middleware.Authenticate(authenticator{user: user})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	return ctx
+}
+
+// memoryStore guarda os registros gravados e as chamadas de Purge.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries []*Entry
+	purged  chan time.Time
+}
+
+func (s *memoryStore) Write(ctx context.Context, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memoryStore) Find(ctx context.Context, query Query) ([]*Entry, *params.ApiPageInfo, error) {
+	return s.entries, &params.ApiPageInfo{}, nil
+}
+
+func (s *memoryStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	s.purged <- before
+	return 0, nil
+}
+
+func TestRedact(t *testing.T) {
+	type credentials struct {
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+	logger := NewLogger(&memoryStore{}, 0)
+
+	got := logger.Redact(map[string]interface{}{
+		"name":          "Ana",
+		"NewPassword":   "s3cret",
+		"account":       credentials{Login: "ana", Password: "s3cret"},
+		"items":         []interface{}{map[string]interface{}{"apiKey": "k1", "label": "a"}},
+		"Authorization": "Bearer x",
+		"tokens":        []string{"t1", "t2"},
+	})
+	want := map[string]interface{}{
+		"name":          "Ana",
+		"NewPassword":   Redacted,
+		"account":       map[string]interface{}{"login": "ana", "password": Redacted},
+		"items":         []interface{}{map[string]interface{}{"apiKey": Redacted, "label": "a"}},
+		"Authorization": Redacted,
+		"tokens":        Redacted,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Redact = %v, want %v", got, want)
+	}
+	if logger.Redact(nil) != nil {
+		t.Error("Redact(nil) != nil")
+	}
+
+	custom := NewLogger(&memoryStore{}, 0, "cpf")
+	if got := custom.Redact(map[string]interface{}{"cpf": "123", "password": "x"}); got["cpf"] != Redacted || got["password"] != "x" {
+		t.Errorf("Redact with custom secrets = %v", got)
+	}
+}
+
+func TestRedactChanges(t *testing.T) {
+	logger := NewLogger(&memoryStore{}, 0)
+	changes := map[string]interface{}{
+		"patch": []interface{}{
+			map[string]interface{}{"op": "replace", "path": "/password", "value": "new"},
+			map[string]interface{}{"op": "add", "path": "/profile/apiKey", "value": "k2"},
+			map[string]interface{}{"op": "remove", "path": "/secretQuestion"},
+			map[string]interface{}{"op": "replace", "path": "/name", "value": "Bia"},
+		},
+		"before": map[string]interface{}{"password": "old", "profile.apiKey": nil, "secretQuestion": "pet", "name": "Ana"},
+		"after":  map[string]interface{}{"password": "new", "profile.apiKey": "k2", "secretQuestion": nil, "name": "Bia"},
+	}
+
+	want := map[string]interface{}{
+		"patch": []interface{}{
+			map[string]interface{}{"op": "replace", "path": "/password", "value": Redacted},
+			map[string]interface{}{"op": "add", "path": "/profile/apiKey", "value": Redacted},
+			map[string]interface{}{"op": "remove", "path": "/secretQuestion"},
+			map[string]interface{}{"op": "replace", "path": "/name", "value": "Bia"},
+		},
+		"before": map[string]interface{}{"password": Redacted, "profile.apiKey": Redacted, "secretQuestion": Redacted, "name": "Ana"},
+		"after":  map[string]interface{}{"password": Redacted, "profile.apiKey": Redacted, "secretQuestion": Redacted, "name": "Bia"},
+	}
+	if got := logger.redactChanges(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("redactChanges = %v, want %v", got, want)
+	}
+	if changes["patch"].([]interface{})[0].(map[string]interface{})["value"] != "new" {
+		t.Error("redactChanges changed the original patch")
+	}
+}
+
+func TestAffectedIdsAndChanges(t *testing.T) {
+	id, other := "64b000000000000000000001", "64b000000000000000000002"
+	changes := &params.ApiChangeDiff{
+		Patch:  []*params.ApiPatchOperation{{Op: "replace", Path: "/name", Value: "Bia"}},
+		Before: map[string]interface{}{"name": "Ana"},
+		After:  map[string]interface{}{"name": "Bia"},
+	}
+
+	tests := []struct {
+		name    string
+		result  interface{}
+		ids     []string
+		changes int
+	}{
+		{"nil", nil, []string{}, 0},
+		{"change result", &params.ApiChangeResult{ID: &id, Diff: changes}, []string{id}, 1},
+		{"list", []*params.ApiChangeResult{{ID: &id, Diff: changes}, {ID: &other}}, []string{id, other}, 1},
+		{"id field", map[string]interface{}{"id": 7, "name": "Ana"}, []string{"7"}, 0},
+		{"envelope", map[string]interface{}{"success": true, "result": map[string]interface{}{"diff": changes}}, []string{}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := AffectedIds(test.result); !reflect.DeepEqual(got, test.ids) {
+				t.Errorf("AffectedIds = %v, want %v", got, test.ids)
+			}
+			if got := Changes(test.result); len(got) != test.changes {
+				t.Errorf("Changes = %v, want %d items", got, test.changes)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	store := &memoryStore{}
+	logger := NewLogger(store, 0)
+	ctx := contextFor(middleware.User{ID: "user-1"})
+
+	arguments := map[string]interface{}{"input": map[string]interface{}{"email": "ana@example.com", "password": "hunter22"}}
+	entry := Mutation("app/account", "accountCreate", arguments, nil, errors.New(`senha "hunter22" fraca para ana@example.com`), time.Now())
+	logger.Record(ctx, entry)
+
+	if len(store.entries) != 1 {
+		t.Fatalf("store has %d entries, want 1", len(store.entries))
+	}
+	recorded := store.entries[0]
+	if recorded.ID == "" || recorded.User != "user-1" || recorded.Success {
+		t.Errorf("entry = %+v", recorded)
+	}
+	if strings.Contains(recorded.Error, "hunter22") || !strings.Contains(recorded.Error, Redacted) || !strings.Contains(recorded.Error, "ana@example.com") {
+		t.Errorf("Error = %q, want the password redacted", recorded.Error)
+	}
+	if input := recorded.Arguments["input"].(map[string]interface{}); input["password"] != Redacted {
+		t.Errorf("Arguments = %v, want the password redacted", recorded.Arguments)
+	}
+}
+
+func TestQueryExpression(t *testing.T) {
+	action, success := "accountCreate", false
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	compiler := filter.NewCompiler(filter.Fields{"action": "action", "module": "module", "user": "user", "success": "success", "createdAt": "created_at"})
+
+	tests := []struct {
+		name  string
+		query Query
+		where string
+		args  []interface{}
+	}{
+		{"empty", Query{}, "1 = 1", nil},
+		{
+			name:  "filter",
+			query: Query{Filter: &ApiAuditFilter{Action: &action, Success: &success}},
+			where: "(`action` = ? AND `success` = ?)",
+			args:  []interface{}{action, false},
+		},
+		{
+			name: "created",
+			query: Query{Date: &params.ApiDateSearchInput{Created: &params.ApiDateOptionsInput{
+				AStart: &params.ApiDateStartSearchInput{Date: start, Operator: params.DateStartComparisonOperatorsGreaterThanOrEqualGte},
+			}}},
+			where: "((`created_at` >= ?))",
+			args:  []interface{}{start},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expression, err := test.query.expression()
+			if err != nil {
+				t.Fatalf("expression: %v", err)
+			}
+			where, args, err := compiler.Mysql(expression)
+			if err != nil {
+				t.Fatalf("Mysql: %v", err)
+			}
+			if where != test.where || !reflect.DeepEqual(args, test.args) {
+				t.Errorf("expression = %q %v, want %q %v", where, args, test.where, test.args)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	logger := NewLogger(&memoryStore{}, 0)
+	admin := contextFor(middleware.User{ID: "admin", IsAdmin: true})
+
+	if _, _, err := logger.Find(contextFor(middleware.User{ID: "user-1"}), Query{}); !errors.Is(err, api_error.New(api_error.CodePermissionDenied, "", "")) {
+		t.Errorf("Find by user = %v, want PERMISSION_DENIED", err)
+	}
+	updated := &params.ApiDateSearchInput{Updated: &params.ApiDateOptionsInput{
+		AStart: &params.ApiDateStartSearchInput{Date: time.Now(), Operator: params.DateStartComparisonOperatorsGreaterThanGt},
+	}}
+	if _, _, err := logger.Find(admin, Query{Date: updated}); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+		t.Errorf("Find by updated = %v, want INVALID_ARGUMENT", err)
+	}
+	if _, _, err := logger.Find(admin, Query{}); err != nil {
+		t.Errorf("Find by admin = %v", err)
+	}
+}
+
+func TestStartRetention(t *testing.T) {
+	store := &memoryStore{purged: make(chan time.Time, 1)}
+	logger := NewLogger(store, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger.StartRetention(ctx, 0)
+	select {
+	case before := <-store.purged:
+		if since := time.Since(before); since < time.Hour || since > time.Hour+time.Minute {
+			t.Errorf("Purge before %s, want one hour ago", before)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("StartRetention did not purge")
+	}
+}
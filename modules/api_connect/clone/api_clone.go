@@ -0,0 +1,289 @@
+// Package clone copia documentos do MongoDB e linhas do MySQL com as relações filhas declaradas,
+// respeitando StatusCloneTypeEnum: documentos public podem ser clonados por qualquer usuário autenticado
+// e documentos private, ou sem o campo cloneType, somente pelo dono (ApiInfo.owner). Além do cloneType,
+// o documento precisa ser visível ao usuário pelas regras de privacy.Policy.
+//
+// A cópia recebe um novo ApiInfo: o usuário da sessão como dono, versão 1 e um novo histórico.
+// Os módulos que declaram o campo "cloneType: StatusCloneTypeEnum" no type.graphqls recebem a mutação
+// <projeto><Pacote>Clone gerada por main/schemas.go e main/resolvers.go. A coleção/tabela do módulo e as relações
+// filhas precisam ser registradas no Service padrão:
+//
+//	service := clone.NewService(clone.NewMongoStore(db, "app"))
+//	service.Register("app/project", clone.Target{Name: "project", UseObjectID: true, Relations: []clone.Relation{
+//		{Target: clone.Target{Name: "task", UseObjectID: true}, ForeignKey: "projectId"},
+//	}})
+//	clone.SetDefault(service)
+package clone
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/privacy"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"log"
+	"sync"
+	"time"
+)
+
+const module = "api_connect/clone"
+
+// Field é o campo (MongoDB) ou coluna (MySQL) com o StatusCloneTypeEnum do documento.
+const Field = "cloneType"
+
+// ActionClone é a ação registrada no histórico do ApiInfo das cópias.
+const ActionClone = "clone"
+
+// ApiCloneInput é a representação Go do input GraphQL ApiCloneInput.
+type ApiCloneInput struct {
+	ID string `json:"_id"`
+}
+
+// Target é a coleção/tabela de um módulo e as suas relações filhas.
+type Target struct {
+	// Name é o nome da coleção (MongoDB) ou tabela (MySQL).
+	Name string
+
+	// UseObjectID indica que os identificadores da coleção são ObjectID (MongoDB).
+	UseObjectID bool
+
+	// IDColumn é a coluna identificadora da tabela (MySQL), preenchida por AUTO_INCREMENT. Se vazia, utiliza "id".
+	IDColumn string
+
+	// Relations são as relações filhas copiadas com o documento.
+	Relations []Relation
+
+	// Privacy são as regras de visibilidade verificadas antes da cópia. Se nil, os documentos com o campo
+	// privacy são verificados pela privacy.Policy padrão, e os documentos sem o campo não são verificados.
+	Privacy *privacy.Policy
+}
+
+// Relation é uma relação filha: os documentos da coleção/tabela cujo ForeignKey referencia o documento pai.
+type Relation struct {
+	Target
+
+	// ForeignKey é o campo/coluna do filho com o identificador do pai. Na cópia, recebe o identificador da cópia do pai.
+	ForeignKey string
+}
+
+// Document é um documento lido do banco de dados.
+type Document struct {
+	// Key é o identificador do documento no banco de dados, por exemplo um ObjectID.
+	Key interface{}
+
+	// Owner é o identificador do dono do documento (ApiInfo.owner).
+	Owner string
+
+	// Values são os valores do documento, sem o identificador e o ApiInfo.
+	Values map[string]interface{}
+}
+
+// Store lê e grava os documentos em um banco de dados.
+type Store interface {
+	// Read retorna o documento, ou um erro CodeNotFound.
+	Read(ctx context.Context, target Target, id string) (*Document, error)
+
+	// Children retorna os documentos da relação filha cujo ForeignKey é o identificador do pai.
+	Children(ctx context.Context, relation Relation, parent interface{}) ([]*Document, error)
+
+	// Insert grava a cópia com um novo identificador e um novo ApiInfo, retornando o identificador gravado.
+	Insert(ctx context.Context, target Target, values map[string]interface{}, action info.Action) (interface{}, *params.ApiChangeResult, error)
+
+	// Delete remove uma cópia gravada por Insert, utilizado para desfazer uma clonagem com falha.
+	Delete(ctx context.Context, target Target, key interface{}) error
+}
+
+// copied é um documento gravado pela clonagem.
+type copied struct {
+	target Target
+	key    interface{}
+}
+
+// Service copia os documentos dos módulos registrados.
+type Service struct {
+	store   Store
+	mu      sync.RWMutex
+	targets map[string]Target
+}
+
+// NewService cria um Service com o Store informado (NewMongoStore ou NewMysqlStore).
+func NewService(store Store) *Service {
+	return &Service{store: store, targets: map[string]Target{}}
+}
+
+// Register registra a coleção/tabela de um módulo, identificado pelo projeto/pacote (ex.: "app/project").
+func (s *Service) Register(moduleName string, target Target) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.targets[moduleName] = target
+}
+
+// Clone copia o documento e as suas relações filhas, retornando o resultado da cópia do documento.
+// Documentos private, ou sem cloneType, e documentos não visíveis pela privacy.Policy retornam
+// CodePermissionDenied para usuários que não são o dono. Se a cópia de algum filho falhar, os documentos
+// já copiados são removidos.
+func (s *Service) Clone(ctx context.Context, moduleName string, id string) (*params.ApiChangeResult, error) {
+	s.mu.RLock()
+	target, ok := s.targets[moduleName]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "module", "módulo não registrado").
+			With("module", moduleName)
+	}
+
+	user, ok := middleware.ForContext(ctx)
+	if !ok {
+		return nil, api_error.New(api_error.CodeUnauthenticated, module, ActionClone, "usuário não autenticado")
+	}
+
+	source, err := s.store.Read(ctx, target, id)
+	if err != nil {
+		return nil, err
+	}
+	if cloneType(source.Values) != params.StatusCloneTypeEnumPublic && source.Owner != user.ID {
+		return nil, api_error.New(api_error.CodePermissionDenied, module, ActionClone, "somente o dono pode clonar um documento privado").
+			With("id", id)
+	}
+	if err = checkPrivacy(ctx, moduleName, target, id, source); err != nil {
+		return nil, err
+	}
+
+	action := info.NewAction(ActionClone)
+	var copies []copied
+	key, result, err := s.insert(ctx, &copies, target, source.Values, action)
+	if err == nil {
+		err = s.children(ctx, &copies, target.Relations, source.Key, key, action)
+	}
+	if err != nil {
+		s.rollback(copies)
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkPrivacy verifica se o documento é visível ao usuário pelas regras de visibilidade do módulo.
+func checkPrivacy(ctx context.Context, moduleName string, target Target, id string, source *Document) error {
+	policy := target.Privacy
+	value, hasPrivacy := source.Values[privacy.Field]
+	if policy == nil {
+		if !hasPrivacy {
+			return nil
+		}
+		policy = privacy.NewPolicy(moduleName, nil, nil)
+	}
+
+	var current status.StatusPrivacyEnum
+	switch item := value.(type) {
+	case string:
+		current = status.StatusPrivacyEnum(item)
+	case status.StatusPrivacyEnum:
+		current = item
+	}
+	return policy.Check(ctx, id, current, source.Owner)
+}
+
+// insert grava a cópia e a registra para que seja removida caso a clonagem falhe.
+func (s *Service) insert(ctx context.Context, copies *[]copied, target Target, values map[string]interface{}, action info.Action) (interface{}, *params.ApiChangeResult, error) {
+	key, result, err := s.store.Insert(ctx, target, values, action)
+	if err != nil {
+		return nil, nil, err
+	}
+	*copies = append(*copies, copied{target: target, key: key})
+	return key, result, nil
+}
+
+// rollback remove as cópias gravadas, da última para a primeira. Utiliza um novo contexto para que as cópias
+// sejam removidas mesmo quando a requisição foi cancelada; as falhas são escritas no log padrão.
+func (s *Service) rollback(copies []copied) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for index := len(copies) - 1; index >= 0; index-- {
+		if err := s.store.Delete(ctx, copies[index].target, copies[index].key); err != nil {
+			log.Printf("Falha ao remover a cópia %v de %s após a falha da clonagem: %v", copies[index].key, copies[index].target.Name, err)
+		}
+	}
+}
+
+// children copia os filhos de cada relação do documento original para a cópia, recursivamente.
+func (s *Service) children(ctx context.Context, copies *[]copied, relations []Relation, source interface{}, parent interface{}, action info.Action) error {
+	for _, relation := range relations {
+		items, err := s.store.Children(ctx, relation, source)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			values := make(map[string]interface{}, len(item.Values))
+			for key, value := range item.Values {
+				values[key] = value
+			}
+			values[relation.ForeignKey] = parent
+
+			key, _, err := s.insert(ctx, copies, relation.Target, values, action)
+			if err != nil {
+				return err
+			}
+			if err = s.children(ctx, copies, relation.Relations, item.Key, key, action); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cloneType retorna o StatusCloneTypeEnum do documento.
+func cloneType(values map[string]interface{}) params.StatusCloneTypeEnum {
+	switch value := values[Field].(type) {
+	case string:
+		return params.StatusCloneTypeEnum(value)
+	case params.StatusCloneTypeEnum:
+		return value
+	}
+	return ""
+}
+
+// ApiCloneMutation implementa a mutação de clonagem, retornando o envelope ApiChangeResponse.
+func (s *Service) ApiCloneMutation(ctx context.Context, moduleName string, input ApiCloneInput) (*params.ApiChangeResponse, error) {
+	_timeStart := time.Now()
+
+	_result, err := s.Clone(ctx, moduleName, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_response := params.ApiChangeResponse{
+		Result:      _result,
+		Success:     true,
+		ElapsedTime: time.Since(_timeStart).String(),
+	}
+	return &_response, nil
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultService *Service
+)
+
+// SetDefault define o Service utilizado pelas mutações geradas, normalmente na inicialização do servidor.
+func SetDefault(service *Service) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultService = service
+}
+
+// Default retorna o Service padrão, ou nil se não foi definido.
+func Default() *Service {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultService
+}
+
+// Clone copia o documento com o Service padrão.
+func Clone(ctx context.Context, moduleName string, id string) (*params.ApiChangeResult, error) {
+	service := Default()
+	if service == nil {
+		return nil, api_error.New(api_error.CodeInternal, module, "default", "serviço de clonagem não configurado")
+	}
+	return service.Clone(ctx, moduleName, id)
+}
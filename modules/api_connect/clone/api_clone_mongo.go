@@ -0,0 +1,115 @@
+package clone
+
+import (
+	"context"
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoStore lê e grava os documentos em coleções do MongoDB.
+type MongoStore struct {
+	db       *mongo.MongoDB
+	database string
+}
+
+// NewMongoStore cria um Store para o banco de dados MongoDB informado.
+func NewMongoStore(db *mongo.MongoDB, database string) *MongoStore {
+	return &MongoStore{db: db, database: database}
+}
+
+// Read retorna o documento.
+func (s *MongoStore) Read(ctx context.Context, target Target, id string) (*Document, error) {
+	key, err := mongoID(target, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var document bson.M
+	err = s.db.Collection(s.database, target.Name).FindOne(ctx, bson.D{{Key: "_id", Value: key}}).Decode(&document)
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return nil, api_error.New(api_error.CodeNotFound, module, ActionClone, "documento não encontrado").
+			With("id", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mongoDocument(document), nil
+}
+
+// Children retorna os documentos da relação filha.
+func (s *MongoStore) Children(ctx context.Context, relation Relation, parent interface{}) ([]*Document, error) {
+	cursor, err := s.db.Collection(s.database, relation.Name).Find(ctx, bson.D{{Key: relation.ForeignKey, Value: parent}})
+	if err != nil {
+		return nil, err
+	}
+	var documents []bson.M
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	result := make([]*Document, 0, len(documents))
+	for _, document := range documents {
+		result = append(result, mongoDocument(document))
+	}
+	return result, nil
+}
+
+// Insert grava a cópia pelo info.MongoWriter, com um novo ObjectID, ou o seu texto hexadecimal
+// quando a coleção não utiliza ObjectID.
+func (s *MongoStore) Insert(ctx context.Context, target Target, values map[string]interface{}, action info.Action) (interface{}, *params.ApiChangeResult, error) {
+	objectID := primitive.NewObjectID()
+	var key interface{} = objectID.Hex()
+	if target.UseObjectID {
+		key = objectID
+	}
+
+	document := bson.M{"_id": key}
+	for name, value := range values {
+		document[name] = value
+	}
+	writer := info.NewMongoWriter(s.db.Collection(s.database, target.Name))
+	result, err := writer.InsertOne(ctx, document, action)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, result, nil
+}
+
+// Delete remove a cópia.
+func (s *MongoStore) Delete(ctx context.Context, target Target, key interface{}) error {
+	_, err := s.db.Collection(s.database, target.Name).DeleteOne(ctx, bson.D{{Key: "_id", Value: key}})
+	return err
+}
+
+// mongoDocument separa o identificador, o dono e os valores do documento.
+func mongoDocument(document bson.M) *Document {
+	item := &Document{Key: document["_id"], Values: map[string]interface{}{}}
+	for key, value := range document {
+		if key != "_id" && key != info.Field {
+			item.Values[key] = value
+		}
+	}
+	if current, ok := document[info.Field].(bson.M); ok {
+		item.Owner, _ = current["owner"].(string)
+	}
+	return item
+}
+
+// mongoID converte o identificador para ObjectID quando a coleção utiliza ObjectID.
+func mongoID(target Target, id string) (interface{}, error) {
+	if !target.UseObjectID {
+		return id, nil
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "_id", "identificador inválido").
+			With("id", id)
+	}
+	return objectID, nil
+}
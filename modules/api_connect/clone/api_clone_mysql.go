@@ -0,0 +1,116 @@
+package clone
+
+import (
+	"context"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+)
+
+// infoColumns são as colunas do ApiInfo, que não são copiadas.
+var infoColumns = map[string]bool{
+	info.ColumnCreatedAt: true,
+	info.ColumnChangedAt: true,
+	info.ColumnOwner:     true,
+	info.ColumnVersion:   true,
+	info.ColumnChecksum:  true,
+	info.ColumnHistory:   true,
+}
+
+// MysqlStore lê e grava as linhas em tabelas do MySQL.
+type MysqlStore struct {
+	db *mysql.MysqlDB
+}
+
+// NewMysqlStore cria um Store para o MysqlDB informado.
+func NewMysqlStore(db *mysql.MysqlDB) *MysqlStore {
+	return &MysqlStore{db: db}
+}
+
+// Read retorna a linha.
+func (s *MysqlStore) Read(ctx context.Context, target Target, id string) (*Document, error) {
+	items, err := s.query(ctx, target, mysqlIDColumn(target), id)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, api_error.New(api_error.CodeNotFound, module, ActionClone, "documento não encontrado").
+			With("id", id)
+	}
+	return items[0], nil
+}
+
+// Children retorna as linhas da relação filha.
+func (s *MysqlStore) Children(ctx context.Context, relation Relation, parent interface{}) ([]*Document, error) {
+	return s.query(ctx, relation.Target, relation.ForeignKey, parent)
+}
+
+// Insert grava a cópia pelo info.MysqlWriter. O identificador é gerado pelo AUTO_INCREMENT da tabela.
+func (s *MysqlStore) Insert(ctx context.Context, target Target, values map[string]interface{}, action info.Action) (interface{}, *params.ApiChangeResult, error) {
+	writer := info.NewMysqlWriter(s.db, target.Name, mysqlIDColumn(target))
+	result, err := writer.Insert(ctx, values, action)
+	if err != nil {
+		return nil, nil, err
+	}
+	return *result.ID, result, nil
+}
+
+// Delete remove a cópia.
+func (s *MysqlStore) Delete(ctx context.Context, target Target, key interface{}) error {
+	table, err := mysql.QuoteIdentifier(target.Name)
+	if err != nil {
+		return err
+	}
+	idColumn, err := mysql.QuoteIdentifier(mysqlIDColumn(target))
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Client().ExecContext(ctx, "DELETE FROM "+table+" WHERE "+idColumn+" = ?", key)
+	return err
+}
+
+// query lê as linhas da tabela cuja coluna é igual ao valor informado.
+func (s *MysqlStore) query(ctx context.Context, target Target, column string, value interface{}) ([]*Document, error) {
+	table, err := mysql.QuoteIdentifier(target.Name)
+	if err != nil {
+		return nil, err
+	}
+	quoted, err := mysql.QuoteIdentifier(column)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Client().QueryContext(ctx, "SELECT * FROM "+table+" WHERE "+quoted+" = ?", value)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items, err := mysql.ScanMaps(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	idColumn := mysqlIDColumn(target)
+	result := make([]*Document, 0, len(items))
+	for _, row := range items {
+		item := &Document{Key: row[idColumn], Values: map[string]interface{}{}}
+		item.Owner, _ = row[info.ColumnOwner].(string)
+		for key, value := range row {
+			if key != idColumn && !infoColumns[key] {
+				item.Values[key] = value
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// mysqlIDColumn retorna a coluna identificadora da tabela.
+func mysqlIDColumn(target Target) string {
+	if target.IDColumn == "" {
+		return "id"
+	}
+	return target.IDColumn
+}
@@ -0,0 +1,244 @@
+package clone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/privacy"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// authenticator identifica todas as requisições com o usuário informado.
+type authenticator struct {
+	user middleware.User
+}
+
+func (a authenticator) Authenticate(http.ResponseWriter, *http.Request) (middleware.User, bool, error) {
+	return a.user, true, nil
+}
+
+// contextFor retorna o contexto de uma requisição autenticada pelo middleware com o usuário informado.
+func contextFor(user middleware.User) context.Context {
+	var ctx context.Context
+	handler := middleware.Authenticate(authenticator{user: user})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	return ctx
+}
+
+// memoryStore guarda os documentos por coleção, com identificadores sequenciais.
+// failAt faz a gravação de número failAt (a partir de 1) falhar.
+type memoryStore struct {
+	tables  map[string][]*Document
+	next    int
+	inserts int
+	failAt  int
+	deleted []interface{}
+}
+
+func (s *memoryStore) Read(ctx context.Context, target Target, id string) (*Document, error) {
+	for _, document := range s.tables[target.Name] {
+		if fmt.Sprint(document.Key) == id {
+			return document, nil
+		}
+	}
+	return nil, api_error.New(api_error.CodeNotFound, module, "id", "documento não encontrado")
+}
+
+func (s *memoryStore) Children(ctx context.Context, relation Relation, parent interface{}) ([]*Document, error) {
+	var result []*Document
+	for _, document := range s.tables[relation.Name] {
+		if document.Values[relation.ForeignKey] == parent {
+			result = append(result, document)
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryStore) Insert(ctx context.Context, target Target, values map[string]interface{}, action info.Action) (interface{}, *params.ApiChangeResult, error) {
+	s.inserts++
+	if s.inserts == s.failAt {
+		return nil, nil, errors.New("falha na gravação")
+	}
+	s.next++
+	key := s.next
+	s.tables[target.Name] = append(s.tables[target.Name], &Document{Key: key, Owner: "copy", Values: values})
+	id := fmt.Sprint(key)
+	return key, &params.ApiChangeResult{ID: &id}, nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, target Target, key interface{}) error {
+	s.deleted = append(s.deleted, key)
+	documents := s.tables[target.Name]
+	for index, document := range documents {
+		if document.Key == key {
+			s.tables[target.Name] = append(documents[:index], documents[index+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// project é o módulo app/project com tarefas e os comentários das tarefas.
+var project = Target{Name: "project", Relations: []Relation{
+	{Target: Target{Name: "task", Relations: []Relation{
+		{Target: Target{Name: "comment"}, ForeignKey: "taskId"},
+	}}, ForeignKey: "projectId"},
+}}
+
+// newStore cria um projeto (1) do user-1 com o cloneType informado, duas tarefas (2 e 3) e um comentário (4) da tarefa 3.
+// Um projeto (5) de outro dono com uma tarefa (6) não deve ser copiado.
+func newStore(values map[string]interface{}) *memoryStore {
+	return &memoryStore{next: 100, tables: map[string][]*Document{
+		"project": {
+			{Key: 1, Owner: "user-1", Values: values},
+			{Key: 5, Owner: "user-9", Values: map[string]interface{}{"name": "Outro"}},
+		},
+		"task": {
+			{Key: 2, Values: map[string]interface{}{"projectId": 1, "title": "Planejar"}},
+			{Key: 3, Values: map[string]interface{}{"projectId": 1, "title": "Executar"}},
+			{Key: 6, Values: map[string]interface{}{"projectId": 5, "title": "Outra"}},
+		},
+		"comment": {
+			{Key: 4, Values: map[string]interface{}{"taskId": 3, "text": "Revisar"}},
+		},
+	}}
+}
+
+func TestClonePermission(t *testing.T) {
+	owner := contextFor(middleware.User{ID: "user-1"})
+	other := contextFor(middleware.User{ID: "user-2"})
+	admin := contextFor(middleware.User{ID: "admin", IsAdmin: true})
+
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		ctx    context.Context
+		code   string
+	}{
+		{name: "public by another user", values: map[string]interface{}{Field: "public"}, ctx: other},
+		{name: "public enum by another user", values: map[string]interface{}{Field: params.StatusCloneTypeEnumPublic}, ctx: other},
+		{name: "public by the owner", values: map[string]interface{}{Field: "public"}, ctx: owner},
+		{name: "private by the owner", values: map[string]interface{}{Field: "private"}, ctx: owner},
+		{name: "private by another user", values: map[string]interface{}{Field: "private"}, ctx: other, code: api_error.CodePermissionDenied},
+		{name: "private by an administrator", values: map[string]interface{}{Field: "private"}, ctx: admin, code: api_error.CodePermissionDenied},
+		{name: "without cloneType by the owner", values: map[string]interface{}{}, ctx: owner},
+		{name: "without cloneType by another user", values: map[string]interface{}{}, ctx: other, code: api_error.CodePermissionDenied},
+		{name: "anonymous", values: map[string]interface{}{Field: "public"}, ctx: context.Background(), code: api_error.CodeUnauthenticated},
+		{name: "public and PRIVATE by another user", values: map[string]interface{}{Field: "public", privacy.Field: "PRIVATE"}, ctx: other},
+		{name: "public and PROTECTED by another user", values: map[string]interface{}{Field: "public", privacy.Field: "PROTECTED"}, ctx: other, code: api_error.CodePermissionDenied},
+		{name: "public and PROTECTED by the owner", values: map[string]interface{}{Field: "public", privacy.Field: "PROTECTED"}, ctx: owner},
+		{name: "public and UNDEFINED by another user", values: map[string]interface{}{Field: "public", privacy.Field: status.StatusPrivacyEnumUndefined}, ctx: other, code: api_error.CodePermissionDenied},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := newStore(test.values)
+			service := NewService(store)
+			service.Register("app/project", Target{Name: "project"})
+
+			result, err := service.Clone(test.ctx, "app/project", "1")
+			if test.code != "" {
+				if !errors.Is(err, api_error.New(test.code, "", "")) {
+					t.Errorf("Clone = %v, want %s", err, test.code)
+				}
+				if store.inserts != 0 {
+					t.Errorf("store inserted %d documents", store.inserts)
+				}
+				return
+			}
+			if err != nil || *result.ID != "101" {
+				t.Errorf("Clone = %v, %v, want 101", result, err)
+			}
+		})
+	}
+}
+
+func TestClonePolicy(t *testing.T) {
+	store := newStore(map[string]interface{}{Field: "public"})
+	service := NewService(store)
+	policy := privacy.NewPolicy("app/project", nil, nil)
+	policy.Default = status.StatusPrivacyEnumPublic
+	service.Register("app/project", Target{Name: "project", Privacy: policy})
+
+	if _, err := service.Clone(contextFor(middleware.User{ID: "user-2"}), "app/project", "1"); err != nil {
+		t.Errorf("Clone with the module Policy = %v", err)
+	}
+	if _, err := service.Clone(contextFor(middleware.User{ID: "user-2"}), "app/unknown", "1"); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+		t.Errorf("Clone of an unknown module = %v, want INVALID_ARGUMENT", err)
+	}
+	if _, err := service.Clone(contextFor(middleware.User{ID: "user-2"}), "app/project", "9"); !errors.Is(err, api_error.New(api_error.CodeNotFound, "", "")) {
+		t.Errorf("Clone of an unknown document = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestCloneRelations(t *testing.T) {
+	values := map[string]interface{}{Field: "public", "name": "Projeto"}
+	store := newStore(values)
+	service := NewService(store)
+	service.Register("app/project", project)
+
+	result, err := service.Clone(contextFor(middleware.User{ID: "user-2"}), "app/project", "1")
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if *result.ID != "101" {
+		t.Errorf("Clone = %s, want 101", *result.ID)
+	}
+
+	// O projeto 101 recebe as tarefas 102 e 103 e a tarefa 103 o comentário 104
+	want := map[string][]string{
+		"project": {"1 map[cloneType:public name:Projeto]", "101 map[cloneType:public name:Projeto]", "5 map[name:Outro]"},
+		"task": {
+			"102 map[projectId:101 title:Planejar]", "103 map[projectId:101 title:Executar]",
+			"2 map[projectId:1 title:Planejar]", "3 map[projectId:1 title:Executar]", "6 map[projectId:5 title:Outra]",
+		},
+		"comment": {"104 map[taskId:103 text:Revisar]", "4 map[taskId:3 text:Revisar]"},
+	}
+	for table, documents := range want {
+		var got []string
+		for _, document := range store.tables[table] {
+			got = append(got, fmt.Sprintf("%v %v", document.Key, document.Values))
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, documents) {
+			t.Errorf("%s = %v, want %v", table, got, documents)
+		}
+	}
+}
+
+func TestCloneRollback(t *testing.T) {
+	for failAt := 1; failAt <= 4; failAt++ {
+		t.Run(fmt.Sprint(failAt), func(t *testing.T) {
+			store := newStore(map[string]interface{}{Field: "public"})
+			store.failAt = failAt
+			service := NewService(store)
+			service.Register("app/project", project)
+
+			if _, err := service.Clone(contextFor(middleware.User{ID: "user-2"}), "app/project", "1"); err == nil {
+				t.Fatal("Clone succeeded, want the insert error")
+			}
+
+			// As cópias gravadas antes da falha são removidas da última para a primeira
+			var want []interface{}
+			for key := 100 + failAt - 1; key > 100; key-- {
+				want = append(want, key)
+			}
+			if !reflect.DeepEqual(store.deleted, want) {
+				t.Errorf("deleted = %v, want %v", store.deleted, want)
+			}
+			if len(store.tables["project"]) != 2 || len(store.tables["task"]) != 3 || len(store.tables["comment"]) != 1 {
+				t.Errorf("tables after the rollback = %v", store.tables)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+// Package cursor implementa a paginação por cursor (keyset) a partir de ApiPositionSearchInput.
+// Os cursores são opacos e assinados com HMAC-SHA256, codificando o valor da chave de ordenação e o identificador do documento.
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"os"
+	"strings"
+)
+
+// Limites padrão de tamanho de página.
+const (
+	DefaultSize = 20
+	MaxSize     = 100
+)
+
+const module = "api_connect/cursor"
+
+// Position é o conteúdo de um cursor: o campo de ordenação, o seu valor e o identificador do documento.
+type Position struct {
+	Field string
+	Value interface{}
+	ID    interface{}
+}
+
+// Codec codifica e valida cursores assinados.
+type Codec struct {
+	secret []byte
+}
+
+// NewCodec cria um Codec com a chave secreta informada.
+func NewCodec(secret []byte) *Codec {
+	return &Codec{secret: secret}
+}
+
+var defaultCodec = NewCodec(defaultSecret())
+
+// Default retorna o Codec padrão, cuja chave é lida da variável de ambiente API_CURSOR_SECRET.
+// Se a variável não estiver definida, é gerada uma chave aleatória e os cursores deixam de ser válidos ao reiniciar a aplicação.
+func Default() *Codec {
+	return defaultCodec
+}
+
+// SetSecret altera a chave secreta do Codec padrão.
+func SetSecret(secret []byte) {
+	defaultCodec = NewCodec(secret)
+}
+
+// Encode gera o cursor opaco da posição.
+func (c *Codec) Encode(position Position) (string, error) {
+	payload, err := bson.MarshalExtJSON(bson.D{
+		{Key: "f", Value: position.Field},
+		{Key: "v", Value: position.Value},
+		{Key: "i", Value: position.ID},
+	}, true, false)
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode valida a assinatura do cursor e retorna a posição codificada.
+// Cursores alterados ou gerados para outro campo de ordenação retornam CodeInvalidArgument.
+func (c *Codec) Decode(value string, field string) (Position, error) {
+	invalid := api_error.New(api_error.CodeInvalidArgument, module, "cursor", "cursor inválido").With("cursor", value)
+
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 {
+		return Position{}, invalid
+	}
+
+	encoding := base64.RawURLEncoding
+	payload, err := encoding.DecodeString(parts[0])
+	if err != nil {
+		return Position{}, invalid
+	}
+	signature, err := encoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, c.sign(payload)) {
+		return Position{}, invalid
+	}
+
+	var document struct {
+		Field string      `bson:"f"`
+		Value interface{} `bson:"v"`
+		ID    interface{} `bson:"i"`
+	}
+	if err = bson.UnmarshalExtJSON(payload, true, &document); err != nil {
+		return Position{}, invalid
+	}
+	if document.Field != field {
+		return Position{}, invalid
+	}
+
+	return Position{Field: document.Field, Value: document.Value, ID: document.ID}, nil
+}
+
+// sign calcula a assinatura HMAC-SHA256 do conteúdo do cursor.
+func (c *Codec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// defaultSecret lê a chave da variável de ambiente API_CURSOR_SECRET ou gera uma chave aleatória.
+func defaultSecret() []byte {
+	if secret := os.Getenv("API_CURSOR_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return secret
+}
+
+// Query define a ordenação e a posição de uma consulta paginada por cursor.
+type Query struct {
+	// Field é o campo de ordenação. Quando vazio, a ordenação é feita somente pelo identificador.
+	Field string
+
+	// Desc indica ordenação decrescente do campo.
+	Desc bool
+
+	// Position é a entrada do cliente (first/after ou last/before).
+	Position *params.ApiPositionSearchInput
+
+	// MaxSize limita o tamanho da página. Quando zero, utiliza MaxSize.
+	MaxSize int
+
+	// Codec utilizado para codificar os cursores. Quando nil, utiliza o Codec padrão.
+	Codec *Codec
+}
+
+// window é a interpretação normalizada de uma Query.
+type window struct {
+	size      int
+	direction params.CursorPositionEnum
+	after     *Position
+	codec     *Codec
+}
+
+// resolve valida a entrada first/last/after/before e determina o tamanho, a direção e a posição inicial.
+func (q Query) resolve(idField string) (window, error) {
+	maxSize := q.MaxSize
+	if maxSize <= 0 {
+		maxSize = MaxSize
+	}
+	codec := q.Codec
+	if codec == nil {
+		codec = defaultCodec
+	}
+
+	result := window{size: DefaultSize, direction: params.CursorPositionEnumNext, codec: codec}
+	if result.size > maxSize {
+		result.size = maxSize
+	}
+	input := q.Position
+	if input == nil {
+		return result, nil
+	}
+
+	if input.First != nil && input.Last != nil {
+		return window{}, api_error.New(api_error.CodeInvalidArgument, module, "position", "first e last não podem ser utilizados juntos")
+	}
+
+	var size *int
+	var value *string
+	if input.Last != nil || (input.First == nil && input.Before != nil) {
+		result.direction = params.CursorPositionEnumPrevious
+		size, value = input.Last, input.Before
+	} else {
+		size, value = input.First, input.After
+	}
+
+	if size != nil {
+		if *size < 1 || *size > maxSize {
+			return window{}, api_error.New(api_error.CodeInvalidArgument, module, "position", "tamanho deve estar entre 1 e o limite permitido").
+				With("size", *size).
+				With("max", maxSize)
+		}
+		result.size = *size
+	}
+
+	if value != nil && *value != "" {
+		position, err := codec.Decode(*value, q.fieldOr(idField))
+		if err != nil {
+			return window{}, err
+		}
+		result.after = &position
+	}
+
+	return result, nil
+}
+
+// fieldOr retorna o campo de ordenação ou o campo identificador quando não houver ordenação.
+func (q Query) fieldOr(idField string) string {
+	if q.Field == "" {
+		return idField
+	}
+	return q.Field
+}
+
+// ascending indica se a consulta ao banco deve ser feita em ordem crescente.
+// Na direção "previous" a ordem é invertida e os resultados são revertidos após a leitura.
+func (w window) ascending(desc bool) bool {
+	return desc == (w.direction == params.CursorPositionEnumPrevious)
+}
+
+// Edge é um documento lido do banco com a sua posição.
+type Edge struct {
+	Node     interface{}
+	Position Position
+}
+
+// connection monta a ApiConnection a partir dos documentos lidos (size+1 para detectar mais páginas).
+func (w window) connection(edges []Edge) (*params.ApiConnection, error) {
+	hasMore := len(edges) > w.size
+	if hasMore {
+		edges = edges[:w.size]
+	}
+	if w.direction == params.CursorPositionEnumPrevious {
+		for i, j := 0, len(edges)-1; i < j; i, j = i+1, j-1 {
+			edges[i], edges[j] = edges[j], edges[i]
+		}
+	}
+
+	result := &params.ApiConnection{
+		Edges:    make([]*params.ApiConnectionEdge, 0, len(edges)),
+		PageInfo: &params.ApiConnectionPageInfo{},
+	}
+	for _, edge := range edges {
+		value, err := w.codec.Encode(edge.Position)
+		if err != nil {
+			return nil, err
+		}
+		result.Edges = append(result.Edges, &params.ApiConnectionEdge{Cursor: value, Node: edge.Node})
+	}
+
+	if len(result.Edges) > 0 {
+		result.PageInfo.StartCursor = &result.Edges[0].Cursor
+		result.PageInfo.EndCursor = &result.Edges[len(result.Edges)-1].Cursor
+	}
+	if w.direction == params.CursorPositionEnumNext {
+		result.PageInfo.HasNextPage = hasMore
+		result.PageInfo.HasPreviousPage = w.after != nil
+	} else {
+		result.PageInfo.HasPreviousPage = hasMore
+		result.PageInfo.HasNextPage = w.after != nil
+	}
+
+	return result, nil
+}
+
+// PageInfo converte as informações de paginação por cursor para ApiPageInfo.
+func PageInfo(connection *params.ApiConnection) *params.ApiPageInfo {
+	size := len(connection.Edges)
+	nextPage := connection.PageInfo.HasNextPage
+	previousPage := connection.PageInfo.HasPreviousPage
+	return &params.ApiPageInfo{
+		Has: &params.ApiCursorSearch{
+			NextPage:     &nextPage,
+			PreviousPage: &previousPage,
+		},
+		Size: &size,
+	}
+}
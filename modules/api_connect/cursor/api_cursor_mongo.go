@@ -0,0 +1,127 @@
+package cursor
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"reflect"
+	"strings"
+)
+
+const mongoIDField = "_id"
+
+// FindMongo executa uma consulta paginada por cursor em uma coleção obtida por MongoDB.Collection.
+// Os documentos são decodificados em results, que deve ser um ponteiro para slice, na mesma ordem das edges retornadas.
+func FindMongo(ctx context.Context, collection *mongo.Collection, filter bson.D, query Query, results interface{}) (*params.ApiConnection, error) {
+	w, err := query.resolve(mongoIDField)
+	if err != nil {
+		return nil, err
+	}
+	ascending := w.ascending(query.Desc)
+
+	field := query.fieldOr(mongoIDField)
+	condition := filter
+	if w.after != nil {
+		keyset := keysetMongo(field, w.after, ascending)
+		if len(filter) == 0 {
+			condition = keyset
+		} else {
+			condition = bson.D{{Key: "$and", Value: bson.A{filter, keyset}}}
+		}
+	}
+	if condition == nil {
+		condition = bson.D{}
+	}
+
+	direction := 1
+	if !ascending {
+		direction = -1
+	}
+	sort := bson.D{{Key: field, Value: direction}}
+	if field != mongoIDField {
+		sort = append(sort, bson.E{Key: mongoIDField, Value: direction})
+	}
+
+	findOptions := options.Find().SetSort(sort).SetLimit(int64(w.size + 1))
+	mongoCursor, err := collection.Find(ctx, condition, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []bson.Raw
+	if err = mongoCursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	edges := make([]Edge, 0, len(documents))
+	for _, document := range documents {
+		// Documentos sem o campo de ordenação são codificados com o valor null, ordenado pelo MongoDB como o menor valor
+		var value interface{}
+		if lookup := document.Lookup(strings.Split(field, ".")...); lookup.Type != 0 && lookup.Type != bsontype.Null {
+			value = lookup
+		}
+		edges = append(edges, Edge{
+			Node: document,
+			Position: Position{
+				Field: field,
+				Value: value,
+				ID:    document.Lookup(mongoIDField),
+			},
+		})
+	}
+
+	connection, err := w.connection(edges)
+	if err != nil {
+		return nil, err
+	}
+	return connection, decodeNodes(connection, results)
+}
+
+// keysetMongo monta o filtro que retorna os documentos posteriores à posição do cursor na ordem da consulta.
+// Os valores null e os campos inexistentes são os primeiros na ordem crescente e os últimos na decrescente.
+func keysetMongo(field string, position *Position, ascending bool) bson.D {
+	operator := "$gt"
+	if !ascending {
+		operator = "$lt"
+	}
+	if field == mongoIDField {
+		return bson.D{{Key: mongoIDField, Value: bson.D{{Key: operator, Value: position.ID}}}}
+	}
+
+	same := bson.D{
+		{Key: field, Value: position.Value},
+		{Key: mongoIDField, Value: bson.D{{Key: operator, Value: position.ID}}},
+	}
+	if position.Value == nil {
+		if !ascending {
+			return same
+		}
+		return bson.D{{Key: "$or", Value: bson.A{same, bson.D{{Key: field, Value: bson.D{{Key: "$ne", Value: nil}}}}}}}
+	}
+
+	conditions := bson.A{bson.D{{Key: field, Value: bson.D{{Key: operator, Value: position.Value}}}}, same}
+	if !ascending {
+		conditions = append(conditions, bson.D{{Key: field, Value: nil}})
+	}
+	return bson.D{{Key: "$or", Value: conditions}}
+}
+
+// decodeNodes decodifica os documentos das edges em results e substitui os nós pelos valores decodificados.
+func decodeNodes(connection *params.ApiConnection, results interface{}) error {
+	if results == nil {
+		return nil
+	}
+	slice := reflect.ValueOf(results).Elem()
+	slice.Set(reflect.MakeSlice(slice.Type(), len(connection.Edges), len(connection.Edges)))
+	for index, edge := range connection.Edges {
+		item := slice.Index(index)
+		if err := bson.Unmarshal(edge.Node.(bson.Raw), item.Addr().Interface()); err != nil {
+			return err
+		}
+		edge.Node = item.Interface()
+	}
+	return nil
+}
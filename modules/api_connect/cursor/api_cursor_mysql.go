@@ -0,0 +1,143 @@
+package cursor
+
+import (
+	"context"
+	"database/sql"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+)
+
+// QueryMysql executa uma consulta paginada por cursor no MysqlDB.
+// A consulta não deve conter ORDER BY/LIMIT; ela é encapsulada em uma subconsulta filtrada pela posição do cursor.
+// Por isso q.Field e idColumn são os nomes das colunas no resultado da consulta, sem o nome da tabela, e as colunas
+// do resultado precisam ter nomes únicos: em consultas com JOIN, utilize aliases (SELECT t.created_at AS created_at)
+// em vez de SELECT *.
+// scan é chamado para cada linha e deve preencher Node, Position.Value (valor da coluna de ordenação, nil quando NULL)
+// e Position.ID.
+func QueryMysql(ctx context.Context, db *mysql.MysqlDB, query string, args []interface{}, idColumn string, q Query, scan func(rows *sql.Rows) (Edge, error)) (*params.ApiConnection, error) {
+	w, err := q.resolve(idColumn)
+	if err != nil {
+		return nil, err
+	}
+	field := q.fieldOr(idColumn)
+	sqlQuery, sqlArgs, err := selectMysql(query, args, field, idColumn, w, w.ascending(q.Desc))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Client().QueryContext(ctx, sqlQuery, sqlArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var edges []Edge
+	for rows.Next() {
+		edge, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		edge.Position.Field = field
+		edges = append(edges, edge)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return w.connection(edges)
+}
+
+// selectMysql encapsula a consulta na subconsulta _cursor, filtrada pela posição do cursor e ordenada pela coluna
+// de ordenação e pelo identificador, com o limite de size+1 linhas.
+func selectMysql(query string, args []interface{}, field string, idColumn string, w window, ascending bool) (string, []interface{}, error) {
+	fieldQuoted, err := quoteColumn(field, "field")
+	if err != nil {
+		return "", nil, err
+	}
+	idQuoted, err := quoteColumn(idColumn, "idColumn")
+	if err != nil {
+		return "", nil, err
+	}
+
+	direction := " ASC"
+	if !ascending {
+		direction = " DESC"
+	}
+
+	sqlQuery := "SELECT * FROM (" + query + ") AS _cursor"
+	sqlArgs := append([]interface{}{}, args...)
+	if w.after != nil {
+		where, whereArgs := keysetMysql(fieldQuoted, idQuoted, field == idColumn, w.after, ascending)
+		sqlQuery += " WHERE " + where
+		sqlArgs = append(sqlArgs, whereArgs...)
+	}
+
+	sqlQuery += " ORDER BY " + fieldQuoted + direction
+	if field != idColumn {
+		sqlQuery += ", " + idQuoted + direction
+	}
+	sqlQuery += " LIMIT ?"
+	sqlArgs = append(sqlArgs, w.size+1)
+	return sqlQuery, sqlArgs, nil
+}
+
+// quoteColumn escapa o nome de uma coluna do resultado da consulta. Nomes qualificados (tabela.coluna) retornam
+// CodeInvalidArgument, pois fora da subconsulta _cursor a coluna é referenciada somente pelo seu nome.
+func quoteColumn(name string, path string) (string, error) {
+	if strings.Contains(name, ".") {
+		return "", api_error.New(api_error.CodeInvalidArgument, module, path, "a coluna de ordenação não pode ser qualificada pela tabela; utilize o nome ou alias da coluna no resultado da consulta").
+			With("column", name)
+	}
+	quoted, err := mysql.QuoteIdentifier(name)
+	if err != nil {
+		return "", api_error.New(api_error.CodeInvalidArgument, module, path, "nome de coluna inválido").
+			With("column", name)
+	}
+	return quoted, nil
+}
+
+// keysetMysql monta a condição que retorna as linhas posteriores à posição do cursor na ordem da consulta.
+// Os valores NULL são os primeiros na ordem crescente e os últimos na decrescente, como no ORDER BY do MySQL.
+func keysetMysql(fieldQuoted, idQuoted string, byID bool, position *Position, ascending bool) (string, []interface{}) {
+	operator := " > ?"
+	if !ascending {
+		operator = " < ?"
+	}
+	id := sqlValue(position.ID)
+	if byID {
+		return idQuoted + operator, []interface{}{id}
+	}
+
+	if position.Value == nil {
+		same := "(" + fieldQuoted + " IS NULL AND " + idQuoted + operator + ")"
+		if !ascending {
+			return same, []interface{}{id}
+		}
+		return "(" + same + " OR " + fieldQuoted + " IS NOT NULL)", []interface{}{id}
+	}
+
+	value := sqlValue(position.Value)
+	where := "(" + fieldQuoted + operator + " OR (" + fieldQuoted + " = ? AND " + idQuoted + operator + ")"
+	if !ascending {
+		where += " OR " + fieldQuoted + " IS NULL"
+	}
+	return where + ")", []interface{}{value, value, id}
+}
+
+// sqlValue converte os tipos BSON decodificados do cursor para tipos aceitos pelo driver MySQL.
+func sqlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.DateTime:
+		return v.Time()
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.Decimal128:
+		return v.String()
+	}
+	return value
+}
@@ -0,0 +1,198 @@
+package cursor
+
+import (
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodec(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	id := primitive.NewObjectID()
+	date := primitive.NewDateTimeFromTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name     string
+		position Position
+		value    interface{}
+	}{
+		{"string", Position{Field: "name", Value: "Ana", ID: id}, "Ana"},
+		{"date", Position{Field: "_info.createdAt", Value: date, ID: id}, date},
+		{"missing sort key", Position{Field: "name", Value: nil, ID: id}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := codec.Encode(test.position)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			position, err := codec.Decode(value, test.position.Field)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !reflect.DeepEqual(position.Value, test.value) || position.ID != id {
+				t.Errorf("Decode = %#v, want value %#v and id %s", position, test.value, id.Hex())
+			}
+		})
+	}
+}
+
+func TestCodecRejectsInvalidCursors(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	value, err := codec.Encode(Position{Field: "name", Value: "Ana", ID: "1"})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	other, _ := NewCodec([]byte("other")).Encode(Position{Field: "name", Value: "Ana", ID: "1"})
+	payload := strings.Split(value, ".")
+
+	tests := []struct {
+		name   string
+		cursor string
+		field  string
+	}{
+		{"another field", value, "email"},
+		{"another secret", other, "name"},
+		{"tampered payload", payload[0] + "A." + payload[1], "name"},
+		{"without signature", payload[0], "name"},
+		{"not base64", "!." + payload[1], "name"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := codec.Decode(test.cursor, test.field); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+				t.Errorf("Decode = %v, want CodeInvalidArgument", err)
+			}
+		})
+	}
+}
+
+func TestKeysetMongo(t *testing.T) {
+	tests := []struct {
+		name      string
+		field     string
+		value     interface{}
+		ascending bool
+		want      bson.D
+	}{
+		{
+			name: "identifier", field: "_id", ascending: true,
+			want: bson.D{{Key: "_id", Value: bson.D{{Key: "$gt", Value: "1"}}}},
+		},
+		{
+			name: "value ascending", field: "name", value: "Ana", ascending: true,
+			want: bson.D{{Key: "$or", Value: bson.A{
+				bson.D{{Key: "name", Value: bson.D{{Key: "$gt", Value: "Ana"}}}},
+				bson.D{{Key: "name", Value: "Ana"}, {Key: "_id", Value: bson.D{{Key: "$gt", Value: "1"}}}},
+			}}},
+		},
+		{
+			name: "value descending includes nulls", field: "name", value: "Ana", ascending: false,
+			want: bson.D{{Key: "$or", Value: bson.A{
+				bson.D{{Key: "name", Value: bson.D{{Key: "$lt", Value: "Ana"}}}},
+				bson.D{{Key: "name", Value: "Ana"}, {Key: "_id", Value: bson.D{{Key: "$lt", Value: "1"}}}},
+				bson.D{{Key: "name", Value: nil}},
+			}}},
+		},
+		{
+			name: "null ascending", field: "name", ascending: true,
+			want: bson.D{{Key: "$or", Value: bson.A{
+				bson.D{{Key: "name", Value: nil}, {Key: "_id", Value: bson.D{{Key: "$gt", Value: "1"}}}},
+				bson.D{{Key: "name", Value: bson.D{{Key: "$ne", Value: nil}}}},
+			}}},
+		},
+		{
+			name: "null descending", field: "name", ascending: false,
+			want: bson.D{{Key: "name", Value: nil}, {Key: "_id", Value: bson.D{{Key: "$lt", Value: "1"}}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := keysetMongo(test.field, &Position{Field: test.field, Value: test.value, ID: "1"}, test.ascending)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("keysetMongo = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestKeysetMysql(t *testing.T) {
+	tests := []struct {
+		name      string
+		byID      bool
+		value     interface{}
+		ascending bool
+		where     string
+		args      []interface{}
+	}{
+		{"identifier", true, nil, false, "`id` < ?", []interface{}{"1"}},
+		{"value ascending", false, "Ana", true, "(`name` > ? OR (`name` = ? AND `id` > ?))", []interface{}{"Ana", "Ana", "1"}},
+		{"value descending", false, "Ana", false, "(`name` < ? OR (`name` = ? AND `id` < ?) OR `name` IS NULL)", []interface{}{"Ana", "Ana", "1"}},
+		{"null ascending", false, nil, true, "((`name` IS NULL AND `id` > ?) OR `name` IS NOT NULL)", []interface{}{"1"}},
+		{"null descending", false, nil, false, "(`name` IS NULL AND `id` < ?)", []interface{}{"1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			where, args := keysetMysql("`name`", "`id`", test.byID, &Position{Value: test.value, ID: "1"}, test.ascending)
+			if where != test.where || !reflect.DeepEqual(args, test.args) {
+				t.Errorf("keysetMysql = %s %v, want %s %v", where, args, test.where, test.args)
+			}
+		})
+	}
+}
+
+func TestSelectMysql(t *testing.T) {
+	after := &Position{Field: "created_at", Value: "2024-05-01", ID: 7}
+	tests := []struct {
+		name      string
+		field     string
+		after     *Position
+		ascending bool
+		query     string
+		args      []interface{}
+		path      string
+	}{
+		{
+			name:      "first page by identifier",
+			field:     "id",
+			ascending: true,
+			query:     "SELECT * FROM (SELECT id, name FROM product WHERE status = ?) AS _cursor ORDER BY `id` ASC LIMIT ?",
+			args:      []interface{}{"ENABLED", 3},
+		},
+		{
+			name:  "after a position",
+			field: "created_at",
+			after: after,
+			query: "SELECT * FROM (SELECT id, name FROM product WHERE status = ?) AS _cursor" +
+				" WHERE (`created_at` < ? OR (`created_at` = ? AND `id` < ?) OR `created_at` IS NULL)" +
+				" ORDER BY `created_at` DESC, `id` DESC LIMIT ?",
+			args: []interface{}{"ENABLED", "2024-05-01", "2024-05-01", 7, 3},
+		},
+		{name: "qualified field", field: "p.created_at", path: "field"},
+		{name: "invalid field", field: "created_at; DROP TABLE product", path: "field"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := window{size: 2, after: test.after}
+			query, args, err := selectMysql("SELECT id, name FROM product WHERE status = ?", []interface{}{"ENABLED"}, test.field, "id", w, test.ascending)
+			if test.path != "" {
+				var apiError *api_error.ApiError
+				if !errors.As(err, &apiError) || apiError.Code != api_error.CodeInvalidArgument || apiError.Path != test.path {
+					t.Errorf("selectMysql = %v, want INVALID_ARGUMENT at %s", err, test.path)
+				}
+				return
+			}
+			if err != nil || query != test.query || !reflect.DeepEqual(args, test.args) {
+				t.Errorf("selectMysql = %s %v, %v, want %s %v", query, args, err, test.query, test.args)
+			}
+		})
+	}
+
+	if _, _, err := selectMysql("SELECT 1", nil, "id", "p.id", window{size: 1}, true); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+		t.Errorf("selectMysql with a qualified idColumn = %v, want INVALID_ARGUMENT", err)
+	}
+}
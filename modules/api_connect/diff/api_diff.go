@@ -0,0 +1,188 @@
+// Package diff calcula os campos alterados entre o documento armazenado e o novo documento,
+// no formato JSON Patch (RFC 6902) e como mapas de valores anteriores e posteriores,
+// e constrói a alteração inversa (desfazer).
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Operações JSON Patch utilizadas.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// pointerEscape e pointerUnescape convertem os nomes de campos para o formato JSON Pointer (RFC 6901).
+var (
+	pointerEscape   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescape = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// Compute retorna os campos alterados entre before e after. Documentos aninhados são comparados campo a campo;
+// listas e demais valores são comparados pela representação JSON, de forma que tipos equivalentes do MongoDB
+// e do MySQL (datas, números) tenham o mesmo valor, e substituídos por inteiro.
+// Os valores anteriores e posteriores mantêm os tipos originais, permitindo restaurá-los em Undo.
+func Compute(before map[string]interface{}, after map[string]interface{}) *params.ApiChangeDiff {
+	result := &params.ApiChangeDiff{
+		Patch:  []*params.ApiPatchOperation{},
+		Before: map[string]interface{}{},
+		After:  map[string]interface{}{},
+	}
+	walk(nil, before, after, result)
+	return result
+}
+
+// IsEmpty indica se não há campos alterados.
+func IsEmpty(changes *params.ApiChangeDiff) bool {
+	return changes == nil || len(changes.Patch) == 0
+}
+
+// Paths retorna os caminhos com pontos dos campos alterados.
+func Paths(changes *params.ApiChangeDiff) []string {
+	if changes == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(changes.Patch))
+	for _, operation := range changes.Patch {
+		paths = append(paths, DottedPath(operation.Path))
+	}
+	return paths
+}
+
+// Reverse retorna o JSON Patch que desfaz as alterações.
+func Reverse(changes *params.ApiChangeDiff) []*params.ApiPatchOperation {
+	if changes == nil {
+		return nil
+	}
+	result := make([]*params.ApiPatchOperation, 0, len(changes.Patch))
+	for index := len(changes.Patch) - 1; index >= 0; index-- {
+		operation := changes.Patch[index]
+		before := changes.Before[DottedPath(operation.Path)]
+		switch operation.Op {
+		case OpAdd:
+			result = append(result, &params.ApiPatchOperation{Op: OpRemove, Path: operation.Path})
+		case OpRemove:
+			result = append(result, &params.ApiPatchOperation{Op: OpAdd, Path: operation.Path, Value: before})
+		case OpReplace:
+			result = append(result, &params.ApiPatchOperation{Op: OpReplace, Path: operation.Path, Value: before})
+		}
+	}
+	return result
+}
+
+// Undo retorna os valores que desfazem as alterações: os campos a restaurar, indexados pelo caminho com pontos,
+// e os campos adicionados que devem ser removidos.
+func Undo(changes *params.ApiChangeDiff) (set map[string]interface{}, unset []string) {
+	set = map[string]interface{}{}
+	for _, operation := range Reverse(changes) {
+		path := DottedPath(operation.Path)
+		if operation.Op == OpRemove {
+			unset = append(unset, path)
+		} else {
+			set[path] = operation.Value
+		}
+	}
+	return set, unset
+}
+
+// DottedPath converte um caminho JSON Pointer (/address/city) para o caminho com pontos (address.city).
+func DottedPath(pointer string) string {
+	parts := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for index, part := range parts {
+		parts[index] = pointerUnescape.Replace(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// walk compara recursivamente os campos dos documentos.
+func walk(path []string, before map[string]interface{}, after map[string]interface{}, result *params.ApiChangeDiff) {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		current := append(append([]string{}, path...), key)
+		beforeValue, hasBefore := before[key]
+		afterValue, hasAfter := after[key]
+
+		switch {
+		case !hasBefore:
+			add(result, OpAdd, current, nil, afterValue)
+		case !hasAfter:
+			add(result, OpRemove, current, beforeValue, nil)
+		default:
+			beforeMap, beforeIsMap := asMap(beforeValue)
+			afterMap, afterIsMap := asMap(afterValue)
+			if beforeIsMap && afterIsMap {
+				walk(current, beforeMap, afterMap, result)
+			} else if !equal(beforeValue, afterValue) {
+				add(result, OpReplace, current, beforeValue, afterValue)
+			}
+		}
+	}
+}
+
+// add adiciona a operação e os valores anteriores e posteriores do campo.
+func add(result *params.ApiChangeDiff, op string, path []string, before interface{}, after interface{}) {
+	pointer := make([]string, len(path))
+	for index, part := range path {
+		pointer[index] = pointerEscape.Replace(part)
+	}
+
+	operation := &params.ApiPatchOperation{Op: op, Path: "/" + strings.Join(pointer, "/")}
+	if op != OpRemove {
+		operation.Value = after
+	}
+	result.Patch = append(result.Patch, operation)
+
+	dotted := strings.Join(path, ".")
+	result.Before[dotted] = before
+	result.After[dotted] = after
+}
+
+// asMap converte documentos aninhados (bson.M, bson.D ou map[string]interface{}) para mapa.
+func asMap(value interface{}) (map[string]interface{}, bool) {
+	switch item := value.(type) {
+	case map[string]interface{}:
+		return item, true
+	case bson.M:
+		return item, true
+	case bson.D:
+		result := make(map[string]interface{}, len(item))
+		for _, element := range item {
+			result[element.Key] = element.Value
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// equal compara os valores pela representação JSON.
+func equal(a interface{}, b interface{}) bool {
+	first, errFirst := json.Marshal(a)
+	second, errSecond := json.Marshal(b)
+	if errFirst != nil || errSecond != nil {
+		return reflect.DeepEqual(a, b)
+	}
+
+	var left, right interface{}
+	if json.Unmarshal(first, &left) != nil || json.Unmarshal(second, &right) != nil {
+		return bytes.Equal(first, second)
+	}
+	return reflect.DeepEqual(left, right)
+}
@@ -0,0 +1,111 @@
+package diff
+
+import (
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompute(t *testing.T) {
+	date := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	before := map[string]interface{}{
+		"name":    "Ana",
+		"age":     int32(30),
+		"tags":    []interface{}{"a", "b"},
+		"phone":   "5555",
+		"address": bson.D{{Key: "city", Value: "Recife"}, {Key: "zip", Value: "50000"}},
+		"a/b":     1,
+		"date":    date,
+	}
+	after := map[string]interface{}{
+		"name":    "Ana",
+		"age":     int64(31),
+		"tags":    []string{"a", "b"},
+		"email":   "ana@example.com",
+		"address": bson.M{"city": "Olinda", "zip": "50000"},
+		"a/b":     2,
+		"date":    date.Format(time.RFC3339Nano),
+	}
+
+	changes := Compute(before, after)
+	want := []*params.ApiPatchOperation{
+		{Op: OpReplace, Path: "/a~1b", Value: 2},
+		{Op: OpReplace, Path: "/address/city", Value: "Olinda"},
+		{Op: OpReplace, Path: "/age", Value: int64(31)},
+		{Op: OpAdd, Path: "/email", Value: "ana@example.com"},
+		{Op: OpRemove, Path: "/phone"},
+	}
+	if !reflect.DeepEqual(changes.Patch, want) {
+		for _, operation := range changes.Patch {
+			t.Logf("%+v", *operation)
+		}
+		t.Fatalf("Patch differs from the expected operations")
+	}
+
+	if got := Paths(changes); !reflect.DeepEqual(got, []string{"a/b", "address.city", "age", "email", "phone"}) {
+		t.Errorf("Paths = %q", got)
+	}
+	if changes.Before["address.city"] != "Recife" || changes.After["address.city"] != "Olinda" {
+		t.Errorf("address.city = %v -> %v, want Recife -> Olinda", changes.Before["address.city"], changes.After["address.city"])
+	}
+	if changes.Before["age"] != int32(30) {
+		t.Errorf("Before age = %#v, want the original type", changes.Before["age"])
+	}
+	if changes.Before["email"] != nil || changes.After["phone"] != nil {
+		t.Errorf("Before email = %v, After phone = %v, want nil", changes.Before["email"], changes.After["phone"])
+	}
+}
+
+func TestComputeEqual(t *testing.T) {
+	document := map[string]interface{}{"name": "Ana", "address": map[string]interface{}{"city": "Recife"}}
+	changes := Compute(document, map[string]interface{}{"name": "Ana", "address": bson.D{{Key: "city", Value: "Recife"}}})
+	if !IsEmpty(changes) {
+		t.Errorf("Compute = %v, want no changes", changes.Patch)
+	}
+	if !IsEmpty(nil) {
+		t.Error("IsEmpty(nil) = false")
+	}
+}
+
+func TestReverseAndUndo(t *testing.T) {
+	changes := Compute(
+		map[string]interface{}{"name": "Ana", "phone": "5555", "address": map[string]interface{}{"city": "Recife"}},
+		map[string]interface{}{"name": "Bia", "email": "bia@example.com", "address": map[string]interface{}{"city": "Olinda"}},
+	)
+
+	want := []*params.ApiPatchOperation{
+		{Op: OpAdd, Path: "/phone", Value: "5555"},
+		{Op: OpReplace, Path: "/name", Value: "Ana"},
+		{Op: OpRemove, Path: "/email"},
+		{Op: OpReplace, Path: "/address/city", Value: "Recife"},
+	}
+	if got := Reverse(changes); !reflect.DeepEqual(got, want) {
+		for _, operation := range got {
+			t.Logf("%+v", *operation)
+		}
+		t.Errorf("Reverse differs from the expected operations")
+	}
+
+	set, unset := Undo(changes)
+	if !reflect.DeepEqual(set, map[string]interface{}{"phone": "5555", "name": "Ana", "address.city": "Recife"}) {
+		t.Errorf("Undo set = %v", set)
+	}
+	if !reflect.DeepEqual(unset, []string{"email"}) {
+		t.Errorf("Undo unset = %v, want [email]", unset)
+	}
+}
+
+func TestDottedPath(t *testing.T) {
+	tests := map[string]string{
+		"/name":         "name",
+		"/address/city": "address.city",
+		"/a~1b/c~0d":    "a/b.c~d",
+	}
+	for pointer, want := range tests {
+		if got := DottedPath(pointer); got != want {
+			t.Errorf("DottedPath(%q) = %q, want %q", pointer, got, want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package api_error fornece erros tipados da ApiConnect, que podem ser encadeados (wrap) e convertidos
+// para o tipo GraphQL ApiErrorType definido em modules/api_connect/error/schemas/type.graphqls.
+//
+// Para que o gqlgen utilize os tipos deste pacote, configure no gqlgen.yml:
+//
+//	models:
+//	  ApiErrorType:
+//	    model: github.com/coocree/coocree_apiconnect_go/modules/api_connect/error.ApiErrorType
+package api_error
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Códigos de erro padrão utilizados pelos pacotes da ApiConnect.
+const (
+	CodeInternal           = "INTERNAL"
+	CodeInvalidArgument    = "INVALID_ARGUMENT"
+	CodeNotFound           = "NOT_FOUND"
+	CodeAlreadyExists      = "ALREADY_EXISTS"
+	CodeConflict           = "CONFLICT"
+	CodeFailedPrecondition = "FAILED_PRECONDITION"
+	CodeUnauthenticated    = "UNAUTHENTICATED"
+	CodePermissionDenied   = "PERMISSION_DENIED"
+)
+
+// Codes são todos os códigos de erro padrão, utilizados por exemplo para verificar as traduções do catálogo i18n.
+var Codes = []string{
+	CodeInternal,
+	CodeInvalidArgument,
+	CodeNotFound,
+	CodeAlreadyExists,
+	CodeConflict,
+	CodeFailedPrecondition,
+	CodeUnauthenticated,
+	CodePermissionDenied,
+}
+
+// ApiErrorType é a representação Go do tipo GraphQL ApiErrorType.
+type ApiErrorType struct {
+	CreatedAt string                 `json:"createdAt"`
+	Message   []string               `json:"message"`
+	Module    string                 `json:"module"`
+	Path      string                 `json:"path"`
+	Type      *string                `json:"type"`
+	Variable  map[string]interface{} `json:"variable"`
+}
+
+// ApiError é um erro tipado que carrega código, módulo, caminho e variáveis do erro.
+type ApiError struct {
+	Code      string
+	Module    string
+	Path      string
+	Message   []string
+	Variable  map[string]interface{}
+	CreatedAt time.Time
+	err       error
+}
+
+// New cria um novo ApiError com o código, módulo e caminho informados.
+// Caso nenhuma mensagem seja informada, o código é utilizado como mensagem.
+func New(code, module, path string, message ...string) *ApiError {
+	return &ApiError{
+		Code:      code,
+		Module:    module,
+		Path:      path,
+		Message:   message,
+		Variable:  map[string]interface{}{},
+		CreatedAt: time.Now(),
+	}
+}
+
+// Wrap encapsula um erro existente em um ApiError.
+// Se err já for um ApiError, retorna uma cópia com os campos vazios completados com os valores informados;
+// as mensagens e variáveis são copiadas, de modo que With na cópia não altera o erro original.
+func Wrap(err error, code, module, path string) *ApiError {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		result := *apiErr
+		result.Message = append([]string(nil), apiErr.Message...)
+		result.Variable = make(map[string]interface{}, len(apiErr.Variable))
+		for key, value := range apiErr.Variable {
+			result.Variable[key] = value
+		}
+		if result.Code == "" {
+			result.Code = code
+		}
+		if result.Module == "" {
+			result.Module = module
+		}
+		if result.Path == "" {
+			result.Path = path
+		}
+		return &result
+	}
+
+	result := New(code, module, path, err.Error())
+	result.err = err
+	return result
+}
+
+// With adiciona uma variável ao erro e retorna o próprio erro para encadeamento.
+func (e *ApiError) With(key string, value interface{}) *ApiError {
+	if e.Variable == nil {
+		e.Variable = map[string]interface{}{}
+	}
+	e.Variable[key] = value
+	return e
+}
+
+// Error implementa a interface error.
+func (e *ApiError) Error() string {
+	message := strings.Join(e.Message, "; ")
+	if message == "" {
+		message = e.Code
+	}
+	if e.Module != "" || e.Path != "" {
+		return fmt.Sprintf("%s: %s [%s/%s]", e.Code, message, e.Module, e.Path)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, message)
+}
+
+// Unwrap retorna o erro original encapsulado, se houver.
+func (e *ApiError) Unwrap() error {
+	return e.err
+}
+
+// Is considera iguais dois ApiError com o mesmo código, permitindo o uso de errors.Is.
+func (e *ApiError) Is(target error) bool {
+	t, ok := target.(*ApiError)
+	if !ok {
+		return false
+	}
+	return t.Code != "" && t.Code == e.Code
+}
+
+// Extensions retorna as extensões do erro GraphQL.
+// O gqlgen utiliza este método automaticamente ao converter o erro para gqlerror.Error.
+func (e *ApiError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":      e.Code,
+		"module":    e.Module,
+		"path":      e.Path,
+		"variable":  e.Variable,
+		"createdAt": e.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ToType converte o erro para o tipo GraphQL ApiErrorType.
+func (e *ApiError) ToType() *ApiErrorType {
+	message := e.Message
+	if len(message) == 0 {
+		message = []string{e.Code}
+	}
+	code := e.Code
+	return &ApiErrorType{
+		CreatedAt: e.CreatedAt.Format(time.RFC3339),
+		Message:   message,
+		Module:    e.Module,
+		Path:      e.Path,
+		Type:      &code,
+		Variable:  e.Variable,
+	}
+}
+
+// List é uma lista de erros retornada quando uma ação produz mais de um erro, por exemplo em validações.
+type List []*ApiError
+
+// Error implementa a interface error. Os itens nil são ignorados.
+func (l List) Error() string {
+	messages := make([]string, 0, len(l))
+	for _, item := range l {
+		if item != nil {
+			messages = append(messages, item.Error())
+		}
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Err retorna nil se a lista estiver vazia, evitando o retorno de uma interface error não nula.
+func (l List) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// From converte qualquer erro em um ApiError, utilizando CodeInternal quando o erro não for tipado.
+func From(err error, module, path string) *ApiError {
+	return Wrap(err, CodeInternal, module, path)
+}
+
+// ToType converte qualquer erro no tipo GraphQL ApiErrorType, utilizando o primeiro item não nil de uma List.
+// Retorna nil se err for nil.
+// Utilizado pelo gerador de código para preencher o campo error de um response.
+func ToType(err error, module, path string) *ApiErrorType {
+	if err == nil {
+		return nil
+	}
+	var list List
+	if errors.As(err, &list) {
+		for _, item := range list {
+			if item != nil {
+				return Wrap(item, CodeInternal, module, path).ToType()
+			}
+		}
+	}
+	return From(err, module, path).ToType()
+}
+
+// ToTypes converte qualquer erro em uma lista de ApiErrorType, ignorando os itens nil de uma List.
+// Retorna nil se err for nil.
+// Utilizado pelo gerador de código para preencher o campo errors de um response.
+func ToTypes(err error, module, path string) []*ApiErrorType {
+	if err == nil {
+		return nil
+	}
+	var list List
+	if errors.As(err, &list) {
+		result := make([]*ApiErrorType, 0, len(list))
+		for _, item := range list {
+			if item != nil {
+				result = append(result, Wrap(item, CodeInternal, module, path).ToType())
+			}
+		}
+		if len(result) > 0 {
+			return result
+		}
+	}
+	return []*ApiErrorType{From(err, module, path).ToType()}
+}
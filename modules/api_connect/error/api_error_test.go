@@ -0,0 +1,54 @@
+package api_error
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWrapCopiesVariables(t *testing.T) {
+	original := New(CodeNotFound, "app/account", "read", "conta não encontrada").With("id", "1")
+	wrapped := Wrap(original, CodeInternal, "app/user", "update").With("id", "2").With("user", "3")
+	wrapped.Message[0] = "alterada"
+
+	if original.Variable["id"] != "1" || len(original.Variable) != 1 {
+		t.Errorf("original.Variable = %v, want map[id:1]", original.Variable)
+	}
+	if original.Message[0] != "conta não encontrada" {
+		t.Errorf("original.Message = %q", original.Message)
+	}
+	if wrapped.Code != CodeNotFound || wrapped.Module != "app/account" {
+		t.Errorf("wrapped = %s %s, want the original code and module", wrapped.Code, wrapped.Module)
+	}
+}
+
+func TestToTypeSkipsNilItems(t *testing.T) {
+	list := List{nil, New(CodeInvalidArgument, "app/account", "name", "nome obrigatório"), nil}
+
+	item := ToType(list.Err(), "app/account", "create")
+	if item == nil || *item.Type != CodeInvalidArgument {
+		t.Errorf("ToType = %v, want INVALID_ARGUMENT", item)
+	}
+
+	items := ToTypes(list.Err(), "app/account", "create")
+	if len(items) != 1 || *items[0].Type != CodeInvalidArgument {
+		t.Errorf("ToTypes = %v, want one INVALID_ARGUMENT", items)
+	}
+
+	empty := List{nil}
+	if item = ToType(empty, "app/account", "create"); item == nil || *item.Type != CodeInternal {
+		t.Errorf("ToType(nil items) = %v, want INTERNAL", item)
+	}
+	if items = ToTypes(empty, "app/account", "create"); len(items) != 1 || *items[0].Type != CodeInternal {
+		t.Errorf("ToTypes(nil items) = %v, want one INTERNAL", items)
+	}
+}
+
+func TestToTypes(t *testing.T) {
+	if ToTypes(nil, "app/account", "create") != nil {
+		t.Error("ToTypes(nil) != nil")
+	}
+	items := ToTypes(errors.New("connection refused"), "app/account", "create")
+	if len(items) != 1 || *items[0].Type != CodeInternal || items[0].Message[0] != "connection refused" {
+		t.Errorf("ToTypes = %v, want one INTERNAL with the error message", items)
+	}
+}
@@ -0,0 +1,150 @@
+// Package exist verifica se um valor já está em uso em um campo (email, slug, ...) e produz o ApiExistResponse.
+// Os módulos registram a coleção/tabela e os campos permitidos com as regras de comparação de cada campo.
+package exist
+
+import (
+	"context"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/i18n"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const module = "api_connect/exist"
+
+// Field define um campo verificável e as suas regras de comparação.
+type Field struct {
+	// Name é o nome do campo (MongoDB) ou coluna (MySQL).
+	Name string
+
+	// CaseInsensitive indica que a comparação ignora maiúsculas e minúsculas.
+	CaseInsensitive bool
+
+	// Normalize é aplicado ao valor antes da comparação, por exemplo NormalizeEmail.
+	Normalize func(value string) string
+
+	// Collation é a collation MySQL utilizada na comparação, por exemplo utf8mb4_0900_as_ci.
+	// Quando vazia, a comparação utiliza a collation da coluna, que aproveita o índice da coluna.
+	Collation string
+}
+
+// Target é a coleção/tabela de um módulo e os campos verificáveis, indexados pelo nome exposto ao cliente.
+type Target struct {
+	Name   string
+	Fields map[string]Field
+}
+
+// Store executa a verificação em um banco de dados.
+type Store interface {
+	Exists(ctx context.Context, target Target, field Field, value string) (bool, error)
+}
+
+// ApiExistFilter é a representação Go do input GraphQL ApiExistFilter.
+type ApiExistFilter struct {
+	Module string `json:"module"`
+	Field  string `json:"field"`
+	Value  string `json:"value"`
+}
+
+// Service verifica a existência de valores nos módulos registrados.
+type Service struct {
+	store   Store
+	mu      sync.RWMutex
+	targets map[string]Target
+}
+
+// NewService cria um Service com o Store informado (NewMongoStore ou NewMysqlStore).
+func NewService(store Store) *Service {
+	return &Service{store: store, targets: map[string]Target{}}
+}
+
+// Register registra a coleção/tabela e os campos verificáveis de um módulo.
+func (s *Service) Register(moduleName string, target Target) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.targets[moduleName] = target
+}
+
+// Exists verifica se o valor já existe no campo do módulo.
+func (s *Service) Exists(ctx context.Context, moduleName string, fieldName string, value string) (*params.ApiExistResult, error) {
+	s.mu.RLock()
+	target, ok := s.targets[moduleName]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "module", "módulo não registrado").
+			With("module", moduleName)
+	}
+
+	field, ok := target.Fields[fieldName]
+	if !ok {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "field", "campo não permitido").
+			With("module", moduleName).
+			With("field", fieldName)
+	}
+
+	normalized := value
+	if field.Normalize != nil {
+		normalized = field.Normalize(normalized)
+	}
+	if field.CaseInsensitive {
+		normalized = strings.ToLower(normalized)
+	}
+
+	exists, err := s.store.Exists(ctx, target, field, normalized)
+	if err != nil {
+		return nil, err
+	}
+	return &params.ApiExistResult{Field: &fieldName, Exists: &exists, Value: &normalized}, nil
+}
+
+// ApiExistQuery implementa a query apiExist, retornando o envelope ApiExistResponse.
+// As verificações com falha são retornadas no campo error como ApiErrorType.
+func (s *Service) ApiExistQuery(ctx context.Context, filter ApiExistFilter) (*params.ApiExistResponse, error) {
+	_timeStart := time.Now()
+	_success := true
+	var _error *api_error.ApiErrorType
+
+	_result, err := s.Exists(ctx, filter.Module, filter.Field, filter.Value)
+	if err != nil {
+		_success = false
+		_error = api_error.ToType(i18n.Localize(ctx, err), module, "apiExist")
+	}
+
+	_response := params.ApiExistResponse{
+		Result:      _result,
+		Error:       _error,
+		Success:     _success,
+		ElapsedTime: time.Since(_timeStart).String(),
+	}
+	return &_response, nil
+}
+
+// NormalizeTrim remove os espaços do início e do fim do valor.
+func NormalizeTrim(value string) string {
+	return strings.TrimSpace(value)
+}
+
+// NormalizeEmail remove os espaços e converte o email para minúsculas.
+func NormalizeEmail(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+var regexSlugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+var slugAccents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// NormalizeSlug converte o valor para um slug: minúsculas, sem acentos e palavras separadas por hífen.
+func NormalizeSlug(value string) string {
+	value = slugAccents.Replace(strings.ToLower(strings.TrimSpace(value)))
+	return strings.Trim(regexSlugInvalid.ReplaceAllString(value, "-"), "-")
+}
@@ -0,0 +1,34 @@
+package exist
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore verifica a existência de valores em coleções do MongoDB.
+type MongoStore struct {
+	db       *mongo.MongoDB
+	database string
+}
+
+// NewMongoStore cria um Store para o banco de dados MongoDB informado.
+func NewMongoStore(db *mongo.MongoDB, database string) *MongoStore {
+	return &MongoStore{db: db, database: database}
+}
+
+// Exists verifica se algum documento possui o valor no campo.
+// Comparações sem diferenciar maiúsculas utilizam collation de força 2, que aproveita índices com a mesma collation.
+func (s *MongoStore) Exists(ctx context.Context, target Target, field Field, value string) (bool, error) {
+	countOptions := options.Count().SetLimit(1)
+	if field.CaseInsensitive {
+		countOptions.SetCollation(&options.Collation{Locale: "pt", Strength: 2})
+	}
+
+	count, err := s.db.Collection(s.database, target.Name).CountDocuments(ctx, bson.D{{Key: field.Name, Value: value}}, countOptions)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
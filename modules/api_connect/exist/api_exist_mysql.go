@@ -0,0 +1,63 @@
+package exist
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"regexp"
+)
+
+// regexCollation valida os nomes de collation informados em Field.Collation.
+var regexCollation = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// MysqlStore verifica a existência de valores em tabelas do MySQL.
+type MysqlStore struct {
+	db *mysql.MysqlDB
+}
+
+// NewMysqlStore cria um Store para o MysqlDB informado.
+func NewMysqlStore(db *mysql.MysqlDB) *MysqlStore {
+	return &MysqlStore{db: db}
+}
+
+// Exists verifica se alguma linha possui o valor na coluna.
+func (s *MysqlStore) Exists(ctx context.Context, target Target, field Field, value string) (bool, error) {
+	query, err := existsQuery(target, field)
+	if err != nil {
+		return false, err
+	}
+
+	var found int
+	err = s.db.Client().QueryRowContext(ctx, query, value).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// existsQuery monta a consulta de Exists comparando a coluna diretamente com o valor.
+// A comparação segue a collation da coluna, que deve corresponder às regras do campo: os campos CaseInsensitive
+// precisam de uma collation _ci sensível a acentos (utf8mb4_0900_as_ci), equivalente à collation de força 2 do
+// MongoDB, e os demais de uma collation _cs ou _bin. Field.Collation força outra collation com COLLATE, sem o
+// índice da coluna.
+func existsQuery(target Target, field Field) (string, error) {
+	table, err := mysql.QuoteIdentifier(target.Name)
+	if err != nil {
+		return "", err
+	}
+	column, err := mysql.QuoteIdentifier(field.Name)
+	if err != nil {
+		return "", err
+	}
+
+	condition := column + " = ?"
+	if field.Collation != "" {
+		if !regexCollation.MatchString(field.Collation) {
+			return "", fmt.Errorf("collation MySQL inválida: %q", field.Collation)
+		}
+		condition = column + " = ? COLLATE " + field.Collation
+	}
+	return "SELECT 1 FROM " + table + " WHERE " + condition + " LIMIT 1", nil
+}
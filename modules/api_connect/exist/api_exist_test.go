@@ -0,0 +1,147 @@
+package exist
+
+import (
+	"context"
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"testing"
+)
+
+// memoryStore responde Exists com os valores guardados, registrando o último valor consultado.
+type memoryStore struct {
+	values map[string]bool
+	last   string
+	err    error
+}
+
+func (s *memoryStore) Exists(ctx context.Context, target Target, field Field, value string) (bool, error) {
+	s.last = value
+	return s.values[value], s.err
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		normalize func(string) string
+		value     string
+		want      string
+	}{
+		{"trim", NormalizeTrim, "  Ana Souza \n", "Ana Souza"},
+		{"email", NormalizeEmail, " Ana.Souza@Example.COM ", "ana.souza@example.com"},
+		{"slug", NormalizeSlug, "  Pão de Açúcar!  ", "pao-de-acucar"},
+		{"slug separators", NormalizeSlug, "--Olá__Mundo  2024--", "ola-mundo-2024"},
+		{"slug empty", NormalizeSlug, " !!! ", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.normalize(test.value); got != test.want {
+				t.Errorf("normalize(%q) = %q, want %q", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestServiceExists(t *testing.T) {
+	store := &memoryStore{values: map[string]bool{"ana@example.com": true, "Pao": true}}
+	service := NewService(store)
+	service.Register("account", Target{Name: "accounts", Fields: map[string]Field{
+		"email": {Name: "email", Normalize: NormalizeEmail},
+		"name":  {Name: "name", CaseInsensitive: true},
+		"code":  {Name: "code", Normalize: NormalizeTrim},
+	}})
+
+	tests := []struct {
+		name       string
+		module     string
+		field      string
+		value      string
+		normalized string
+		exists     bool
+		code       string
+	}{
+		{name: "normalized", module: "account", field: "email", value: " ANA@example.com ", normalized: "ana@example.com", exists: true},
+		{name: "case insensitive", module: "account", field: "name", value: "Pao", normalized: "pao"},
+		{name: "case sensitive", module: "account", field: "code", value: " Pao ", normalized: "Pao", exists: true},
+		{name: "unknown module", module: "product", field: "email", value: "x", code: api_error.CodeInvalidArgument},
+		{name: "unknown field", module: "account", field: "password", value: "x", code: api_error.CodeInvalidArgument},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := service.Exists(context.Background(), test.module, test.field, test.value)
+			if test.code != "" {
+				if !errors.Is(err, api_error.New(test.code, "", "")) {
+					t.Fatalf("Exists = %v, want %s", err, test.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exists: %v", err)
+			}
+			if store.last != test.normalized || *result.Value != test.normalized || *result.Exists != test.exists || *result.Field != test.field {
+				t.Errorf("Exists = %v %q (store %q), want %v %q", *result.Exists, *result.Value, store.last, test.exists, test.normalized)
+			}
+		})
+	}
+}
+
+func TestApiExistQuery(t *testing.T) {
+	store := &memoryStore{err: errors.New("conexão recusada")}
+	service := NewService(store)
+	service.Register("account", Target{Name: "accounts", Fields: map[string]Field{"email": {Name: "email"}}})
+
+	response, err := service.ApiExistQuery(context.Background(), ApiExistFilter{Module: "account", Field: "email", Value: "a"})
+	if err != nil || response.Success || response.Error == nil || response.Result != nil {
+		t.Errorf("ApiExistQuery with a store error = %+v, %v", response, err)
+	}
+
+	response, err = service.ApiExistQuery(context.Background(), ApiExistFilter{Module: "account", Field: "password"})
+	if err != nil || response.Success || response.Error == nil {
+		t.Errorf("ApiExistQuery with an unknown field = %+v, %v", response, err)
+	}
+
+	store.err = nil
+	response, err = service.ApiExistQuery(context.Background(), ApiExistFilter{Module: "account", Field: "email", Value: "a"})
+	if err != nil || !response.Success || response.Error != nil || *response.Result.Exists {
+		t.Errorf("ApiExistQuery = %+v, %v", response, err)
+	}
+}
+
+func TestExistsQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		target Target
+		field  Field
+		want   string
+		fails  bool
+	}{
+		{
+			name:   "column collation",
+			target: Target{Name: "accounts"},
+			field:  Field{Name: "email", CaseInsensitive: true},
+			want:   "SELECT 1 FROM `accounts` WHERE `email` = ? LIMIT 1",
+		},
+		{
+			name:   "explicit collation",
+			target: Target{Name: "shop.products"},
+			field:  Field{Name: "slug", Collation: "utf8mb4_0900_as_ci"},
+			want:   "SELECT 1 FROM `shop`.`products` WHERE `slug` = ? COLLATE utf8mb4_0900_as_ci LIMIT 1",
+		},
+		{name: "invalid collation", target: Target{Name: "accounts"}, field: Field{Name: "email", Collation: "x; DROP TABLE accounts"}, fails: true},
+		{name: "invalid column", target: Target{Name: "accounts"}, field: Field{Name: "email`"}, fails: true},
+		{name: "invalid table", target: Target{Name: "accounts; --"}, field: Field{Name: "email"}, fails: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := existsQuery(test.target, test.field)
+			if test.fails {
+				if err == nil {
+					t.Errorf("existsQuery = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("existsQuery = %q, %v, want %q", got, err, test.want)
+			}
+		})
+	}
+}
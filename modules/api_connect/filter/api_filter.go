@@ -0,0 +1,184 @@
+// Package filter compila expressões de filtro com ComparisonQueryOperators para BSON (MongoDB)
+// e para cláusulas WHERE parametrizadas (MySQL).
+// Somente campos presentes na lista de campos permitidos (Fields) podem ser filtrados.
+package filter
+
+import (
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"reflect"
+)
+
+// Limites das expressões enviadas pelos clientes.
+const (
+	MaxDepth      = 5
+	MaxConditions = 50
+)
+
+const module = "api_connect/filter"
+
+// Fields é a lista de campos permitidos: o nome exposto ao cliente e o campo/coluna correspondente no banco.
+type Fields map[string]string
+
+// Expression é uma condição (Field, Operator, Value) ou um grupo de expressões And/Or.
+type Expression struct {
+	Field    string
+	Operator params.ComparisonQueryOperators
+	Value    interface{}
+	And      []Expression
+	Or       []Expression
+}
+
+// Condition cria uma expressão de condição de um campo.
+func Condition(field string, operator params.ComparisonQueryOperators, value interface{}) Expression {
+	return Expression{Field: field, Operator: operator, Value: value}
+}
+
+// And cria um grupo de expressões que devem ser todas verdadeiras.
+func And(expressions ...Expression) Expression {
+	return Expression{And: expressions}
+}
+
+// Or cria um grupo de expressões das quais ao menos uma deve ser verdadeira.
+func Or(expressions ...Expression) Expression {
+	return Expression{Or: expressions}
+}
+
+// FromInput converte um ApiFilterInput em uma expressão.
+func FromInput(input *params.ApiFilterInput) Expression {
+	if input == nil {
+		return Expression{}
+	}
+
+	var and []Expression
+	for _, condition := range input.And {
+		and = append(and, Condition(condition.Field, condition.Operator, condition.Value))
+	}
+
+	var or []Expression
+	for _, condition := range input.Or {
+		or = append(or, Condition(condition.Field, condition.Operator, condition.Value))
+	}
+	if len(or) > 0 {
+		and = append(and, Or(or...))
+	}
+
+	for _, group := range input.Groups {
+		and = append(and, FromInput(group))
+	}
+	return And(and...)
+}
+
+// IsEmpty indica se a expressão não possui condições, inclusive nos grupos aninhados.
+func (e Expression) IsEmpty() bool {
+	if e.Field != "" {
+		return false
+	}
+	for _, item := range e.And {
+		if !item.IsEmpty() {
+			return false
+		}
+	}
+	for _, item := range e.Or {
+		if !item.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Compiler compila expressões validando os campos pela lista de campos permitidos.
+type Compiler struct {
+	fields Fields
+}
+
+// NewCompiler cria um compilador com a lista de campos permitidos.
+func NewCompiler(fields Fields) *Compiler {
+	return &Compiler{fields: fields}
+}
+
+// validate verifica a profundidade, a quantidade de condições, os campos e os operadores da expressão.
+func (c *Compiler) validate(expression Expression) error {
+	count := 0
+	var walk func(e Expression, depth int) error
+	walk = func(e Expression, depth int) error {
+		if depth > MaxDepth {
+			return api_error.New(api_error.CodeInvalidArgument, module, "depth", "filtro excede a profundidade máxima").
+				With("max", MaxDepth)
+		}
+		if e.Field != "" {
+			count++
+			if count > MaxConditions {
+				return api_error.New(api_error.CodeInvalidArgument, module, "conditions", "filtro excede a quantidade máxima de condições").
+					With("max", MaxConditions)
+			}
+			if _, ok := c.fields[e.Field]; !ok {
+				return api_error.New(api_error.CodeInvalidArgument, module, "field", "campo não permitido no filtro").
+					With("field", e.Field)
+			}
+			return validateOperator(e)
+		}
+		for _, item := range e.And {
+			if err := walk(item, depth+1); err != nil {
+				return err
+			}
+		}
+		for _, item := range e.Or {
+			if err := walk(item, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(expression, 0)
+}
+
+// validateOperator verifica se o operador é conhecido e se o valor é compatível com ele.
+// Os operadores de intervalo não aceitam null, que não é comparável da mesma forma no MongoDB e no MySQL.
+func validateOperator(e Expression) error {
+	switch e.Operator {
+	case params.ComparisonQueryOperatorsInArrayIn, params.ComparisonQueryOperatorsNotInArrayNin:
+		if _, ok := toSlice(e.Value); !ok {
+			return api_error.New(api_error.CodeInvalidArgument, module, "value", "o operador exige uma lista de valores").
+				With("field", e.Field).
+				With("operator", string(e.Operator))
+		}
+	case params.ComparisonQueryOperatorsGreaterThanGt, params.ComparisonQueryOperatorsGreaterThanOrEqualGte,
+		params.ComparisonQueryOperatorsLessThanLt, params.ComparisonQueryOperatorsLessThanOrEqualLte:
+		if e.Value == nil {
+			return api_error.New(api_error.CodeInvalidArgument, module, "value", "o operador não aceita o valor null").
+				With("field", e.Field).
+				With("operator", string(e.Operator))
+		}
+		fallthrough
+	case params.ComparisonQueryOperatorsEqualEq, params.ComparisonQueryOperatorsNotEqualNe:
+		if _, ok := toSlice(e.Value); ok {
+			return api_error.New(api_error.CodeInvalidArgument, module, "value", "o operador não aceita uma lista de valores").
+				With("field", e.Field).
+				With("operator", string(e.Operator))
+		}
+	default:
+		return api_error.New(api_error.CodeInvalidArgument, module, "operator", "operador de comparação inválido").
+			With("operator", string(e.Operator))
+	}
+	return nil
+}
+
+// toSlice converte listas de qualquer tipo (exceto []byte) em []interface{}.
+func toSlice(value interface{}) ([]interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	if _, ok := value.([]byte); ok {
+		return nil, false
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	result := make([]interface{}, v.Len())
+	for index := range result {
+		result[index] = v.Index(index).Interface()
+	}
+	return result, true
+}
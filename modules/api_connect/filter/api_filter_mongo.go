@@ -0,0 +1,54 @@
+package filter
+
+import (
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Mongo compila a expressão para um filtro bson.D do MongoDB.
+// Uma expressão vazia retorna um filtro vazio.
+func (c *Compiler) Mongo(expression Expression) (bson.D, error) {
+	if err := c.validate(expression); err != nil {
+		return nil, err
+	}
+	if expression.IsEmpty() {
+		return bson.D{}, nil
+	}
+	return c.mongo(expression), nil
+}
+
+// mongo compila recursivamente uma expressão já validada.
+func (c *Compiler) mongo(e Expression) bson.D {
+	if e.Field != "" {
+		value := e.Value
+		if list, ok := toSlice(value); ok {
+			value = list
+		}
+		return bson.D{{Key: c.fields[e.Field], Value: bson.D{{Key: e.Operator.Operator(), Value: value}}}}
+	}
+
+	var result bson.D
+	if and := c.mongoList(e.And); len(and) > 0 {
+		result = append(result, bson.E{Key: "$and", Value: and})
+	}
+	if or := c.mongoList(e.Or); len(or) > 0 {
+		result = append(result, bson.E{Key: "$or", Value: or})
+	}
+	return result
+}
+
+// mongoList compila uma lista de expressões, ignorando as expressões vazias.
+func (c *Compiler) mongoList(expressions []Expression) bson.A {
+	var result bson.A
+	for _, item := range expressions {
+		if !item.IsEmpty() {
+			result = append(result, c.mongo(item))
+		}
+	}
+	return result
+}
+
+// MongoInput compila um ApiFilterInput diretamente para bson.D.
+func (c *Compiler) MongoInput(input *params.ApiFilterInput) (bson.D, error) {
+	return c.Mongo(FromInput(input))
+}
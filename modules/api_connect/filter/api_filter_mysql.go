@@ -0,0 +1,114 @@
+package filter
+
+import (
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"strings"
+)
+
+// sqlOperators relaciona os operadores de comparação aos operadores SQL.
+var sqlOperators = map[params.ComparisonQueryOperators]string{
+	params.ComparisonQueryOperatorsEqualEq:               "=",
+	params.ComparisonQueryOperatorsGreaterThanGt:         ">",
+	params.ComparisonQueryOperatorsGreaterThanOrEqualGte: ">=",
+	params.ComparisonQueryOperatorsLessThanLt:            "<",
+	params.ComparisonQueryOperatorsLessThanOrEqualLte:    "<=",
+}
+
+// Mysql compila a expressão para uma cláusula WHERE (sem a palavra WHERE) com parâmetros "?".
+// Uma expressão vazia retorna "1 = 1".
+func (c *Compiler) Mysql(expression Expression) (string, []interface{}, error) {
+	if err := c.validate(expression); err != nil {
+		return "", nil, err
+	}
+	if expression.IsEmpty() {
+		return "1 = 1", nil, nil
+	}
+	var args []interface{}
+	where, err := c.mysql(expression, &args)
+	if err == nil && where == "" {
+		where = "1 = 1"
+	}
+	return where, args, err
+}
+
+// mysql compila recursivamente uma expressão já validada.
+// NOT_EQUAL_ne e NOT_IN_ARRAY_nin incluem valores NULL, equivalente aos campos inexistentes no MongoDB.
+func (c *Compiler) mysql(e Expression, args *[]interface{}) (string, error) {
+	if e.Field != "" {
+		column, err := mysql.QuoteIdentifier(c.fields[e.Field])
+		if err != nil {
+			return "", err
+		}
+
+		switch e.Operator {
+		case params.ComparisonQueryOperatorsInArrayIn, params.ComparisonQueryOperatorsNotInArrayNin:
+			list, _ := toSlice(e.Value)
+			isIn := e.Operator == params.ComparisonQueryOperatorsInArrayIn
+			if len(list) == 0 {
+				if isIn {
+					return "1 = 0", nil
+				}
+				return "1 = 1", nil
+			}
+			*args = append(*args, list...)
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(list)), ", ")
+			if isIn {
+				return column + " IN (" + placeholders + ")", nil
+			}
+			return "(" + column + " NOT IN (" + placeholders + ") OR " + column + " IS NULL)", nil
+		case params.ComparisonQueryOperatorsNotEqualNe:
+			if e.Value == nil {
+				return column + " IS NOT NULL", nil
+			}
+			*args = append(*args, e.Value)
+			return "(" + column + " <> ? OR " + column + " IS NULL)", nil
+		case params.ComparisonQueryOperatorsEqualEq:
+			if e.Value == nil {
+				return column + " IS NULL", nil
+			}
+		}
+
+		*args = append(*args, e.Value)
+		return column + " " + sqlOperators[e.Operator] + " ?", nil
+	}
+
+	var parts []string
+	if and, err := c.mysqlList(e.And, " AND ", args); err != nil {
+		return "", err
+	} else if and != "" {
+		parts = append(parts, and)
+	}
+	if or, err := c.mysqlList(e.Or, " OR ", args); err != nil {
+		return "", err
+	} else if or != "" {
+		parts = append(parts, or)
+	}
+	return strings.Join(parts, " AND "), nil
+}
+
+// mysqlList compila uma lista de expressões unidas pelo separador, ignorando as expressões vazias.
+func (c *Compiler) mysqlList(expressions []Expression, separator string, args *[]interface{}) (string, error) {
+	var parts []string
+	for _, item := range expressions {
+		if item.IsEmpty() {
+			continue
+		}
+		part, err := c.mysql(item, args)
+		if err != nil {
+			return "", err
+		}
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "", nil
+	}
+	return "(" + strings.Join(parts, separator) + ")", nil
+}
+
+// MysqlInput compila um ApiFilterInput diretamente para uma cláusula WHERE parametrizada.
+func (c *Compiler) MysqlInput(input *params.ApiFilterInput) (string, []interface{}, error) {
+	return c.Mysql(FromInput(input))
+}
@@ -0,0 +1,225 @@
+package filter
+
+import (
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"reflect"
+	"testing"
+)
+
+var fields = Fields{"name": "name", "age": "profile.age", "status": "status"}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression Expression
+		mongo      bson.D
+		where      string
+		args       []interface{}
+	}{
+		{
+			name:       "empty",
+			expression: Expression{},
+			mongo:      bson.D{},
+			where:      "1 = 1",
+		},
+		{
+			name:       "condition",
+			expression: Condition("age", params.ComparisonQueryOperatorsGreaterThanOrEqualGte, 18),
+			mongo:      bson.D{{Key: "profile.age", Value: bson.D{{Key: "$gte", Value: 18}}}},
+			where:      "`profile`.`age` >= ?",
+			args:       []interface{}{18},
+		},
+		{
+			name:       "equal null",
+			expression: Condition("name", params.ComparisonQueryOperatorsEqualEq, nil),
+			mongo:      bson.D{{Key: "name", Value: bson.D{{Key: "$eq", Value: nil}}}},
+			where:      "`name` IS NULL",
+		},
+		{
+			name:       "not equal includes null",
+			expression: Condition("name", params.ComparisonQueryOperatorsNotEqualNe, "ana"),
+			mongo:      bson.D{{Key: "name", Value: bson.D{{Key: "$ne", Value: "ana"}}}},
+			where:      "(`name` <> ? OR `name` IS NULL)",
+			args:       []interface{}{"ana"},
+		},
+		{
+			name:       "in",
+			expression: Condition("status", params.ComparisonQueryOperatorsInArrayIn, []string{"A", "B"}),
+			mongo:      bson.D{{Key: "status", Value: bson.D{{Key: "$in", Value: []interface{}{"A", "B"}}}}},
+			where:      "`status` IN (?, ?)",
+			args:       []interface{}{"A", "B"},
+		},
+		{
+			name:       "empty in",
+			expression: Condition("status", params.ComparisonQueryOperatorsInArrayIn, []string{}),
+			mongo:      bson.D{{Key: "status", Value: bson.D{{Key: "$in", Value: []interface{}{}}}}},
+			where:      "1 = 0",
+		},
+		{
+			name:       "not in includes null",
+			expression: Condition("status", params.ComparisonQueryOperatorsNotInArrayNin, []int{1}),
+			mongo:      bson.D{{Key: "status", Value: bson.D{{Key: "$nin", Value: []interface{}{1}}}}},
+			where:      "(`status` NOT IN (?) OR `status` IS NULL)",
+			args:       []interface{}{1},
+		},
+		{
+			name: "groups",
+			expression: And(
+				Condition("age", params.ComparisonQueryOperatorsLessThanLt, 65),
+				Or(
+					Condition("name", params.ComparisonQueryOperatorsEqualEq, "ana"),
+					Condition("name", params.ComparisonQueryOperatorsEqualEq, "bia"),
+				),
+				And(),
+			),
+			mongo: bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "profile.age", Value: bson.D{{Key: "$lt", Value: 65}}}},
+				bson.D{{Key: "$or", Value: bson.A{
+					bson.D{{Key: "name", Value: bson.D{{Key: "$eq", Value: "ana"}}}},
+					bson.D{{Key: "name", Value: bson.D{{Key: "$eq", Value: "bia"}}}},
+				}}},
+			}}},
+			where: "(`profile`.`age` < ? AND (`name` = ? OR `name` = ?))",
+			args:  []interface{}{65, "ana", "bia"},
+		},
+	}
+	compiler := NewCompiler(fields)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mongo, err := compiler.Mongo(test.expression)
+			if err != nil {
+				t.Fatalf("Mongo: %v", err)
+			}
+			if !reflect.DeepEqual(mongo, test.mongo) {
+				t.Errorf("Mongo = %v, want %v", mongo, test.mongo)
+			}
+
+			where, args, err := compiler.Mysql(test.expression)
+			if err != nil {
+				t.Fatalf("Mysql: %v", err)
+			}
+			if where != test.where || !reflect.DeepEqual(args, test.args) {
+				t.Errorf("Mysql = %q %v, want %q %v", where, args, test.where, test.args)
+			}
+		})
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	deep := Condition("name", params.ComparisonQueryOperatorsEqualEq, "ana")
+	for index := 0; index <= MaxDepth; index++ {
+		deep = And(deep)
+	}
+	many := make([]Expression, MaxConditions+1)
+	for index := range many {
+		many[index] = Condition("age", params.ComparisonQueryOperatorsGreaterThanGt, index)
+	}
+
+	tests := []struct {
+		name       string
+		expression Expression
+		path       string
+	}{
+		{"field not allowed", Condition("password", params.ComparisonQueryOperatorsEqualEq, "x"), "field"},
+		{"unknown operator", Condition("name", "LIKE_like", "x"), "operator"},
+		{"in without list", Condition("status", params.ComparisonQueryOperatorsInArrayIn, "A"), "value"},
+		{"equal with list", Condition("status", params.ComparisonQueryOperatorsEqualEq, []string{"A"}), "value"},
+		{"range with null", Condition("age", params.ComparisonQueryOperatorsGreaterThanGt, nil), "value"},
+		{"depth", deep, "depth"},
+		{"conditions", Or(many...), "conditions"},
+	}
+	compiler := NewCompiler(fields)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := compiler.Mongo(test.expression)
+			var apiErr *api_error.ApiError
+			if !errors.As(err, &apiErr) || apiErr.Code != api_error.CodeInvalidArgument || apiErr.Path != test.path {
+				t.Errorf("Mongo = %v, want INVALID_ARGUMENT at %s", err, test.path)
+			}
+			if _, _, err := compiler.Mysql(test.expression); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+				t.Errorf("Mysql = %v, want INVALID_ARGUMENT", err)
+			}
+		})
+	}
+}
+
+func TestFromInput(t *testing.T) {
+	input := &params.ApiFilterInput{
+		And: []*params.ApiFilterConditionInput{{Field: "age", Operator: params.ComparisonQueryOperatorsGreaterThanGt, Value: 18}},
+		Or: []*params.ApiFilterConditionInput{
+			{Field: "status", Operator: params.ComparisonQueryOperatorsEqualEq, Value: "A"},
+			{Field: "status", Operator: params.ComparisonQueryOperatorsEqualEq, Value: nil},
+		},
+		Groups: []*params.ApiFilterInput{{
+			Or: []*params.ApiFilterConditionInput{{Field: "name", Operator: params.ComparisonQueryOperatorsNotEqualNe, Value: "ana"}},
+		}},
+	}
+	where, args, err := NewCompiler(fields).MysqlInput(input)
+	if err != nil {
+		t.Fatalf("MysqlInput: %v", err)
+	}
+	want := "(`profile`.`age` > ? AND (`status` = ? OR `status` IS NULL) AND (((`name` <> ? OR `name` IS NULL))))"
+	if where != want || !reflect.DeepEqual(args, []interface{}{18, "A", "ana"}) {
+		t.Errorf("MysqlInput = %q %v, want %q [18 A ana]", where, args, want)
+	}
+
+	if !FromInput(nil).IsEmpty() {
+		t.Error("FromInput(nil) is not empty")
+	}
+}
+
+func TestEmptyGroups(t *testing.T) {
+	condition := &params.ApiFilterConditionInput{Field: "name", Operator: params.ComparisonQueryOperatorsEqualEq, Value: "ana"}
+	tests := []struct {
+		name  string
+		input *params.ApiFilterInput
+		mongo bson.D
+		where string
+		args  []interface{}
+	}{
+		{
+			name:  "empty group",
+			input: &params.ApiFilterInput{Groups: []*params.ApiFilterInput{{}}},
+			mongo: bson.D{},
+			where: "1 = 1",
+		},
+		{
+			name:  "nested empty groups",
+			input: &params.ApiFilterInput{Groups: []*params.ApiFilterInput{{Groups: []*params.ApiFilterInput{{}, {}}}}},
+			mongo: bson.D{},
+			where: "1 = 1",
+		},
+		{
+			name: "condition with nested empty groups",
+			input: &params.ApiFilterInput{
+				And:    []*params.ApiFilterConditionInput{condition},
+				Groups: []*params.ApiFilterInput{{Groups: []*params.ApiFilterInput{{}}}},
+			},
+			mongo: bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "name", Value: bson.D{{Key: "$eq", Value: "ana"}}}}}}},
+			where: "(`name` = ?)",
+			args:  []interface{}{"ana"},
+		},
+	}
+	compiler := NewCompiler(fields)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mongo, err := compiler.MongoInput(test.input)
+			if err != nil {
+				t.Fatalf("MongoInput: %v", err)
+			}
+			if !reflect.DeepEqual(mongo, test.mongo) {
+				t.Errorf("MongoInput = %#v, want %#v", mongo, test.mongo)
+			}
+			where, args, err := compiler.MysqlInput(test.input)
+			if err != nil {
+				t.Fatalf("MysqlInput: %v", err)
+			}
+			if where != test.where || !reflect.DeepEqual(args, test.args) {
+				t.Errorf("MysqlInput = %q %v, want %q %v", where, args, test.where, test.args)
+			}
+		})
+	}
+}
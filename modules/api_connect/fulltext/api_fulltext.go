@@ -0,0 +1,140 @@
+// Package fulltext implementa a busca textual por relevância utilizada por ApiTypesSearchInput.text.
+// Os módulos declaram os campos pesquisáveis (Definition) e o Engine cria o índice e executa as consultas:
+// índice text no MongoDB, índice FULLTEXT no MySQL ou um índice em memória para testes sem banco de dados.
+package fulltext
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const module = "api_connect/fulltext"
+
+// MaxIndexName é o tamanho máximo do nome de um índice no MySQL.
+const MaxIndexName = 64
+
+// Definition declara os campos pesquisáveis de uma coleção ou tabela.
+type Definition struct {
+	// Name é o nome da coleção (MongoDB) ou tabela (MySQL).
+	Name string
+
+	// Fields são os campos pesquisáveis.
+	Fields []string
+
+	// Weights define o peso de cada campo na relevância. Campos sem peso utilizam peso 1.
+	Weights map[string]int
+
+	// Language é o idioma do índice text do MongoDB (padrão "portuguese").
+	Language string
+}
+
+// IndexName retorna o nome do índice criado para a definição.
+// Nomes com mais de MaxIndexName bytes são truncados e recebem o hash do nome completo,
+// mantendo nomes distintos para conjuntos de campos distintos.
+func (d Definition) IndexName() string {
+	name := "fulltext_" + strings.Join(d.Fields, "_")
+	if len(name) <= MaxIndexName {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := "_" + hex.EncodeToString(sum[:4])
+	size := MaxIndexName - len(suffix)
+	for size > 0 && !utf8.RuneStart(name[size]) {
+		size--
+	}
+	return name[:size] + suffix
+}
+
+// weight retorna o peso do campo.
+func (d Definition) weight(field string) int {
+	if weight, ok := d.Weights[field]; ok && weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+// Query é uma consulta textual paginada.
+type Query struct {
+	Text       string
+	Pagination *params.ApiPaginationInput
+
+	// WithScore indica se a relevância deve ser retornada em Hit.Score.
+	WithScore bool
+}
+
+// Hit é um documento encontrado com a sua relevância.
+type Hit struct {
+	ID       interface{}
+	Score    float64
+	Document interface{}
+}
+
+// Engine cria os índices e executa as consultas textuais.
+type Engine interface {
+	EnsureIndex(ctx context.Context, definition Definition) error
+	Search(ctx context.Context, definition Definition, query Query) ([]Hit, *params.ApiPageInfo, error)
+}
+
+var registry = struct {
+	sync.RWMutex
+	definitions map[string]Definition
+}{definitions: map[string]Definition{}}
+
+// Register registra a definição de busca textual de um módulo.
+func Register(moduleName string, definition Definition) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.definitions[moduleName] = definition
+}
+
+// Lookup retorna a definição de busca textual registrada para o módulo.
+func Lookup(moduleName string) (Definition, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	definition, ok := registry.definitions[moduleName]
+	return definition, ok
+}
+
+// EnsureIndexes cria os índices de todas as definições registradas.
+func EnsureIndexes(ctx context.Context, engine Engine) error {
+	registry.RLock()
+	definitions := make([]Definition, 0, len(registry.definitions))
+	for _, definition := range registry.definitions {
+		definitions = append(definitions, definition)
+	}
+	registry.RUnlock()
+
+	for _, definition := range definitions {
+		if err := engine.EnsureIndex(ctx, definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search executa a consulta textual de ApiTypesSearchInput.text no módulo registrado.
+func Search(ctx context.Context, engine Engine, moduleName string, query Query) ([]Hit, *params.ApiPageInfo, error) {
+	definition, ok := Lookup(moduleName)
+	if !ok {
+		return nil, nil, api_error.New(api_error.CodeNotFound, module, "definition", "módulo sem busca textual registrada").
+			With("module", moduleName)
+	}
+	if err := validateQuery(query); err != nil {
+		return nil, nil, err
+	}
+	return engine.Search(ctx, definition, query)
+}
+
+// validateQuery verifica se o texto da consulta não está vazio.
+func validateQuery(query Query) error {
+	if strings.TrimSpace(query.Text) == "" {
+		return api_error.New(api_error.CodeInvalidArgument, module, "text", "o texto da consulta é obrigatório")
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package fulltext
+
+import (
+	"context"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/pagination"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// accents converte caracteres acentuados para a sua forma sem acento na tokenização.
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// MemoryEngine é um índice invertido em memória, utilizado em testes sem banco de dados.
+// A relevância é calculada por TF-IDF, considerando o peso dos campos.
+type MemoryEngine struct {
+	mu      sync.RWMutex
+	indexes map[string]*memoryIndex
+}
+
+// memoryIndex é o índice invertido de uma definição.
+type memoryIndex struct {
+	documents map[string]memoryDocument
+	postings  map[string]map[string]float64
+}
+
+// memoryDocument é um documento indexado.
+type memoryDocument struct {
+	id       interface{}
+	document map[string]interface{}
+}
+
+// NewMemoryEngine cria um índice em memória vazio.
+func NewMemoryEngine() *MemoryEngine {
+	return &MemoryEngine{indexes: map[string]*memoryIndex{}}
+}
+
+// EnsureIndex cria o índice da definição, caso ainda não exista.
+func (e *MemoryEngine) EnsureIndex(_ context.Context, definition Definition) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.index(definition)
+	return nil
+}
+
+// index retorna o índice da definição, criando-o se necessário. Requer o bloqueio de escrita.
+func (e *MemoryEngine) index(definition Definition) *memoryIndex {
+	index, ok := e.indexes[definition.Name]
+	if !ok {
+		index = &memoryIndex{documents: map[string]memoryDocument{}, postings: map[string]map[string]float64{}}
+		e.indexes[definition.Name] = index
+	}
+	return index
+}
+
+// Index adiciona ou substitui um documento no índice.
+func (e *MemoryEngine) Index(definition Definition, id interface{}, document map[string]interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	index := e.index(definition)
+	key := fmt.Sprint(id)
+	index.remove(key)
+	index.documents[key] = memoryDocument{id: id, document: document}
+
+	for _, field := range definition.Fields {
+		value, ok := document[field]
+		if !ok || value == nil {
+			continue
+		}
+		for _, term := range tokenize(fmt.Sprint(value)) {
+			if index.postings[term] == nil {
+				index.postings[term] = map[string]float64{}
+			}
+			index.postings[term][key] += float64(definition.weight(field))
+		}
+	}
+}
+
+// Remove retira um documento do índice.
+func (e *MemoryEngine) Remove(definition Definition, id interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if index, ok := e.indexes[definition.Name]; ok {
+		index.remove(fmt.Sprint(id))
+	}
+}
+
+// remove retira um documento das listas de ocorrências. Requer o bloqueio de escrita.
+func (i *memoryIndex) remove(key string) {
+	if _, ok := i.documents[key]; !ok {
+		return
+	}
+	delete(i.documents, key)
+	for term, posting := range i.postings {
+		delete(posting, key)
+		if len(posting) == 0 {
+			delete(i.postings, term)
+		}
+	}
+}
+
+// Search retorna os documentos que contêm algum termo da consulta, ordenados pela relevância.
+func (e *MemoryEngine) Search(_ context.Context, definition Definition, query Query) ([]Hit, *params.ApiPageInfo, error) {
+	if err := validateQuery(query); err != nil {
+		return nil, nil, err
+	}
+	page, err := pagination.Normalize(query.Pagination, pagination.MaxSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	index, ok := e.indexes[definition.Name]
+	if !ok {
+		return []Hit{}, page.PageInfo(0), nil
+	}
+
+	scores := map[string]float64{}
+	total := float64(len(index.documents))
+	for _, term := range tokenize(query.Text) {
+		posting := index.postings[term]
+		idf := math.Log(1 + total/float64(len(posting)+1))
+		for key, frequency := range posting {
+			scores[key] += frequency * idf
+		}
+	}
+
+	keys := make([]string, 0, len(scores))
+	for key := range scores {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if scores[keys[i]] != scores[keys[j]] {
+			return scores[keys[i]] > scores[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	hits := []Hit{}
+	for position := page.Offset; position < len(keys) && position < page.Offset+page.Size; position++ {
+		document := index.documents[keys[position]]
+		hit := Hit{ID: document.id, Document: document.document}
+		if query.WithScore {
+			hit.Score = scores[keys[position]]
+		}
+		hits = append(hits, hit)
+	}
+	return hits, page.PageInfo(len(keys)), nil
+}
+
+// tokenize divide o texto em termos minúsculos, sem acentos, com ao menos dois caracteres.
+func tokenize(text string) []string {
+	text = accents.Replace(strings.ToLower(text))
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	result := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if len([]rune(field)) >= 2 {
+			result = append(result, field)
+		}
+	}
+	return result
+}
@@ -0,0 +1,90 @@
+package fulltext
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/pagination"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoEngine executa a busca textual com índices text do MongoDB.
+type MongoEngine struct {
+	db       *mongo.MongoDB
+	database string
+}
+
+// NewMongoEngine cria um Engine para o banco de dados MongoDB informado.
+func NewMongoEngine(db *mongo.MongoDB, database string) *MongoEngine {
+	return &MongoEngine{db: db, database: database}
+}
+
+// EnsureIndex cria o índice text da definição, caso ainda não exista.
+func (e *MongoEngine) EnsureIndex(ctx context.Context, definition Definition) error {
+	keys := bson.D{}
+	weights := bson.D{}
+	for _, field := range definition.Fields {
+		keys = append(keys, bson.E{Key: field, Value: "text"})
+		weights = append(weights, bson.E{Key: field, Value: definition.weight(field)})
+	}
+
+	language := definition.Language
+	if language == "" {
+		language = "portuguese"
+	}
+
+	_, err := e.db.Collection(e.database, definition.Name).Indexes().CreateOne(ctx, mongodriver.IndexModel{
+		Keys: keys,
+		Options: options.Index().
+			SetName(definition.IndexName()).
+			SetWeights(weights).
+			SetDefaultLanguage(language),
+	})
+	return err
+}
+
+// Search executa a consulta $text ordenada pela relevância.
+func (e *MongoEngine) Search(ctx context.Context, definition Definition, query Query) ([]Hit, *params.ApiPageInfo, error) {
+	if err := validateQuery(query); err != nil {
+		return nil, nil, err
+	}
+	page, err := pagination.Normalize(query.Pagination, pagination.MaxSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filter := bson.D{{Key: "$text", Value: bson.D{{Key: "$search", Value: query.Text}}}}
+	score := bson.D{{Key: "_score", Value: bson.D{{Key: "$meta", Value: "textScore"}}}}
+	findOptions := page.FindOptions().SetSort(score)
+	if query.WithScore {
+		findOptions.SetProjection(score)
+	}
+
+	collection := e.db.Collection(e.database, definition.Name)
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var documents []bson.Raw
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, nil, err
+	}
+
+	hits := make([]Hit, 0, len(documents))
+	for _, document := range documents {
+		hit := Hit{ID: document.Lookup("_id"), Document: document}
+		if query.WithScore {
+			hit.Score, _ = document.Lookup("_score").DoubleOK()
+		}
+		hits = append(hits, hit)
+	}
+
+	total, err := pagination.CountMongo(ctx, collection, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return hits, page.PageInfo(total), nil
+}
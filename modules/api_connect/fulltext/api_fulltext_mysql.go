@@ -0,0 +1,185 @@
+package fulltext
+
+import (
+	"context"
+	"database/sql"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/pagination"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"strconv"
+	"strings"
+)
+
+// MysqlEngine executa a busca textual com índices FULLTEXT do MySQL.
+type MysqlEngine struct {
+	db       *mysql.MysqlDB
+	idColumn string
+}
+
+// NewMysqlEngine cria um Engine para o MysqlDB informado. idColumn é a coluna identificadora das tabelas.
+func NewMysqlEngine(db *mysql.MysqlDB, idColumn string) *MysqlEngine {
+	return &MysqlEngine{db: db, idColumn: idColumn}
+}
+
+// EnsureIndex cria o índice FULLTEXT da definição, caso ainda não exista.
+// Um índice existente com o mesmo nome e outras colunas, ou de outro tipo, é recriado com os campos da definição.
+func (e *MysqlEngine) EnsureIndex(ctx context.Context, definition Definition) error {
+	existing, err := e.indexColumns(ctx, definition)
+	if err != nil {
+		return err
+	}
+	if sameColumns(existing, definition.Fields) {
+		return nil
+	}
+
+	table, columns, err := e.identifiers(definition)
+	if err != nil {
+		return err
+	}
+	index, err := mysql.QuoteIdentifier(definition.IndexName())
+	if err != nil {
+		return err
+	}
+
+	_, err = e.db.Client().ExecContext(ctx, indexStatement(table, index, columns, existing != nil))
+	return err
+}
+
+// indexColumns retorna as colunas do índice da definição, na ordem do índice.
+// Retorna nil quando o índice não existe e uma coluna vazia quando o índice existente não é FULLTEXT.
+func (e *MysqlEngine) indexColumns(ctx context.Context, definition Definition) ([]string, error) {
+	rows, err := e.db.Client().QueryContext(ctx,
+		"SELECT COLUMN_NAME, INDEX_TYPE FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ? ORDER BY SEQ_IN_INDEX",
+		definition.Name, definition.IndexName())
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var columns []string
+	for rows.Next() {
+		var column, indexType sql.NullString
+		if err = rows.Scan(&column, &indexType); err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(indexType.String, "FULLTEXT") {
+			column.String = ""
+		}
+		columns = append(columns, column.String)
+	}
+	return columns, rows.Err()
+}
+
+// sameColumns informa se as colunas do índice existente são os campos da definição, na mesma ordem.
+// Os nomes de colunas do MySQL não diferenciam maiúsculas de minúsculas.
+func sameColumns(existing []string, fields []string) bool {
+	if existing == nil || len(existing) != len(fields) {
+		return false
+	}
+	for i, column := range existing {
+		if !strings.EqualFold(column, fields[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// indexStatement retorna o ALTER TABLE que cria o índice ou, quando ele já existe, o remove e recria no mesmo comando.
+func indexStatement(table string, index string, columns string, exists bool) string {
+	statement := "ALTER TABLE " + table
+	if exists {
+		statement += " DROP INDEX " + index + ","
+	}
+	return statement + " ADD FULLTEXT INDEX " + index + " (" + columns + ")"
+}
+
+// Search executa a consulta MATCH ... AGAINST ordenada pela relevância.
+// Os documentos são retornados como map[string]interface{}.
+func (e *MysqlEngine) Search(ctx context.Context, definition Definition, query Query) ([]Hit, *params.ApiPageInfo, error) {
+	if err := validateQuery(query); err != nil {
+		return nil, nil, err
+	}
+	page, err := pagination.Normalize(query.Pagination, pagination.MaxSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	table, columns, err := e.identifiers(definition)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	match := "MATCH(" + columns + ") AGAINST(? IN NATURAL LANGUAGE MODE)"
+	where := " FROM " + table + " WHERE " + match
+	sqlQuery, args := page.LimitOffset("SELECT *, "+match+" AS _score"+where+" ORDER BY _score DESC", []interface{}{query.Text, query.Text})
+
+	rows, err := e.db.Client().QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	hits, err := scanHits(rows, e.idColumn, query.WithScore)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var total int
+	if err = e.db.Client().QueryRowContext(ctx, "SELECT COUNT(*)"+where, query.Text).Scan(&total); err != nil {
+		return nil, nil, err
+	}
+	return hits, page.PageInfo(total), nil
+}
+
+// identifiers valida e escapa o nome da tabela e as colunas da definição.
+func (e *MysqlEngine) identifiers(definition Definition) (string, string, error) {
+	table, err := mysql.QuoteIdentifier(definition.Name)
+	if err != nil {
+		return "", "", err
+	}
+	columns := make([]string, 0, len(definition.Fields))
+	for _, field := range definition.Fields {
+		column, err := mysql.QuoteIdentifier(field)
+		if err != nil {
+			return "", "", err
+		}
+		columns = append(columns, column)
+	}
+	return table, strings.Join(columns, ", "), nil
+}
+
+// scanHits lê as linhas como map[string]interface{}, separando a coluna _score.
+func scanHits(rows *sql.Rows, idColumn string, withScore bool) ([]Hit, error) {
+	documents, err := mysql.ScanMaps(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, 0, len(documents))
+	for _, document := range documents {
+		hit := Hit{ID: document[idColumn], Document: document}
+		if withScore {
+			hit.Score = toFloat(document["_score"])
+		}
+		delete(document, "_score")
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+// toFloat converte o valor da relevância retornado pelo driver para float64.
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case string:
+		result, _ := strconv.ParseFloat(v, 64)
+		return result
+	}
+	return 0
+}
@@ -0,0 +1,179 @@
+package fulltext
+
+import (
+	"context"
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var articles = Definition{
+	Name:    "article",
+	Fields:  []string{"title", "body"},
+	Weights: map[string]int{"title": 5},
+}
+
+// newEngine cria um MemoryEngine com os artigos de teste.
+func newEngine() *MemoryEngine {
+	engine := NewMemoryEngine()
+	engine.Index(articles, 1, map[string]interface{}{"title": "Receita de pão", "body": "Farinha, água e sal."})
+	engine.Index(articles, 2, map[string]interface{}{"title": "Viagem", "body": "O pão da padaria da esquina."})
+	engine.Index(articles, 3, map[string]interface{}{"title": "Ação e reação", "body": "Física básica", "tags": "pão"})
+	engine.Index(articles, 4, map[string]interface{}{"title": nil, "body": "Sal grosso e PÃO"})
+	return engine
+}
+
+// ids retorna os identificadores dos documentos encontrados.
+func ids(hits []Hit) []interface{} {
+	result := make([]interface{}, 0, len(hits))
+	for _, hit := range hits {
+		result = append(result, hit.ID)
+	}
+	return result
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("Ação, REAÇÃO e pão-de-queijo 2x!")
+	want := []string{"acao", "reacao", "pao", "de", "queijo", "2x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize = %q, want %q", got, want)
+	}
+}
+
+func TestMemorySearch(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []interface{}
+	}{
+		{"title weight first", "pao", []interface{}{1, 2, 4}},
+		{"accents and case", "PÃO", []interface{}{1, 2, 4}},
+		{"more terms first", "pao sal", []interface{}{1, 4, 2}},
+		{"field not indexed", "fisica", []interface{}{3}},
+		{"no match", "bolo", []interface{}{}},
+	}
+	engine := newEngine()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hits, pageInfo, err := engine.Search(context.Background(), articles, Query{Text: test.text})
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if got := ids(hits); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Search(%q) = %v, want %v", test.text, got, test.want)
+			}
+			if *pageInfo.Total != len(test.want) {
+				t.Errorf("Total = %d, want %d", *pageInfo.Total, len(test.want))
+			}
+		})
+	}
+}
+
+func TestMemorySearchPagination(t *testing.T) {
+	engine := newEngine()
+	hits, pageInfo, err := engine.Search(context.Background(), articles, Query{
+		Text:       "pao",
+		Pagination: &params.ApiPaginationInput{Size: 2, Page: 2},
+		WithScore:  true,
+	})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if got := ids(hits); !reflect.DeepEqual(got, []interface{}{4}) {
+		t.Errorf("Search = %v, want [4]", got)
+	}
+	if hits[0].Score <= 0 {
+		t.Errorf("Score = %v, want the relevance", hits[0].Score)
+	}
+	if *pageInfo.Total != 3 || *pageInfo.Has.NextPage || !*pageInfo.Has.PreviousPage {
+		t.Errorf("PageInfo = total %d next %v previous %v", *pageInfo.Total, *pageInfo.Has.NextPage, *pageInfo.Has.PreviousPage)
+	}
+}
+
+func TestMemoryIndexReplaceAndRemove(t *testing.T) {
+	engine := newEngine()
+	engine.Index(articles, 1, map[string]interface{}{"title": "Bolo de cenoura"})
+	engine.Remove(articles, 2)
+
+	hits, _, err := engine.Search(context.Background(), articles, Query{Text: "pao bolo"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if got := ids(hits); !reflect.DeepEqual(got, []interface{}{1, 4}) {
+		t.Errorf("Search = %v, want [1 4]", got)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	engine := newEngine()
+	Register("app/article", articles)
+
+	if _, _, err := Search(context.Background(), engine, "app/unknown", Query{Text: "pao"}); !errors.Is(err, api_error.New(api_error.CodeNotFound, "", "")) {
+		t.Errorf("Search unknown module = %v, want CodeNotFound", err)
+	}
+	if _, _, err := Search(context.Background(), engine, "app/article", Query{Text: "  "}); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+		t.Errorf("Search empty text = %v, want CodeInvalidArgument", err)
+	}
+	hits, _, err := Search(context.Background(), engine, "app/article", Query{Text: "receita"})
+	if err != nil || !reflect.DeepEqual(ids(hits), []interface{}{1}) {
+		t.Errorf("Search = %v, %v, want [1]", ids(hits), err)
+	}
+}
+
+func TestIndexName(t *testing.T) {
+	long := Definition{Fields: []string{"title", "subtitle", "description", "summary", "keywords", "author_name"}}
+	other := Definition{Fields: []string{"title", "subtitle", "description", "summary", "keywords", "author_names"}}
+	accents := Definition{Fields: []string{"título", "subtítulo", "descrição", "resumo", "palavras_chave", "ação"}}
+
+	if name := articles.IndexName(); name != "fulltext_title_body" {
+		t.Errorf("IndexName = %q, want fulltext_title_body", name)
+	}
+	for _, definition := range []Definition{long, other, accents} {
+		name := definition.IndexName()
+		if len(name) > MaxIndexName || !utf8.ValidString(name) || !strings.HasPrefix(name, "fulltext_t") {
+			t.Errorf("IndexName = %q (%d bytes), want a valid name up to %d bytes", name, len(name), MaxIndexName)
+		}
+		if name != definition.IndexName() {
+			t.Errorf("IndexName is not stable: %q", name)
+		}
+	}
+	if long.IndexName() == other.IndexName() {
+		t.Errorf("IndexName = %q for distinct fields", long.IndexName())
+	}
+}
+
+func TestSameColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		want     bool
+	}{
+		{name: "missing index", existing: nil},
+		{name: "same columns", existing: []string{"title", "body"}, want: true},
+		{name: "different case", existing: []string{"Title", "BODY"}, want: true},
+		{name: "other order", existing: []string{"body", "title"}},
+		{name: "fewer columns", existing: []string{"title"}},
+		{name: "more columns", existing: []string{"title", "body", "summary"}},
+		{name: "other index type", existing: []string{"", ""}},
+	}
+	for _, test := range tests {
+		if got := sameColumns(test.existing, articles.Fields); got != test.want {
+			t.Errorf("%s: sameColumns = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestIndexStatement(t *testing.T) {
+	if got, want := indexStatement("`article`", "`fulltext_title_body`", "`title`, `body`", false),
+		"ALTER TABLE `article` ADD FULLTEXT INDEX `fulltext_title_body` (`title`, `body`)"; got != want {
+		t.Errorf("indexStatement = %q, want %q", got, want)
+	}
+	if got, want := indexStatement("`article`", "`fulltext_title_body`", "`title`, `body`", true),
+		"ALTER TABLE `article` DROP INDEX `fulltext_title_body`, ADD FULLTEXT INDEX `fulltext_title_body` (`title`, `body`)"; got != want {
+		t.Errorf("indexStatement = %q, want %q", got, want)
+	}
+}
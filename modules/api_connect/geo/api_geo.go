@@ -0,0 +1,230 @@
+// Package geo calcula distâncias entre pontos GeoJSON (Haversine e Vincenty) e a duração estimada por um perfil
+// de velocidade, produzindo os tipos GraphQL Distance e TextValue com o texto formatado no idioma da requisição,
+// por exemplo "3,2 km" e "12 min". O arquivo api_geo_mongo.go contém os índices 2dsphere e as consultas
+// $geoNear e $geoWithin do MongoDB.
+//
+// As coordenadas seguem a ordem do GeoJSON: [longitude, latitude].
+package geo
+
+import (
+	"context"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/i18n"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const module = "api_connect/geo"
+
+// TypePoint é o tipo GeoJSON de um ponto.
+const TypePoint = "Point"
+
+// EarthRadius é o raio médio da Terra em metros, utilizado pela fórmula de Haversine.
+const EarthRadius = 6371008.8
+
+// Elipsoide WGS-84, utilizado pela fórmula de Vincenty.
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+	wgs84B = wgs84A * (1 - wgs84F)
+)
+
+// GeoPointInput é a representação Go do input GraphQL GeoPointInput.
+type GeoPointInput struct {
+	Type        *string   `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// GeoPoint é a representação Go do tipo GraphQL GeoPoint e do ponto GeoJSON armazenado no MongoDB.
+type GeoPoint struct {
+	Type        string    `json:"type" bson:"type"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
+// Point é uma posição geográfica em graus decimais.
+type Point struct {
+	Longitude float64
+	Latitude  float64
+}
+
+// FromInput valida o GeoPointInput e retorna o Point. O tipo, se informado, precisa ser Point
+// e as coordenadas precisam ser [longitude, latitude] dentro dos limites válidos.
+func FromInput(input *GeoPointInput) (Point, error) {
+	if input == nil {
+		return Point{}, api_error.New(api_error.CodeInvalidArgument, module, "point", "o ponto é obrigatório")
+	}
+	if input.Type != nil && *input.Type != TypePoint {
+		return Point{}, api_error.New(api_error.CodeInvalidArgument, module, "type", "o tipo GeoJSON precisa ser Point").
+			With("type", *input.Type)
+	}
+	if len(input.Coordinates) != 2 {
+		return Point{}, api_error.New(api_error.CodeInvalidArgument, module, "coordinates", "as coordenadas precisam ser [longitude, latitude]").
+			With("coordinates", input.Coordinates)
+	}
+	point := Point{Longitude: input.Coordinates[0], Latitude: input.Coordinates[1]}
+	return point, point.Validate()
+}
+
+// Validate verifica se a longitude está entre -180 e 180 e a latitude entre -90 e 90.
+func (p Point) Validate() error {
+	if math.IsNaN(p.Longitude) || p.Longitude < -180 || p.Longitude > 180 {
+		return api_error.New(api_error.CodeInvalidArgument, module, "longitude", "a longitude precisa estar entre -180 e 180").
+			With("longitude", p.Longitude)
+	}
+	if math.IsNaN(p.Latitude) || p.Latitude < -90 || p.Latitude > 90 {
+		return api_error.New(api_error.CodeInvalidArgument, module, "latitude", "a latitude precisa estar entre -90 e 90").
+			With("latitude", p.Latitude)
+	}
+	return nil
+}
+
+// GeoPoint retorna o ponto GeoJSON.
+func (p Point) GeoPoint() GeoPoint {
+	return GeoPoint{Type: TypePoint, Coordinates: []float64{p.Longitude, p.Latitude}}
+}
+
+// Haversine retorna a distância em metros entre os pontos pela fórmula de Haversine, considerando a Terra uma esfera.
+// O erro é de até 0,5% em relação ao elipsoide, suficiente para ordenações e estimativas.
+func Haversine(from Point, to Point) float64 {
+	lat1, lat2 := radians(from.Latitude), radians(to.Latitude)
+	deltaLat := lat2 - lat1
+	deltaLon := radians(to.Longitude - from.Longitude)
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// Vincenty retorna a distância em metros entre os pontos pela fórmula inversa de Vincenty sobre o elipsoide WGS-84,
+// com precisão milimétrica. Para pontos quase antípodas, em que o método não converge, utiliza Haversine.
+func Vincenty(from Point, to Point) float64 {
+	l := radians(to.Longitude - from.Longitude)
+	u1 := math.Atan((1 - wgs84F) * math.Tan(radians(from.Latitude)))
+	u2 := math.Atan((1 - wgs84F) * math.Tan(radians(to.Latitude)))
+	sinU1, cosU1 := math.Sin(u1), math.Cos(u1)
+	sinU2, cosU2 := math.Sin(u2), math.Cos(u2)
+
+	lambda := l
+	for iteration := 0; iteration < 200; iteration++ {
+		sinLambda, cosLambda := math.Sin(lambda), math.Cos(lambda)
+		sinSigma := math.Sqrt((cosU2*sinLambda)*(cosU2*sinLambda) +
+			(cosU1*sinU2-sinU1*cosU2*cosLambda)*(cosU1*sinU2-sinU1*cosU2*cosLambda))
+		if sinSigma == 0 {
+			return 0
+		}
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha := 1 - sinAlpha*sinAlpha
+		cos2SigmaM := 0.0
+		if cosSqAlpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cosSqAlpha
+		}
+		c := wgs84F / 16 * cosSqAlpha * (4 + wgs84F*(4-3*cosSqAlpha))
+
+		previous := lambda
+		lambda = l + (1-c)*wgs84F*sinAlpha*
+			(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-previous) > 1e-12 {
+			continue
+		}
+
+		uSq := cosSqAlpha * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+		a := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+		b := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+		deltaSigma := b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+			b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+		return wgs84B * a * (sigma - deltaSigma)
+	}
+	return Haversine(from, to)
+}
+
+// radians converte graus para radianos.
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// Profile é um perfil de velocidade para a estimativa da duração do percurso.
+type Profile struct {
+	// Name é o nome do perfil, por exemplo driving.
+	Name string
+
+	// Speed é a velocidade média em km/h.
+	Speed float64
+
+	// Detour é o fator aplicado à distância em linha reta para aproximar a distância percorrida. Se zero, utiliza 1.
+	Detour float64
+}
+
+// Perfis de velocidade padrão.
+var (
+	ProfileWalking = Profile{Name: "walking", Speed: 5, Detour: 1.2}
+	ProfileCycling = Profile{Name: "cycling", Speed: 15, Detour: 1.2}
+	ProfileDriving = Profile{Name: "driving", Speed: 40, Detour: 1.3}
+)
+
+// Duration retorna a duração estimada para percorrer a distância em metros. Retorna zero se a velocidade não for positiva.
+func (p Profile) Duration(meters float64) time.Duration {
+	if p.Speed <= 0 {
+		return 0
+	}
+	detour := p.Detour
+	if detour <= 0 {
+		detour = 1
+	}
+	seconds := meters * detour / (p.Speed * 1000 / 3600)
+	return time.Duration(math.Round(seconds)) * time.Second
+}
+
+// Estimate retorna a distância e a duração estimada entre os pontos, com o texto no idioma da requisição.
+// A distância é calculada por Vincenty.
+func Estimate(ctx context.Context, from Point, to Point, profile Profile) *params.Distance {
+	return Measure(ctx, Vincenty(from, to), profile)
+}
+
+// Measure retorna o Distance da distância em metros e a duração estimada pelo perfil, com o texto no idioma da requisição.
+func Measure(ctx context.Context, meters float64, profile Profile) *params.Distance {
+	locale := i18n.ForContext(ctx)
+	return &params.Distance{
+		Distance: FormatDistance(locale, meters),
+		Duration: FormatDuration(profile.Duration(meters)),
+	}
+}
+
+// FormatDistance retorna o TextValue da distância em metros: até 1 km em metros ("850 m")
+// e acima em quilômetros com uma casa decimal ("3,2 km"), com o separador decimal do idioma.
+func FormatDistance(locale string, meters float64) *params.TextValue {
+	if meters < 1000 {
+		return &params.TextValue{Text: strconv.FormatFloat(math.Round(meters), 'f', 0, 64) + " m", Value: meters}
+	}
+	kilometers := strconv.FormatFloat(math.Round(meters/100)/10, 'f', 1, 64)
+	return &params.TextValue{Text: decimal(locale, kilometers) + " km", Value: meters}
+}
+
+// FormatDuration retorna o TextValue da duração em segundos: "45 s", "12 min" ou "1 h 5 min".
+func FormatDuration(duration time.Duration) *params.TextValue {
+	seconds := duration.Seconds()
+	if duration < time.Minute {
+		return &params.TextValue{Text: strconv.Itoa(int(math.Round(seconds))) + " s", Value: seconds}
+	}
+	minutes := int(math.Round(duration.Minutes()))
+	if minutes < 60 {
+		return &params.TextValue{Text: strconv.Itoa(minutes) + " min", Value: seconds}
+	}
+	text := strconv.Itoa(minutes/60) + " h"
+	if minutes%60 > 0 {
+		text += " " + strconv.Itoa(minutes%60) + " min"
+	}
+	return &params.TextValue{Text: text, Value: seconds}
+}
+
+// decimal substitui o separador decimal pela vírgula, exceto nos idiomas en.
+func decimal(locale string, value string) string {
+	if locale == i18n.LocaleEn || strings.HasPrefix(locale, i18n.LocaleEn+"-") {
+		return value
+	}
+	return strings.Replace(value, ".", ",", 1)
+}
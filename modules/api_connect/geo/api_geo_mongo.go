@@ -0,0 +1,212 @@
+package geo
+
+import (
+	"context"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+)
+
+// DistanceField é o campo com a distância em metros adicionado aos documentos retornados por Near.
+const DistanceField = "_distance"
+
+// Result é um documento retornado pelas consultas geoespaciais, com a distância até o ponto de referência.
+type Result struct {
+	Document bson.M
+	Meters   float64
+	Distance *params.Distance
+}
+
+// NearQuery é uma consulta $geoNear pelos documentos mais próximos de um ponto.
+type NearQuery struct {
+	// Field é o campo com o ponto GeoJSON, indexado como 2dsphere (ex.: "address.location").
+	Field string
+
+	// Center é o ponto de referência.
+	Center Point
+
+	// MinDistance e MaxDistance limitam a distância em metros. Se zero, não há limite.
+	MinDistance float64
+	MaxDistance float64
+
+	// Filter é a condição adicional dos documentos, por exemplo as condições de filter.Compiler.Mongo.
+	Filter bson.D
+
+	// Limit é a quantidade máxima de documentos. Se zero, retorna todos.
+	Limit int64
+
+	// Profile é o perfil de velocidade da duração estimada.
+	Profile Profile
+}
+
+// WithinQuery é uma consulta $geoWithin pelos documentos dentro de um círculo ou polígono.
+type WithinQuery struct {
+	// Field é o campo com o ponto GeoJSON, indexado como 2dsphere.
+	Field string
+
+	// Center é o centro do círculo e o ponto de referência da distância retornada.
+	Center Point
+
+	// Radius é o raio do círculo em metros. Ignorado se Polygon for informado.
+	Radius float64
+
+	// Polygon são os vértices do polígono, sem a repetição do primeiro vértice.
+	Polygon []Point
+
+	// Filter é a condição adicional dos documentos.
+	Filter bson.D
+
+	// Profile é o perfil de velocidade da duração estimada.
+	Profile Profile
+}
+
+// EnsureIndex cria o índice 2dsphere do campo com o ponto GeoJSON.
+func EnsureIndex(ctx context.Context, collection *mongo.Collection, field string) error {
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: field, Value: "2dsphere"}}})
+	return err
+}
+
+// Mongo retorna o ponto GeoJSON no formato BSON.
+func Mongo(point Point) bson.D {
+	return bson.D{{Key: "type", Value: TypePoint}, {Key: "coordinates", Value: bson.A{point.Longitude, point.Latitude}}}
+}
+
+// Near executa a consulta $geoNear, retornando os documentos do mais próximo ao mais distante com a distância
+// calculada pelo MongoDB e a duração estimada pelo perfil. O campo precisa de um índice 2dsphere.
+func Near(ctx context.Context, collection *mongo.Collection, query NearQuery) ([]*Result, error) {
+	if err := query.Center.Validate(); err != nil {
+		return nil, err
+	}
+
+	stage := bson.D{
+		{Key: "near", Value: Mongo(query.Center)},
+		{Key: "distanceField", Value: DistanceField},
+		{Key: "key", Value: query.Field},
+		{Key: "spherical", Value: true},
+	}
+	if query.MinDistance > 0 {
+		stage = append(stage, bson.E{Key: "minDistance", Value: query.MinDistance})
+	}
+	if query.MaxDistance > 0 {
+		stage = append(stage, bson.E{Key: "maxDistance", Value: query.MaxDistance})
+	}
+	if len(query.Filter) > 0 {
+		stage = append(stage, bson.E{Key: "query", Value: query.Filter})
+	}
+	pipeline := mongo.Pipeline{{{Key: "$geoNear", Value: stage}}}
+	if query.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: query.Limit}})
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var documents []bson.M
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	results := make([]*Result, 0, len(documents))
+	for _, document := range documents {
+		meters, _ := document[DistanceField].(float64)
+		delete(document, DistanceField)
+		results = append(results, &Result{Document: document, Meters: meters, Distance: Measure(ctx, meters, query.Profile)})
+	}
+	return results, nil
+}
+
+// Within executa a consulta $geoWithin no círculo ($centerSphere) ou no polígono, retornando os documentos
+// com a distância até o centro calculada por Haversine, na ordem retornada pelo MongoDB.
+func Within(ctx context.Context, collection *mongo.Collection, query WithinQuery) ([]*Result, error) {
+	if err := query.Center.Validate(); err != nil {
+		return nil, err
+	}
+
+	var shape bson.D
+	switch {
+	case len(query.Polygon) >= 3:
+		ring := bson.A{}
+		for _, point := range query.Polygon {
+			if err := point.Validate(); err != nil {
+				return nil, err
+			}
+			ring = append(ring, bson.A{point.Longitude, point.Latitude})
+		}
+		ring = append(ring, bson.A{query.Polygon[0].Longitude, query.Polygon[0].Latitude})
+		shape = bson.D{{Key: "$geometry", Value: bson.D{{Key: "type", Value: "Polygon"}, {Key: "coordinates", Value: bson.A{ring}}}}}
+	case query.Radius > 0:
+		center := bson.A{query.Center.Longitude, query.Center.Latitude}
+		shape = bson.D{{Key: "$centerSphere", Value: bson.A{center, query.Radius / EarthRadius}}}
+	default:
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "within", "informe o raio ou um polígono com pelo menos 3 vértices")
+	}
+
+	where := bson.D{{Key: query.Field, Value: bson.D{{Key: "$geoWithin", Value: shape}}}}
+	if len(query.Filter) > 0 {
+		where = bson.D{{Key: "$and", Value: bson.A{where, query.Filter}}}
+	}
+	cursor, err := collection.Find(ctx, where)
+	if err != nil {
+		return nil, err
+	}
+	var documents []bson.M
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	results := make([]*Result, 0, len(documents))
+	for _, document := range documents {
+		item := &Result{Document: document}
+		if point, ok := pointAt(document, query.Field); ok {
+			item.Meters = Haversine(query.Center, point)
+			item.Distance = Measure(ctx, item.Meters, query.Profile)
+		}
+		results = append(results, item)
+	}
+	return results, nil
+}
+
+// pointAt retorna o ponto GeoJSON do campo com pontos (ex.: "address.location") do documento.
+func pointAt(document bson.M, path string) (Point, bool) {
+	var current interface{} = document
+	for _, key := range strings.Split(path, ".") {
+		switch item := current.(type) {
+		case bson.M:
+			current = item[key]
+		case bson.D:
+			current = item.Map()[key]
+		default:
+			return Point{}, false
+		}
+	}
+
+	var coordinates bson.A
+	switch item := current.(type) {
+	case bson.M:
+		coordinates, _ = item["coordinates"].(bson.A)
+	case bson.D:
+		coordinates, _ = item.Map()["coordinates"].(bson.A)
+	}
+	if len(coordinates) != 2 {
+		return Point{}, false
+	}
+	longitude, okLongitude := number(coordinates[0])
+	latitude, okLatitude := number(coordinates[1])
+	return Point{Longitude: longitude, Latitude: latitude}, okLongitude && okLatitude
+}
+
+// number converte os números BSON para float64.
+func number(value interface{}) (float64, bool) {
+	switch item := value.(type) {
+	case float64:
+		return item, true
+	case int32:
+		return float64(item), true
+	case int64:
+		return float64(item), true
+	}
+	return 0, false
+}
@@ -0,0 +1,192 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/i18n"
+	"math"
+	"testing"
+	"time"
+)
+
+// Cidades de referência, em [longitude, latitude].
+var (
+	paris        = Point{Longitude: 2.3522, Latitude: 48.8566}
+	london       = Point{Longitude: -0.1278, Latitude: 51.5074}
+	newYork      = Point{Longitude: -74.0060, Latitude: 40.7128}
+	sanFrancisco = Point{Longitude: -122.4194, Latitude: 37.7749}
+	losAngeles   = Point{Longitude: -118.2437, Latitude: 34.0522}
+)
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		name      string
+		from, to  Point
+		meters    float64
+		tolerance float64
+	}{
+		{"same point", paris, paris, 0, 0.001},
+		{"one degree on the equator", Point{0, 0}, Point{1, 0}, 111195.08, 0.01},
+		{"equator to the pole", Point{0, 0}, Point{0, 90}, 10007557.22, 0.01},
+		{"antipodes", Point{0, 0}, Point{180, 0}, math.Pi * EarthRadius, 0.01},
+		{"Paris to London", paris, london, 343556, 1},
+		{"New York to London", newYork, london, 5570230, 1},
+		{"San Francisco to Los Angeles", sanFrancisco, losAngeles, 559121, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Haversine(test.from, test.to); math.Abs(got-test.meters) > test.tolerance {
+				t.Errorf("Haversine = %.3f, want %.3f", got, test.meters)
+			}
+			if got, back := Haversine(test.from, test.to), Haversine(test.to, test.from); math.Abs(got-back) > 0.001 {
+				t.Errorf("Haversine is not symmetric: %.3f, %.3f", got, back)
+			}
+		})
+	}
+}
+
+func TestVincenty(t *testing.T) {
+	tests := []struct {
+		name      string
+		from, to  Point
+		meters    float64
+		tolerance float64
+	}{
+		{"same point", london, london, 0, 0.001},
+		// Exemplo do artigo de Vincenty (1975): Flinders Peak a Buninyong
+		{"Flinders Peak to Buninyong", Point{144.424867889, -37.951033417}, Point{143.926495528, -37.652821139}, 54972.271, 0.001},
+		{"one degree on the equator", Point{0, 0}, Point{1, 0}, 111319.491, 0.001},
+		{"meridian quadrant", Point{0, 0}, Point{0, 90}, 10001965.729, 0.001},
+		{"Paris to London", paris, london, 343923, 1},
+		{"New York to London", newYork, london, 5585234, 1},
+		{"San Francisco to Los Angeles", sanFrancisco, losAngeles, 559042, 1},
+		{"antipodes use Haversine", Point{0, 0}, Point{180, 0}, math.Pi * EarthRadius, 0.01},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Vincenty(test.from, test.to); math.Abs(got-test.meters) > test.tolerance {
+				t.Errorf("Vincenty = %.3f, want %.3f", got, test.meters)
+			}
+		})
+	}
+
+	// A diferença para Haversine é de até 0,5%
+	for _, pair := range [][2]Point{{paris, london}, {newYork, london}, {sanFrancisco, losAngeles}} {
+		vincenty, haversine := Vincenty(pair[0], pair[1]), Haversine(pair[0], pair[1])
+		if math.Abs(vincenty-haversine)/vincenty > 0.005 {
+			t.Errorf("Vincenty %.0f and Haversine %.0f differ by more than 0.5%%", vincenty, haversine)
+		}
+	}
+}
+
+func TestFromInput(t *testing.T) {
+	point, polygon := TypePoint, "Polygon"
+	tests := []struct {
+		name  string
+		input *GeoPointInput
+		want  Point
+		path  string
+	}{
+		{name: "point", input: &GeoPointInput{Type: &point, Coordinates: []float64{-46.6333, -23.5505}}, want: Point{-46.6333, -23.5505}},
+		{name: "without type", input: &GeoPointInput{Coordinates: []float64{180, -90}}, want: Point{180, -90}},
+		{name: "nil", input: nil, path: "point"},
+		{name: "polygon", input: &GeoPointInput{Type: &polygon, Coordinates: []float64{0, 0}}, path: "type"},
+		{name: "three coordinates", input: &GeoPointInput{Coordinates: []float64{0, 0, 0}}, path: "coordinates"},
+		{name: "longitude out of range", input: &GeoPointInput{Coordinates: []float64{-180.5, 0}}, path: "longitude"},
+		{name: "latitude out of range", input: &GeoPointInput{Coordinates: []float64{0, 90.1}}, path: "latitude"},
+		{name: "latitude first", input: &GeoPointInput{Coordinates: []float64{-23.5505, -146.6333}}, path: "latitude"},
+		{name: "NaN", input: &GeoPointInput{Coordinates: []float64{math.NaN(), 0}}, path: "longitude"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := FromInput(test.input)
+			if test.path == "" {
+				if err != nil || got != test.want {
+					t.Errorf("FromInput = %v, %v, want %v", got, err, test.want)
+				}
+				return
+			}
+			var apiError *api_error.ApiError
+			if !errors.As(err, &apiError) || apiError.Code != api_error.CodeInvalidArgument || apiError.Path != test.path {
+				t.Errorf("FromInput = %v, want INVALID_ARGUMENT at %s", err, test.path)
+			}
+		})
+	}
+}
+
+func TestFormatDistance(t *testing.T) {
+	tests := []struct {
+		locale string
+		meters float64
+		text   string
+	}{
+		{i18n.LocalePtBR, 0, "0 m"},
+		{i18n.LocalePtBR, 849.6, "850 m"},
+		{i18n.LocalePtBR, 999.4, "999 m"},
+		{i18n.LocalePtBR, 1000, "1,0 km"},
+		{i18n.LocalePtBR, 3249, "3,2 km"},
+		{i18n.LocalePtBR, 343923, "343,9 km"},
+		{i18n.LocaleEn, 3250, "3.3 km"},
+		{"en-US", 5585234, "5585.2 km"},
+		{"es", 1500, "1,5 km"},
+	}
+	for _, test := range tests {
+		got := FormatDistance(test.locale, test.meters)
+		if got.Text != test.text || got.Value != test.meters {
+			t.Errorf("FormatDistance(%s, %v) = %+v, want %q", test.locale, test.meters, got, test.text)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		text     string
+	}{
+		{0, "0 s"},
+		{45 * time.Second, "45 s"},
+		{time.Minute, "1 min"},
+		{12*time.Minute + 29*time.Second, "12 min"},
+		{59*time.Minute + 31*time.Second, "1 h"},
+		{time.Hour + 5*time.Minute, "1 h 5 min"},
+		{26 * time.Hour, "26 h"},
+	}
+	for _, test := range tests {
+		got := FormatDuration(test.duration)
+		if got.Text != test.text || got.Value != test.duration.Seconds() {
+			t.Errorf("FormatDuration(%s) = %+v, want %q", test.duration, got, test.text)
+		}
+	}
+}
+
+func TestProfileDuration(t *testing.T) {
+	tests := []struct {
+		profile Profile
+		meters  float64
+		want    time.Duration
+	}{
+		{ProfileWalking, 5000, 72 * time.Minute},
+		{ProfileCycling, 15000, 72 * time.Minute},
+		{ProfileDriving, 40000, 78 * time.Minute},
+		{Profile{Speed: 36}, 1000, 100 * time.Second},
+		{Profile{Speed: 0}, 1000, 0},
+	}
+	for _, test := range tests {
+		if got := test.profile.Duration(test.meters); got != test.want {
+			t.Errorf("%s Duration(%v) = %s, want %s", test.profile.Name, test.meters, got, test.want)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	distance := Estimate(i18n.WithLocale(context.Background(), i18n.LocaleEn), paris, london, ProfileDriving)
+	if distance.Distance.Text != "343.9 km" || distance.Duration.Text != "11 h 11 min" {
+		t.Errorf("Estimate = %+v %+v", distance.Distance, distance.Duration)
+	}
+
+	distance = Measure(context.Background(), 850, ProfileWalking)
+	if distance.Distance.Text != "850 m" || distance.Duration.Text != "12 min" {
+		t.Errorf("Measure = %+v %+v", distance.Distance, distance.Duration)
+	}
+}
@@ -0,0 +1,333 @@
+// Package i18n fornece o catálogo de mensagens localizadas (pt-BR / en) dos erros da ApiConnect.
+// As mensagens são indexadas pelo código do erro e podem interpolar as variáveis do ApiErrorType
+// utilizando a notação {variavel}, e a mensagem original do erro utilizando {message}.
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Idiomas suportados por padrão. Mensagens não traduzidas utilizam DefaultLocale.
+const (
+	LocalePtBR    = "pt-BR"
+	LocaleEn      = "en"
+	DefaultLocale = LocalePtBR
+)
+
+// A private key for context that only this package can access.
+var localeCtxKey = &contextKey{"locale"}
+
+type contextKey struct {
+	name string
+}
+
+// Catalog armazena os modelos de mensagem por idioma e código de erro.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+}
+
+// NewCatalog cria um catálogo vazio.
+func NewCatalog() *Catalog {
+	return &Catalog{messages: map[string]map[string]string{}}
+}
+
+// Register adiciona ou substitui as mensagens de um idioma.
+func (c *Catalog) Register(locale string, messages map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.messages[locale] == nil {
+		c.messages[locale] = map[string]string{}
+	}
+	for code, message := range messages {
+		c.messages[locale][code] = message
+	}
+}
+
+// Locales retorna os idiomas registrados no catálogo, em ordem alfabética.
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Translate retorna a mensagem do código no idioma informado, interpolando as variáveis.
+// Procura o idioma exato, depois o idioma base (en-US -> en) e por fim DefaultLocale.
+// O segundo retorno indica se alguma tradução foi encontrada.
+func (c *Catalog) Translate(locale, code string, variable map[string]interface{}) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if message, ok := c.template(locale, code); ok {
+		return interpolate(message, variable), true
+	}
+	return code, false
+}
+
+// template retorna o modelo da mensagem do código no idioma informado, idioma base ou DefaultLocale.
+func (c *Catalog) template(locale, code string) (string, bool) {
+	for _, candidate := range []string{locale, baseLocale(locale), DefaultLocale} {
+		if message, ok := c.messages[candidate][code]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// Check verifica se os códigos padrão de api_error e todos os códigos registrados possuem tradução
+// em todos os idiomas informados. Se nenhum idioma for informado, utiliza os idiomas registrados no catálogo.
+func (c *Catalog) Check(locales ...string) error {
+	if len(locales) == 0 {
+		locales = c.Locales()
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	codes := map[string]bool{}
+	for _, code := range api_error.Codes {
+		codes[code] = true
+	}
+	for _, messages := range c.messages {
+		for code := range messages {
+			codes[code] = true
+		}
+	}
+
+	var missing []string
+	for _, locale := range locales {
+		for code := range codes {
+			if _, ok := c.messages[locale][code]; !ok {
+				missing = append(missing, locale+":"+code)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("códigos de erro sem tradução: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Localize retorna uma cópia do erro com a mensagem traduzida para o idioma informado. A mensagem original
+// é interpolada em {message} ou, se o modelo não utiliza {message}, mantida após a mensagem traduzida.
+// Uma List retorna uma nova List com cada item traduzido. Erros que não são ApiError ou que não possuem
+// tradução são retornados sem alteração.
+func (c *Catalog) Localize(locale string, err error) error {
+	var list api_error.List
+	if errors.As(err, &list) {
+		result := make(api_error.List, len(list))
+		for index, item := range list {
+			if item != nil {
+				result[index] = c.localize(locale, item)
+			}
+		}
+		return result
+	}
+
+	var apiErr *api_error.ApiError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	if result := c.localize(locale, apiErr); result != apiErr {
+		return result
+	}
+	return err
+}
+
+// localize retorna uma cópia do ApiError com a mensagem traduzida, ou o próprio ApiError se não houver tradução.
+func (c *Catalog) localize(locale string, apiErr *api_error.ApiError) *api_error.ApiError {
+	variable := make(map[string]interface{}, len(apiErr.Variable)+1)
+	for key, value := range apiErr.Variable {
+		variable[key] = value
+	}
+	original := strings.Join(apiErr.Message, "; ")
+	variable["message"] = original
+
+	c.mu.RLock()
+	template, ok := c.template(locale, apiErr.Code)
+	c.mu.RUnlock()
+	if !ok {
+		return apiErr
+	}
+
+	result := *apiErr
+	result.Message = []string{interpolate(template, variable)}
+	if !strings.Contains(template, "{message}") && original != "" {
+		result.Message = append(result.Message, apiErr.Message...)
+	}
+	return &result
+}
+
+var defaultCatalog = newDefaultCatalog()
+
+// Default retorna o catálogo padrão, já preenchido com os códigos de api_error.
+func Default() *Catalog {
+	return defaultCatalog
+}
+
+// Localize traduz o erro para o idioma do contexto utilizando o catálogo padrão.
+func Localize(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	return defaultCatalog.Localize(ForContext(ctx), err)
+}
+
+// Middleware identifica o idioma a partir do cabeçalho Accept-Language e o armazena no contexto.
+func Middleware(supported ...string) func(http.Handler) http.Handler {
+	if len(supported) == 0 {
+		supported = []string{LocalePtBR, LocaleEn}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locale := ParseAcceptLanguage(r.Header.Get("Accept-Language"), supported)
+			if locale != "" {
+				r = r.WithContext(WithLocale(r.Context(), locale))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithLocale retorna um novo contexto com o idioma informado.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeCtxKey, locale)
+}
+
+// ForContext retorna o idioma da requisição: Accept-Language, idioma do usuário da sessão ou DefaultLocale.
+func ForContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeCtxKey).(string); ok && locale != "" {
+		return locale
+	}
+	if user, _ := middleware.ForContext(ctx); user.Locale != "" {
+		return user.Locale
+	}
+	return DefaultLocale
+}
+
+// ParseAcceptLanguage retorna o idioma suportado de maior prioridade do cabeçalho Accept-Language.
+// Retorna uma string vazia se nenhum idioma suportado for encontrado.
+func ParseAcceptLanguage(header string, supported []string) string {
+	bestLocale := ""
+	bestQuality := 0.0
+
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if _, err := fmt.Sscanf(param[2:], "%g", &quality); err != nil {
+					quality = 0
+				}
+			}
+		}
+
+		locale := matchLocale(tag, supported)
+		if locale != "" && quality > bestQuality {
+			bestLocale = locale
+			bestQuality = quality
+		}
+	}
+
+	return bestLocale
+}
+
+// matchLocale compara uma etiqueta de idioma com os idiomas suportados, considerando o idioma base.
+func matchLocale(tag string, supported []string) string {
+	for _, locale := range supported {
+		if strings.EqualFold(tag, locale) {
+			return locale
+		}
+	}
+	for _, locale := range supported {
+		if strings.EqualFold(baseLocale(tag), baseLocale(locale)) {
+			return locale
+		}
+	}
+	return ""
+}
+
+// baseLocale retorna o idioma base de uma etiqueta, por exemplo "en" para "en-US".
+func baseLocale(locale string) string {
+	if index := strings.IndexAny(locale, "-_"); index > 0 {
+		return locale[:index]
+	}
+	return locale
+}
+
+// interpolate substitui as variáveis {nome} do modelo pelos valores informados em uma única leitura do modelo,
+// de forma que os valores que contêm {nome} não são substituídos. Variáveis desconhecidas são mantidas.
+func interpolate(message string, variable map[string]interface{}) string {
+	var result strings.Builder
+	for {
+		start := strings.IndexByte(message, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(message[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		if value, ok := variable[message[start+1:end]]; ok {
+			result.WriteString(message[:start])
+			result.WriteString(fmt.Sprint(value))
+			message = message[end+1:]
+		} else {
+			result.WriteString(message[:start+1])
+			message = message[start+1:]
+		}
+	}
+	result.WriteString(message)
+	return result.String()
+}
+
+// newDefaultCatalog cria o catálogo com as mensagens dos códigos padrão de api_error.
+func newDefaultCatalog() *Catalog {
+	catalog := NewCatalog()
+	catalog.Register(LocalePtBR, map[string]string{
+		api_error.CodeInternal:           "Erro interno do servidor",
+		api_error.CodeInvalidArgument:    "Argumento inválido",
+		api_error.CodeNotFound:           "Documento não encontrado",
+		api_error.CodeAlreadyExists:      "Documento já existe",
+		api_error.CodeConflict:           "O documento foi alterado por outra operação",
+		api_error.CodeFailedPrecondition: "O documento não está no estado exigido pela operação",
+		api_error.CodeUnauthenticated:    "Usuário não autenticado",
+		api_error.CodePermissionDenied:   "Permissão negada",
+	})
+	catalog.Register(LocaleEn, map[string]string{
+		api_error.CodeInternal:           "Internal server error",
+		api_error.CodeInvalidArgument:    "Invalid argument",
+		api_error.CodeNotFound:           "Document not found",
+		api_error.CodeAlreadyExists:      "Document already exists",
+		api_error.CodeConflict:           "The document was changed by another operation",
+		api_error.CodeFailedPrecondition: "The document is not in the state required by the operation",
+		api_error.CodeUnauthenticated:    "User not authenticated",
+		api_error.CodePermissionDenied:   "Permission denied",
+	})
+	return catalog
+}
@@ -0,0 +1,183 @@
+package i18n
+
+import (
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultCatalogCheck(t *testing.T) {
+	if err := Default().Check(LocalePtBR, LocaleEn); err != nil {
+		t.Fatalf("Check = %v", err)
+	}
+}
+
+func TestCheckMissingTranslation(t *testing.T) {
+	tests := []struct {
+		name    string
+		catalog func() *Catalog
+		missing string
+	}{
+		{
+			name: "code registered in another locale",
+			catalog: func() *Catalog {
+				catalog := newDefaultCatalog()
+				catalog.Register(LocalePtBR, map[string]string{"QUOTA_EXCEEDED": "Cota excedida"})
+				return catalog
+			},
+			missing: "en:QUOTA_EXCEEDED",
+		},
+		{
+			name: "api_error code without any translation",
+			catalog: func() *Catalog {
+				catalog := NewCatalog()
+				catalog.Register(LocalePtBR, map[string]string{api_error.CodeInternal: "Erro interno do servidor"})
+				return catalog
+			},
+			missing: LocalePtBR + ":" + api_error.CodeNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.catalog().Check()
+			if err == nil {
+				t.Fatal("Check = nil, want missing translation")
+			}
+			if !strings.Contains(err.Error(), test.missing) {
+				t.Errorf("Check = %v, want %s", err, test.missing)
+			}
+		})
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	catalog := newDefaultCatalog()
+	catalog.Register(LocaleEn, map[string]string{"QUOTA_EXCEEDED": "Quota of {limit} exceeded: {message}"})
+
+	tests := []struct {
+		name    string
+		locale  string
+		err     error
+		message []string
+	}{
+		{
+			name:    "keeps the original message",
+			locale:  "en-US",
+			err:     api_error.New(api_error.CodeNotFound, "app/account", "read", "conta não encontrada"),
+			message: []string{"Document not found", "conta não encontrada"},
+		},
+		{
+			name:    "without original message",
+			locale:  LocalePtBR,
+			err:     api_error.New(api_error.CodePermissionDenied, "app/account", "read"),
+			message: []string{"Permissão negada"},
+		},
+		{
+			name:    "interpolates message and variables",
+			locale:  LocaleEn,
+			err:     api_error.New("QUOTA_EXCEEDED", "app/account", "create", "limite do plano").With("limit", 10),
+			message: []string{"Quota of 10 exceeded: limite do plano"},
+		},
+		{
+			name:    "falls back to the default locale",
+			locale:  "fr",
+			err:     api_error.New(api_error.CodeConflict, "app/account", "update"),
+			message: []string{"O documento foi alterado por outra operação"},
+		},
+		{
+			name:    "without translation",
+			locale:  LocaleEn,
+			err:     api_error.New("UNKNOWN", "app/account", "read", "falha"),
+			message: []string{"falha"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var apiErr *api_error.ApiError
+			if !errors.As(catalog.Localize(test.locale, test.err), &apiErr) {
+				t.Fatal("Localize did not return an ApiError")
+			}
+			if !reflect.DeepEqual(apiErr.Message, test.message) {
+				t.Errorf("Message = %q, want %q", apiErr.Message, test.message)
+			}
+		})
+	}
+
+	original := api_error.New("QUOTA_EXCEEDED", "app/account", "create").With("limit", 10)
+	catalog.Localize(LocaleEn, original)
+	if _, ok := original.Variable["message"]; ok {
+		t.Error("Localize changed the variables of the original error")
+	}
+}
+
+func TestLocalizeList(t *testing.T) {
+	list := api_error.List{
+		api_error.New(api_error.CodeNotFound, "app/account", "read"),
+		nil,
+		api_error.New("UNKNOWN", "app/account", "read", "falha"),
+		api_error.New(api_error.CodeInvalidArgument, "app/account", "create", "email inválido").With("field", "email"),
+	}
+
+	var result api_error.List
+	if !errors.As(Default().Localize(LocaleEn, list), &result) {
+		t.Fatal("Localize did not return a List")
+	}
+	want := [][]string{{"Document not found"}, nil, {"falha"}, {"Invalid argument", "email inválido"}}
+	if len(result) != len(want) {
+		t.Fatalf("Localize returned %d items, want %d", len(result), len(want))
+	}
+	for index, item := range result {
+		if item == nil {
+			if want[index] != nil {
+				t.Errorf("item %d = nil", index)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(item.Message, want[index]) {
+			t.Errorf("item %d = %q, want %q", index, item.Message, want[index])
+		}
+	}
+	if result[3].Variable["field"] != "email" || list[0].Message != nil {
+		t.Errorf("Localize changed the original List or lost the variables")
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	tests := []struct {
+		template string
+		variable map[string]interface{}
+		want     string
+	}{
+		{"{a} e {b}", map[string]interface{}{"a": "{b}", "b": "{a}"}, "{b} e {a}"},
+		{"{a}{a} {unknown}", map[string]interface{}{"a": 1}, "11 {unknown}"},
+		{"{{a}} {a", map[string]interface{}{"a": "x"}, "{x} {a"},
+		{"sem variáveis", nil, "sem variáveis"},
+	}
+	for _, test := range tests {
+		for attempt := 0; attempt < 20; attempt++ {
+			if got := interpolate(test.template, test.variable); got != test.want {
+				t.Fatalf("interpolate(%q) = %q, want %q", test.template, got, test.want)
+			}
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"en-US,en;q=0.9,pt-BR;q=0.8", LocaleEn},
+		{"pt-BR;q=0.5,en;q=0.4", LocalePtBR},
+		{"pt", LocalePtBR},
+		{"fr-FR", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := ParseAcceptLanguage(test.header, []string{LocalePtBR, LocaleEn}); got != test.want {
+			t.Errorf("ParseAcceptLanguage(%q) = %q, want %q", test.header, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Package info mantém os metadados ApiInfo dos documentos nas operações de escrita do MongoDB e do MySQL:
+// datas de criação e alteração, versão, dono do documento e as últimas interações (ApiHistory).
+package info
+
+import (
+	"context"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/diff"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const module = "api_connect/info"
+
+// HistoryLimit é a quantidade de interações mantidas em ApiInfo.history.
+const HistoryLimit = 10
+
+// Field é o campo do MongoDB onde o ApiInfo é armazenado.
+const Field = "_info"
+
+// Colunas do MySQL onde o ApiInfo é armazenado.
+const (
+	ColumnCreatedAt = "created_at"
+	ColumnChangedAt = "changed_at"
+	ColumnOwner     = "owner"
+	ColumnVersion   = "version"
+	ColumnChecksum  = "checksum"
+	ColumnHistory   = "history"
+)
+
+// Action identifica a ação realizada no documento.
+type Action struct {
+	// Name é o nome da ação, por exemplo "accountCreate".
+	Name string
+
+	// Log é o identificador da ação no log da aplicação. Se vazio, um novo identificador é gerado.
+	Log string
+}
+
+// NewAction cria uma ação com um novo identificador de log.
+func NewAction(name string) Action {
+	return Action{Name: name, Log: NewLog()}
+}
+
+// NewLog gera um novo identificador de log.
+func NewLog() string {
+	return primitive.NewObjectID().Hex()
+}
+
+// log retorna o identificador de log da ação, gerando-o se necessário.
+func (a *Action) log() string {
+	if a.Log == "" {
+		a.Log = NewLog()
+	}
+	return a.Log
+}
+
+// Owner retorna o identificador do usuário da sessão, ou nil se não houver usuário.
+func Owner(ctx context.Context) *string {
+	user, ok := middleware.ForContext(ctx)
+	if !ok {
+		return nil
+	}
+	owner := user.ID
+	return &owner
+}
+
+// Insert retorna o ApiInfo de um novo documento: versão 1, dono da sessão e a primeira interação.
+func Insert(ctx context.Context, action Action) *params.ApiInfo {
+	now := stamp()
+	version := 1
+	return &params.ApiInfo{
+		CreatedAt: &now,
+		ChangedAt: &now,
+		Owner:     Owner(ctx),
+		Version:   &version,
+		History:   []*params.ApiHistory{history(now, action)},
+	}
+}
+
+// Update retorna o ApiInfo após a alteração de um documento: data de alteração, versão incrementada
+// e a interação adicionada ao histórico, limitado às HistoryLimit mais recentes.
+func Update(current *params.ApiInfo, action Action) *params.ApiInfo {
+	now := stamp()
+	next := params.ApiInfo{ChangedAt: &now, CreatedAt: &now}
+
+	version := 1
+	if current != nil {
+		next.CreatedAt = current.CreatedAt
+		next.Owner = current.Owner
+		next.Checksum = current.Checksum
+		next.History = current.History
+		if current.Version != nil {
+			version = *current.Version + 1
+		}
+	}
+	next.Version = &version
+	next.History = Push(next.History, history(now, action))
+	return &next
+}
+
+// Push adiciona a interação ao histórico, mantendo somente as HistoryLimit mais recentes.
+func Push(items []*params.ApiHistory, item *params.ApiHistory) []*params.ApiHistory {
+	result := make([]*params.ApiHistory, 0, HistoryLimit)
+	result = append(result, items...)
+	result = append(result, item)
+	if len(result) > HistoryLimit {
+		result = result[len(result)-HistoryLimit:]
+	}
+	return result
+}
+
+// track adiciona à última interação do histórico os caminhos dos campos alterados.
+// Os valores alterados não são armazenados no documento, somente no log da aplicação.
+func track(info *params.ApiInfo, changes *params.ApiChangeDiff) {
+	if count := len(info.History); count > 0 {
+		info.History[count-1].Changes = diff.Paths(changes)
+	}
+}
+
+// stamp retorna a data atual em UTC com a precisão de milissegundos armazenada pelo MongoDB.
+func stamp() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// history cria uma interação com o nome e o identificador de log da ação.
+func history(createdAt time.Time, action Action) *params.ApiHistory {
+	log := action.log()
+	item := &params.ApiHistory{CreatedAt: &createdAt, Log: &log}
+	if action.Name != "" {
+		name := action.Name
+		item.Action = &name
+	}
+	return item
+}
+
+// idString converte o identificador do documento para texto.
+func idString(id interface{}) *string {
+	var value string
+	switch item := id.(type) {
+	case nil:
+		return nil
+	case primitive.ObjectID:
+		value = item.Hex()
+	case string:
+		value = item
+	default:
+		value = fmt.Sprint(item)
+	}
+	return &value
+}
@@ -0,0 +1,263 @@
+package info
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/security"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Motivos de falha na verificação de integridade.
+const (
+	ViolationMissing = "MISSING"
+	ViolationContent = "CONTENT"
+	ViolationHistory = "HISTORY"
+)
+
+// historyTimeLayout é o formato das datas do histórico no encadeamento, com precisão de milissegundos.
+const historyTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+var (
+	secretMu     sync.RWMutex
+	secretKey    []byte
+	secretLoaded bool
+)
+
+// SetSecret define a chave do HMAC-SHA256 do checksum do documento e da cadeia do histórico.
+// Sem chave, utiliza a variável de ambiente API_CHECKSUM_SECRET.
+func SetSecret(secret []byte) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	secretKey = secret
+	secretLoaded = true
+}
+
+// secret retorna a chave do HMAC, lida uma vez da variável de ambiente API_CHECKSUM_SECRET. Sem chave, o checksum
+// detecta somente alterações acidentais, pois qualquer pessoa com acesso ao banco de dados pode recalculá-lo.
+func secret() []byte {
+	secretMu.RLock()
+	if secretLoaded {
+		defer secretMu.RUnlock()
+		return secretKey
+	}
+	secretMu.RUnlock()
+
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	if !secretLoaded {
+		secretKey = []byte(os.Getenv("API_CHECKSUM_SECRET"))
+		secretLoaded = true
+		if len(secretKey) == 0 {
+			log.Printf("API_CHECKSUM_SECRET não definido: o checksum do ApiInfo não detecta alterações feitas diretamente no banco de dados")
+		}
+	}
+	return secretKey
+}
+
+// sign retorna o HMAC-SHA256 do valor com a chave do servidor, em hexadecimal.
+func sign(value string) string {
+	mac := hmac.New(sha256.New, secret())
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Violation é uma falha de integridade encontrada em um documento.
+type Violation struct {
+	// ID é o identificador do documento.
+	ID string
+
+	// Reason é o motivo da falha: ViolationMissing, ViolationContent ou ViolationHistory.
+	Reason string
+
+	// Index é a posição da interação do histórico com falha, ou -1 quando a falha não é do histórico.
+	Index int
+}
+
+// Canonical retorna a serialização canônica dos valores de um documento: chaves em ordem alfabética,
+// valores escalares como texto, datas em UTC com precisão de segundos e ObjectID em hexadecimal.
+// A representação é a mesma para valores lidos do MongoDB e do MySQL.
+func Canonical(values map[string]interface{}) (string, error) {
+	normalized, err := canonicalValue(values)
+	if err != nil {
+		return "", err
+	}
+	content, err := json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// canonicalValue converte o valor para mapas, listas, textos e nil.
+func canonicalValue(value interface{}) (interface{}, error) {
+	switch item := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return item, nil
+	case []byte:
+		return string(item), nil
+	case bool:
+		return fmt.Sprint(item), nil
+	case time.Time:
+		return item.UTC().Truncate(time.Second).Format(time.RFC3339), nil
+	case *time.Time:
+		if item == nil {
+			return nil, nil
+		}
+		return canonicalValue(*item)
+	case primitive.DateTime:
+		return canonicalValue(item.Time())
+	case primitive.ObjectID:
+		return item.Hex(), nil
+	case primitive.Decimal128:
+		return item.String(), nil
+	case bson.D:
+		result := make(map[string]interface{}, len(item))
+		for _, element := range item {
+			result[element.Key] = element.Value
+		}
+		return canonicalValue(result)
+	case bson.M:
+		return canonicalValue(map[string]interface{}(item))
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(item))
+		for key, entry := range item {
+			normalized, err := canonicalValue(entry)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = normalized
+		}
+		return result, nil
+	case bson.A:
+		return canonicalValue([]interface{}(item))
+	case []interface{}:
+		result := make([]interface{}, len(item))
+		for index, entry := range item {
+			normalized, err := canonicalValue(entry)
+			if err != nil {
+				return nil, err
+			}
+			result[index] = normalized
+		}
+		return result, nil
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
+		return fmt.Sprint(value), nil
+	}
+
+	// Estruturas, ponteiros e demais tipos são convertidos pela representação BSON
+	raw, err := bson.Marshal(bson.M{"v": value})
+	if err != nil {
+		return nil, err
+	}
+	var document bson.M
+	if err = bson.Unmarshal(raw, &document); err != nil {
+		return nil, err
+	}
+	return canonicalValue(document["v"])
+}
+
+// Checksum retorna o SHA-256 da serialização canônica dos valores do documento.
+func Checksum(values map[string]interface{}) (string, error) {
+	content, err := Canonical(values)
+	if err != nil {
+		return "", err
+	}
+	return security.Sha256(content), nil
+}
+
+// chain retorna o checksum da interação, encadeado ao checksum da interação anterior: o HMAC do checksum anterior,
+// do log, da data, da ação e dos campos alterados.
+func chain(previous string, item *params.ApiHistory) string {
+	var logID, createdAt, action string
+	if item.Log != nil {
+		logID = *item.Log
+	}
+	if item.CreatedAt != nil {
+		createdAt = item.CreatedAt.UTC().Format(historyTimeLayout)
+	}
+	if item.Action != nil {
+		action = *item.Action
+	}
+	changes, _ := json.Marshal(item.Changes)
+	return sign(strings.Join([]string{previous, logID, createdAt, action, string(changes)}, "|"))
+}
+
+// seal calcula o checksum da última interação do histórico e o checksum do documento,
+// que vincula o conteúdo ao final da cadeia do histórico.
+func seal(info *params.ApiInfo, values map[string]interface{}) error {
+	content, err := Checksum(values)
+	if err != nil {
+		return err
+	}
+
+	var tip string
+	if count := len(info.History); count > 0 {
+		var previous string
+		if count > 1 && info.History[count-2].Checksum != nil {
+			previous = *info.History[count-2].Checksum
+		}
+		tip = chain(previous, info.History[count-1])
+		info.History[count-1].Checksum = &tip
+	}
+
+	checksum := sign(content + "|" + tip)
+	info.Checksum = &checksum
+	return nil
+}
+
+// Verify verifica o conteúdo e a cadeia do histórico de um documento.
+// Os valores não devem conter o identificador nem os campos do ApiInfo.
+func Verify(id string, values map[string]interface{}, info *params.ApiInfo) ([]Violation, error) {
+	if info == nil || info.Checksum == nil {
+		return []Violation{{ID: id, Reason: ViolationMissing, Index: -1}}, nil
+	}
+
+	var violations []Violation
+
+	// O primeiro item só pode ser verificado enquanto o histórico não foi truncado
+	start := 1
+	if info.Version != nil && *info.Version == len(info.History) {
+		start = 0
+	}
+	for index := start; index < len(info.History); index++ {
+		var previous string
+		if index > 0 && info.History[index-1].Checksum != nil {
+			previous = *info.History[index-1].Checksum
+		}
+		item := info.History[index]
+		if item.Checksum == nil || !hmac.Equal([]byte(*item.Checksum), []byte(chain(previous, item))) {
+			violations = append(violations, Violation{ID: id, Reason: ViolationHistory, Index: index})
+		}
+	}
+
+	content, err := Checksum(values)
+	if err != nil {
+		return nil, err
+	}
+	var tip string
+	if count := len(info.History); count > 0 && info.History[count-1].Checksum != nil {
+		tip = *info.History[count-1].Checksum
+	}
+	if !hmac.Equal([]byte(*info.Checksum), []byte(sign(content+"|"+tip))) {
+		violations = append(violations, Violation{ID: id, Reason: ViolationContent, Index: -1})
+	}
+	return violations, nil
+}
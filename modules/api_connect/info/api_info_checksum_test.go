@@ -0,0 +1,196 @@
+package info
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// document cria os valores e o ApiInfo selado de um documento com a quantidade informada de alterações.
+func document(t *testing.T, updates int) (map[string]interface{}, *params.ApiInfo) {
+	t.Helper()
+	values := map[string]interface{}{"name": "Ana", "age": 30}
+	info := Insert(context.Background(), NewAction("accountCreate"))
+	if err := seal(info, values); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	for index := 0; index < updates; index++ {
+		values = map[string]interface{}{"name": "Ana", "age": 31 + index}
+		info = Update(info, NewAction("accountUpdate"))
+		track(info, &params.ApiChangeDiff{Patch: []*params.ApiPatchOperation{{Op: "replace", Path: "/age"}}})
+		if err := seal(info, values); err != nil {
+			t.Fatalf("seal: %v", err)
+		}
+	}
+	return values, info
+}
+
+func TestCanonical(t *testing.T) {
+	date := time.Date(2024, 5, 1, 12, 30, 15, 0, time.UTC)
+	first := map[string]interface{}{
+		"name":    "Ana",
+		"age":     int32(30),
+		"active":  true,
+		"date":    date.Add(250 * time.Millisecond),
+		"id":      primitive.ObjectID{1},
+		"address": bson.D{{Key: "zip", Value: "50000"}, {Key: "city", Value: "Recife"}},
+		"tags":    bson.A{"a", int64(2)},
+	}
+	second := map[string]interface{}{
+		"tags":    []interface{}{"a", "2"},
+		"address": map[string]interface{}{"city": "Recife", "zip": "50000"},
+		"id":      primitive.ObjectID{1}.Hex(),
+		"date":    primitive.NewDateTimeFromTime(date.In(time.FixedZone("BRT", -3*3600))),
+		"active":  "true",
+		"age":     int64(30),
+		"name":    "Ana",
+	}
+
+	want, err := Checksum(first)
+	if err != nil {
+		t.Fatalf("Checksum: %v", err)
+	}
+	if got, _ := Checksum(second); got != want {
+		t.Errorf("Checksum differs for equivalent MongoDB and MySQL values")
+	}
+	if got, _ := Checksum(map[string]interface{}{"name": "Ana"}); got == want {
+		t.Errorf("Checksum equal for different values")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	SetSecret([]byte("test secret"))
+
+	tests := []struct {
+		name    string
+		updates int
+		tamper  func(values map[string]interface{}, info *params.ApiInfo)
+		want    []Violation
+	}{
+		{
+			name:    "valid",
+			updates: 3,
+		},
+		{
+			name:    "valid after the history is truncated",
+			updates: HistoryLimit + 2,
+		},
+		{
+			name:    "content",
+			updates: 3,
+			tamper:  func(values map[string]interface{}, info *params.ApiInfo) { values["age"] = 99 },
+			want:    []Violation{{ID: "1", Reason: ViolationContent, Index: -1}},
+		},
+		{
+			name:    "action",
+			updates: 3,
+			tamper: func(values map[string]interface{}, info *params.ApiInfo) {
+				action := "accountDelete"
+				info.History[1].Action = &action
+			},
+			want: []Violation{{ID: "1", Reason: ViolationHistory, Index: 1}},
+		},
+		{
+			name:    "changes",
+			updates: 3,
+			tamper:  func(values map[string]interface{}, info *params.ApiInfo) { info.History[2].Changes = []string{"name"} },
+			want:    []Violation{{ID: "1", Reason: ViolationHistory, Index: 2}},
+		},
+		{
+			name:    "log",
+			updates: 3,
+			tamper: func(values map[string]interface{}, info *params.ApiInfo) {
+				log := NewLog()
+				info.History[0].Log = &log
+			},
+			want: []Violation{{ID: "1", Reason: ViolationHistory, Index: 0}},
+		},
+		{
+			name:    "removed interaction",
+			updates: 3,
+			tamper: func(values map[string]interface{}, info *params.ApiInfo) {
+				info.History = append(info.History[:1], info.History[2:]...)
+			},
+			want: []Violation{{ID: "1", Reason: ViolationHistory, Index: 1}},
+		},
+		{
+			name:    "recomputed content checksum",
+			updates: 1,
+			tamper: func(values map[string]interface{}, info *params.ApiInfo) {
+				values["age"] = 99
+				checksum, _ := Checksum(values)
+				info.Checksum = &checksum
+			},
+			want: []Violation{{ID: "1", Reason: ViolationContent, Index: -1}},
+		},
+		{
+			name:    "missing checksum",
+			updates: 1,
+			tamper:  func(values map[string]interface{}, info *params.ApiInfo) { info.Checksum = nil },
+			want:    []Violation{{ID: "1", Reason: ViolationMissing, Index: -1}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values, info := document(t, test.updates)
+			if test.tamper != nil {
+				test.tamper(values, info)
+			}
+			violations, err := Verify("1", values, info)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if !reflect.DeepEqual(violations, test.want) {
+				t.Errorf("Verify = %+v, want %+v", violations, test.want)
+			}
+		})
+	}
+}
+
+func TestVerifyTruncatedHistory(t *testing.T) {
+	SetSecret([]byte("test secret"))
+
+	values, info := document(t, HistoryLimit+2)
+	if len(info.History) != HistoryLimit || *info.Version != HistoryLimit+3 {
+		t.Fatalf("history %d version %d, want %d %d", len(info.History), *info.Version, HistoryLimit, HistoryLimit+3)
+	}
+
+	// A primeira interação mantida é encadeada a uma interação removida e não pode ser verificada
+	action := "accountDelete"
+	info.History[0].Action = &action
+	if violations, err := Verify("1", values, info); err != nil || len(violations) != 0 {
+		t.Errorf("Verify = %+v, %v, want no violations", violations, err)
+	}
+
+	info.History[1].Action = &action
+	want := []Violation{{ID: "1", Reason: ViolationHistory, Index: 1}}
+	if violations, _ := Verify("1", values, info); !reflect.DeepEqual(violations, want) {
+		t.Errorf("Verify = %+v, want %+v", violations, want)
+	}
+}
+
+func TestVerifySecret(t *testing.T) {
+	SetSecret([]byte("test secret"))
+	values, info := document(t, 2)
+
+	SetSecret([]byte("other secret"))
+	defer SetSecret([]byte("test secret"))
+
+	violations, err := Verify("1", values, info)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	want := []Violation{
+		{ID: "1", Reason: ViolationHistory, Index: 0},
+		{ID: "1", Reason: ViolationHistory, Index: 1},
+		{ID: "1", Reason: ViolationHistory, Index: 2},
+		{ID: "1", Reason: ViolationContent, Index: -1},
+	}
+	if !reflect.DeepEqual(violations, want) {
+		t.Errorf("Verify with another secret = %+v, want %+v", violations, want)
+	}
+}
@@ -0,0 +1,279 @@
+package info
+
+import (
+	"context"
+	"errors"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/diff"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+)
+
+// MongoWriter grava documentos em uma coleção do MongoDB mantendo o campo _info.
+type MongoWriter struct {
+	collection *mongo.Collection
+}
+
+// NewMongoWriter cria um MongoWriter para a coleção informada.
+func NewMongoWriter(collection *mongo.Collection) *MongoWriter {
+	return &MongoWriter{collection: collection}
+}
+
+// InsertOne insere o documento com o ApiInfo inicial e o checksum do conteúdo.
+func (w *MongoWriter) InsertOne(ctx context.Context, values bson.M, action Action) (*params.ApiChangeResult, error) {
+	if err := checkMongoValues(values, "insertOne"); err != nil {
+		return nil, err
+	}
+
+	document := bson.M{}
+	for key, value := range values {
+		document[key] = value
+	}
+
+	info := Insert(ctx, action)
+	if err := seal(info, mongoContent(document)); err != nil {
+		return nil, err
+	}
+	document[Field] = info
+
+	result, err := w.collection.InsertOne(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+	return &params.ApiChangeResult{ID: idString(result.InsertedID), Info: info, Values: values}, nil
+}
+
+// UpdateByID altera os valores do documento e atualiza o _info: data de alteração, versão incrementada,
+// a interação adicionada ao histórico e o checksum do novo conteúdo.
+// Se a versão esperada for informada, a alteração é condicional e retorna um erro CodeConflict
+// com a versão atual quando o documento foi alterado por outra operação. Sem a versão esperada,
+// a alteração é repetida automaticamente em caso de conflito.
+func (w *MongoWriter) UpdateByID(ctx context.Context, id interface{}, expected *params.ApiVersionInput, values bson.M, action Action) (*params.ApiChangeResult, error) {
+	if err := checkMongoValues(values, "updateByID"); err != nil {
+		return nil, err
+	}
+	return w.retry(ctx, id, expected, values, nil, action)
+}
+
+// Undo desfaz as alterações de um ApiChangeDiff, restaurando os valores anteriores e removendo os campos adicionados.
+// A alteração inversa é registrada como uma nova interação do documento.
+func (w *MongoWriter) Undo(ctx context.Context, id interface{}, expected *params.ApiVersionInput, changes *params.ApiChangeDiff, action Action) (*params.ApiChangeResult, error) {
+	set, unset := diff.Undo(changes)
+	values := bson.M(set)
+	if err := checkMongoValues(values, "undo"); err != nil {
+		return nil, err
+	}
+	for _, path := range unset {
+		if err := checkMongoValues(bson.M{path: nil}, "undo"); err != nil {
+			return nil, err
+		}
+	}
+	return w.retry(ctx, id, expected, values, unset, action)
+}
+
+// retry executa a alteração uma vez, se a versão esperada for informada, ou repete-a em caso de conflito.
+func (w *MongoWriter) retry(ctx context.Context, id interface{}, expected *params.ApiVersionInput, values bson.M, unset []string, action Action) (*params.ApiChangeResult, error) {
+	if expected != nil {
+		return w.update(ctx, id, expected, values, unset, action)
+	}
+
+	var result *params.ApiChangeResult
+	err := Retry(ctx, 0, 0, func(ctx context.Context) error {
+		var err error
+		result, err = w.update(ctx, id, nil, values, unset, action)
+		return err
+	})
+	return result, err
+}
+
+// update lê o documento, aplica os valores, calcula os campos alterados e grava condicionado à versão lida.
+func (w *MongoWriter) update(ctx context.Context, id interface{}, expected *params.ApiVersionInput, values bson.M, unset []string, action Action) (*params.ApiChangeResult, error) {
+	var document bson.M
+	err := w.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&document)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, api_error.New(api_error.CodeNotFound, module, "updateByID", "documento não encontrado").
+			With("id", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := mongoInfo(document)
+	if err != nil {
+		return nil, err
+	}
+	if expected != nil && (current == nil || current.Version == nil || *current.Version != expected.Expected) {
+		return nil, conflict("updateByID", id, expected, current)
+	}
+
+	content := mongoContent(document)
+	for key, value := range values {
+		if err = setPath(content, key, value); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range unset {
+		unsetPath(content, path)
+	}
+	changes := diff.Compute(mongoContent(document), content)
+
+	info := Update(current, action)
+	track(info, changes)
+	if err = seal(info, content); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"_id": id, Field + ".version": bson.M{"$exists": false}}
+	if current != nil && current.Version != nil {
+		filter[Field+".version"] = *current.Version
+	}
+	set := bson.M{Field: info}
+	for key, value := range values {
+		set[key] = value
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		fields := bson.M{}
+		for _, path := range unset {
+			fields[path] = ""
+		}
+		update["$unset"] = fields
+	}
+
+	result, err := w.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, conflict("updateByID", id, expected, current)
+	}
+	return &params.ApiChangeResult{ID: idString(id), Info: info, Values: values, Diff: changes}, nil
+}
+
+// Verify percorre a coleção e retorna os documentos cujo conteúdo ou histórico foi alterado fora da API,
+// além da quantidade de documentos verificados.
+func (w *MongoWriter) Verify(ctx context.Context) ([]Violation, int, error) {
+	cursor, err := w.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var violations []Violation
+	count := 0
+	for cursor.Next(ctx) {
+		var document bson.M
+		if err = cursor.Decode(&document); err != nil {
+			return nil, count, err
+		}
+		count++
+
+		info, err := mongoInfo(document)
+		if err != nil {
+			return nil, count, err
+		}
+		found, err := Verify(*idString(document["_id"]), mongoContent(document), info)
+		if err != nil {
+			return nil, count, err
+		}
+		violations = append(violations, found...)
+	}
+	return violations, count, cursor.Err()
+}
+
+// mongoInfo converte o campo _info do documento para ApiInfo.
+func mongoInfo(document bson.M) (*params.ApiInfo, error) {
+	value, ok := document[Field]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	raw, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var info params.ApiInfo
+	if err = bson.Unmarshal(raw, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// mongoContent retorna uma cópia do conteúdo do documento, sem o _id e o _info.
+func mongoContent(document bson.M) map[string]interface{} {
+	content := make(map[string]interface{}, len(document))
+	for key, value := range document {
+		if key != "_id" && key != Field {
+			content[key] = value
+		}
+	}
+	return content
+}
+
+// setPath aplica o valor no caminho com pontos (ex.: "address.city"), como o operador $set.
+func setPath(document map[string]interface{}, path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	current := document
+	for _, key := range keys[:len(keys)-1] {
+		next := map[string]interface{}{}
+		switch item := current[key].(type) {
+		case nil:
+		case bson.M:
+			for name, entry := range item {
+				next[name] = entry
+			}
+		case map[string]interface{}:
+			for name, entry := range item {
+				next[name] = entry
+			}
+		case bson.D:
+			for _, element := range item {
+				next[element.Key] = element.Value
+			}
+		default:
+			return api_error.New(api_error.CodeInvalidArgument, module, "updateByID", "caminho inválido para alteração").
+				With("field", path)
+		}
+		current[key] = next
+		current = next
+	}
+	current[keys[len(keys)-1]] = value
+	return nil
+}
+
+// unsetPath remove o campo no caminho com pontos, como o operador $unset.
+func unsetPath(document map[string]interface{}, path string) {
+	keys := strings.Split(path, ".")
+	current := document
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			if value, isM := current[key].(bson.M); isM {
+				next, ok = value, true
+			}
+		}
+		if !ok {
+			return
+		}
+		copied := make(map[string]interface{}, len(next))
+		for name, entry := range next {
+			copied[name] = entry
+		}
+		current[key] = copied
+		current = copied
+	}
+	delete(current, keys[len(keys)-1])
+}
+
+// checkMongoValues impede que os valores alterem diretamente o campo _info.
+func checkMongoValues(values bson.M, path string) error {
+	for key := range values {
+		if key == Field || strings.HasPrefix(key, Field+".") {
+			return api_error.New(api_error.CodeInvalidArgument, module, path, "o campo _info é mantido pelo sistema").
+				With("field", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+package info
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/diff"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// infoColumns são as colunas mantidas pelo sistema, que não podem ser alteradas diretamente.
+var infoColumns = map[string]bool{
+	ColumnCreatedAt: true,
+	ColumnChangedAt: true,
+	ColumnOwner:     true,
+	ColumnVersion:   true,
+	ColumnChecksum:  true,
+	ColumnHistory:   true,
+}
+
+// MysqlWriter grava linhas em uma tabela do MySQL mantendo as colunas do ApiInfo.
+// A coluna history é do tipo JSON e o DSN deve conter parseTime=true para a leitura das datas.
+type MysqlWriter struct {
+	db       *mysql.MysqlDB
+	table    string
+	idColumn string
+}
+
+// NewMysqlWriter cria um MysqlWriter para a tabela e a coluna identificadora informadas.
+func NewMysqlWriter(db *mysql.MysqlDB, table string, idColumn string) *MysqlWriter {
+	return &MysqlWriter{db: db, table: table, idColumn: idColumn}
+}
+
+// Insert insere a linha com o ApiInfo inicial e o checksum do conteúdo, na mesma transação. Se a coluna
+// identificadora não estiver nos valores, o identificador gerado pelo AUTO_INCREMENT é retornado.
+func (w *MysqlWriter) Insert(ctx context.Context, values map[string]interface{}, action Action) (*params.ApiChangeResult, error) {
+	table, err := mysql.QuoteIdentifier(w.table)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkMysqlValues(values, "insert"); err != nil {
+		return nil, err
+	}
+
+	columns, args, err := mysqlColumns(values)
+	if err != nil {
+		return nil, err
+	}
+	info := Insert(ctx, action)
+	columns = append(columns, "`"+ColumnCreatedAt+"`", "`"+ColumnChangedAt+"`", "`"+ColumnOwner+"`", "`"+ColumnVersion+"`")
+	args = append(args, *info.CreatedAt, *info.ChangedAt, info.Owner, *info.Version)
+
+	tx, err := w.db.Client().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (?" + strings.Repeat(", ?", len(columns)-1) + ")"
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := values[w.idColumn]
+	if !ok {
+		if id, err = result.LastInsertId(); err != nil {
+			return nil, err
+		}
+	}
+
+	stored, err := w.read(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = w.seal(ctx, tx, id, info, stored); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &params.ApiChangeResult{ID: idString(id), Info: info, Values: values}, nil
+}
+
+// Update altera os valores da linha e atualiza as colunas do ApiInfo e o checksum na mesma transação.
+// Se a versão esperada for informada, a alteração utiliza WHERE version = ? e retorna um erro CodeConflict
+// com a versão atual quando a linha foi alterada por outra operação.
+func (w *MysqlWriter) Update(ctx context.Context, id interface{}, expected *params.ApiVersionInput, values map[string]interface{}, action Action) (*params.ApiChangeResult, error) {
+	if err := checkMysqlValues(values, "update"); err != nil {
+		return nil, err
+	}
+
+	tx, err := w.db.Client().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row, err := w.read(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := mysqlInfo(row)
+	if err != nil {
+		return nil, err
+	}
+	if expected != nil && (current.Version == nil || *current.Version != expected.Expected) {
+		return nil, conflict("update", id, expected, current)
+	}
+
+	info := Update(current, action)
+	updated, err := w.write(ctx, tx, id, current.Version, values, info)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, conflict("update", id, expected, current)
+	}
+
+	// Os campos alterados e o checksum utilizam os valores relidos, no formato retornado pelo MySQL
+	stored, err := w.read(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	changes := diff.Compute(w.content(row), w.content(stored))
+	track(info, changes)
+	if err = w.seal(ctx, tx, id, info, stored); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &params.ApiChangeResult{ID: idString(id), Info: info, Values: values, Diff: changes}, nil
+}
+
+// Undo desfaz as alterações de um ApiChangeDiff, restaurando os valores anteriores das colunas.
+// Colunas sem valor anterior recebem NULL. A alteração inversa é registrada como uma nova interação da linha.
+func (w *MysqlWriter) Undo(ctx context.Context, id interface{}, expected *params.ApiVersionInput, changes *params.ApiChangeDiff, action Action) (*params.ApiChangeResult, error) {
+	set, unset := diff.Undo(changes)
+	for _, column := range unset {
+		set[column] = nil
+	}
+	return w.Update(ctx, id, expected, set, action)
+}
+
+// Info retorna o ApiInfo da linha.
+func (w *MysqlWriter) Info(ctx context.Context, id interface{}) (*params.ApiInfo, error) {
+	tx, err := w.db.Client().BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row, err := w.read(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	return mysqlInfo(row)
+}
+
+// Verify percorre a tabela em lotes e retorna as linhas cujo conteúdo ou histórico foi alterado fora da API,
+// além da quantidade de linhas verificadas.
+func (w *MysqlWriter) Verify(ctx context.Context, batchSize int) ([]Violation, int, error) {
+	table, err := mysql.QuoteIdentifier(w.table)
+	if err != nil {
+		return nil, 0, err
+	}
+	idColumn, err := mysql.QuoteIdentifier(w.idColumn)
+	if err != nil {
+		return nil, 0, err
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	var violations []Violation
+	count := 0
+	for {
+		rows, err := w.db.Client().QueryContext(ctx, "SELECT * FROM "+table+" ORDER BY "+idColumn+" LIMIT ? OFFSET ?", batchSize, count)
+		if err != nil {
+			return nil, count, err
+		}
+		items, err := mysql.ScanMaps(rows)
+		rows.Close()
+		if err != nil {
+			return nil, count, err
+		}
+
+		for _, row := range items {
+			info, err := mysqlInfo(row)
+			if err != nil {
+				return nil, count, err
+			}
+			found, err := Verify(*idString(row[w.idColumn]), w.content(row), info)
+			if err != nil {
+				return nil, count, err
+			}
+			violations = append(violations, found...)
+		}
+		count += len(items)
+		if len(items) < batchSize {
+			return violations, count, nil
+		}
+	}
+}
+
+// read lê e bloqueia a linha para alteração.
+func (w *MysqlWriter) read(ctx context.Context, tx *sql.Tx, id interface{}) (map[string]interface{}, error) {
+	table, err := mysql.QuoteIdentifier(w.table)
+	if err != nil {
+		return nil, err
+	}
+	idColumn, err := mysql.QuoteIdentifier(w.idColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT * FROM "+table+" WHERE "+idColumn+" = ? FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items, err := mysql.ScanMaps(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, api_error.New(api_error.CodeNotFound, module, "update", "documento não encontrado").
+			With("id", id)
+	}
+	return items[0], nil
+}
+
+// content retorna uma cópia dos valores da linha, sem a coluna identificadora e as colunas do ApiInfo.
+func (w *MysqlWriter) content(row map[string]interface{}) map[string]interface{} {
+	content := make(map[string]interface{}, len(row))
+	for key, value := range row {
+		if key != w.idColumn && !infoColumns[key] {
+			content[key] = value
+		}
+	}
+	return content
+}
+
+// seal calcula o checksum sobre o conteúdo relido da linha e grava o checksum e o histórico.
+// Os valores relidos incluem as colunas com valor padrão e estão no formato retornado pelo MySQL
+// (booleanos como 1, decimais com a escala da coluna), o mesmo utilizado por Verify.
+func (w *MysqlWriter) seal(ctx context.Context, tx *sql.Tx, id interface{}, info *params.ApiInfo, stored map[string]interface{}) error {
+	table, err := mysql.QuoteIdentifier(w.table)
+	if err != nil {
+		return err
+	}
+	idColumn, err := mysql.QuoteIdentifier(w.idColumn)
+	if err != nil {
+		return err
+	}
+	if err = seal(info, w.content(stored)); err != nil {
+		return err
+	}
+	history, err := json.Marshal(info.History)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE "+table+" SET `"+ColumnChecksum+"` = ?, `"+ColumnHistory+"` = ? WHERE "+idColumn+" = ?",
+		*info.Checksum, string(history), id)
+	return err
+}
+
+// mysqlInfo converte as colunas do ApiInfo da linha para ApiInfo.
+func mysqlInfo(row map[string]interface{}) (*params.ApiInfo, error) {
+	info := params.ApiInfo{}
+	if value, ok := row[ColumnCreatedAt].(time.Time); ok {
+		info.CreatedAt = &value
+	}
+	if value, ok := row[ColumnChangedAt].(time.Time); ok {
+		info.ChangedAt = &value
+	}
+	if value, ok := row[ColumnOwner].(string); ok {
+		info.Owner = &value
+	}
+	if value, ok := row[ColumnChecksum].(string); ok {
+		info.Checksum = &value
+	}
+	switch value := row[ColumnVersion].(type) {
+	case int64:
+		version := int(value)
+		info.Version = &version
+	case string:
+		version, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		info.Version = &version
+	}
+	if value, ok := row[ColumnHistory].(string); ok && value != "" {
+		if err := json.Unmarshal([]byte(value), &info.History); err != nil {
+			return nil, err
+		}
+	}
+	return &info, nil
+}
+
+// write grava os valores, a data de alteração e a versão da linha, condicionada à versão lida.
+// O checksum e o histórico são gravados por seal após a releitura da linha.
+// Retorna false se nenhuma linha foi alterada.
+func (w *MysqlWriter) write(ctx context.Context, tx *sql.Tx, id interface{}, version *int, values map[string]interface{}, info *params.ApiInfo) (bool, error) {
+	table, err := mysql.QuoteIdentifier(w.table)
+	if err != nil {
+		return false, err
+	}
+	idColumn, err := mysql.QuoteIdentifier(w.idColumn)
+	if err != nil {
+		return false, err
+	}
+
+	columns, args, err := mysqlColumns(values)
+	if err != nil {
+		return false, err
+	}
+	assignments := make([]string, 0, len(columns)+2)
+	for _, column := range columns {
+		assignments = append(assignments, column+" = ?")
+	}
+	assignments = append(assignments, "`"+ColumnChangedAt+"` = ?", "`"+ColumnVersion+"` = ?")
+	args = append(args, *info.ChangedAt, *info.Version, id)
+
+	where := " WHERE " + idColumn + " = ? AND `" + ColumnVersion + "` IS NULL"
+	if version != nil {
+		where = " WHERE " + idColumn + " = ? AND `" + ColumnVersion + "` = ?"
+		args = append(args, *version)
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE "+table+" SET "+strings.Join(assignments, ", ")+where, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// mysqlColumns retorna as colunas, validadas e em ordem alfabética, e os respectivos valores.
+func mysqlColumns(values map[string]interface{}) ([]string, []interface{}, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	columns := make([]string, 0, len(names))
+	args := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		column, err := mysql.QuoteIdentifier(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		columns = append(columns, column)
+		args = append(args, values[name])
+	}
+	return columns, args, nil
+}
+
+// checkMysqlValues impede que os valores alterem diretamente as colunas do ApiInfo.
+func checkMysqlValues(values map[string]interface{}, path string) error {
+	for key := range values {
+		if infoColumns[key] {
+			return api_error.New(api_error.CodeInvalidArgument, module, path, "a coluna é mantida pelo sistema").
+				With("field", key)
+		}
+	}
+	return nil
+}
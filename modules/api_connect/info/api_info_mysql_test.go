@@ -0,0 +1,378 @@
+package info
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/diff"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// column é uma coluna da tabela em memória: o valor padrão e a conversão para o formato retornado
+// pelo driver MySQL (TINYINT como int64, DECIMAL e textos como []byte, DATETIME como time.Time).
+type column struct {
+	name     string
+	convert  func(value driver.Value) driver.Value
+	fallback driver.Value
+}
+
+func integer(value driver.Value) driver.Value {
+	switch item := value.(type) {
+	case bool:
+		if item {
+			return int64(1)
+		}
+		return int64(0)
+	case float64:
+		return int64(item)
+	case string:
+		number, _ := strconv.ParseInt(item, 10, 64)
+		return number
+	}
+	return value
+}
+
+func decimal(value driver.Value) driver.Value {
+	switch item := value.(type) {
+	case int64:
+		return []byte(strconv.FormatFloat(float64(item), 'f', 2, 64))
+	case float64:
+		return []byte(strconv.FormatFloat(item, 'f', 2, 64))
+	case string:
+		number, _ := strconv.ParseFloat(item, 64)
+		return []byte(strconv.FormatFloat(number, 'f', 2, 64))
+	}
+	return value
+}
+
+func text(value driver.Value) driver.Value {
+	if item, ok := value.(string); ok {
+		return []byte(item)
+	}
+	return value
+}
+
+func datetime(value driver.Value) driver.Value {
+	if item, ok := value.(time.Time); ok {
+		return item.UTC().Truncate(time.Millisecond)
+	}
+	return value
+}
+
+// table é uma tabela MySQL em memória que executa as instruções geradas pelo MysqlWriter.
+type table struct {
+	columns []column
+	rows    []map[string]driver.Value
+	nextID  int64
+}
+
+// newTable cria a tabela product com colunas booleanas, decimais e com valor padrão.
+func newTable() *table {
+	return &table{columns: []column{
+		{name: "id", convert: integer},
+		{name: "name", convert: text},
+		{name: "active", convert: integer},
+		{name: "price", convert: decimal},
+		{name: "score", convert: integer, fallback: int64(0)},
+		{name: "notes", convert: text},
+		{name: ColumnCreatedAt, convert: datetime},
+		{name: ColumnChangedAt, convert: datetime},
+		{name: ColumnOwner, convert: text},
+		{name: ColumnVersion, convert: integer},
+		{name: ColumnChecksum, convert: text},
+		{name: ColumnHistory, convert: text},
+	}}
+}
+
+func (t *table) column(name string) (column, error) {
+	for _, item := range t.columns {
+		if item.name == name {
+			return item, nil
+		}
+	}
+	return column{}, fmt.Errorf("unknown column %s", name)
+}
+
+func (t *table) Connect(context.Context) (driver.Conn, error) {
+	return &conn{table: t}, nil
+}
+
+func (t *table) Driver() driver.Driver {
+	return nil
+}
+
+var (
+	insertPattern = regexp.MustCompile("^INSERT INTO `\\w+` \\((.+)\\) VALUES \\(")
+	updatePattern = regexp.MustCompile("^UPDATE `\\w+` SET (.+) WHERE (.+)$")
+	selectPattern = regexp.MustCompile("^SELECT \\* FROM `\\w+` (?:WHERE `(\\w+)` = \\? FOR UPDATE|ORDER BY `\\w+` LIMIT \\? OFFSET \\?)$")
+)
+
+// names retorna os nomes das colunas de uma lista "`a` = ?, `b` = ?".
+func names(list string, separator string) []string {
+	var result []string
+	for _, item := range strings.Split(list, separator) {
+		result = append(result, strings.Trim(strings.Fields(item)[0], "`"))
+	}
+	return result
+}
+
+// conn executa as instruções na tabela em memória. As transações restauram as linhas no Rollback.
+type conn struct {
+	table    *table
+	snapshot []map[string]driver.Value
+}
+
+func (c *conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	c.snapshot = make([]map[string]driver.Value, len(c.table.rows))
+	for index, row := range c.table.rows {
+		c.snapshot[index] = map[string]driver.Value{}
+		for key, value := range row {
+			c.snapshot[index][key] = value
+		}
+	}
+	return c, nil
+}
+
+func (c *conn) BeginTx(ctx context.Context, options driver.TxOptions) (driver.Tx, error) {
+	return c.Begin()
+}
+
+func (c *conn) Commit() error {
+	c.snapshot = nil
+	return nil
+}
+
+func (c *conn) Rollback() error {
+	if c.snapshot != nil {
+		c.table.rows = c.snapshot
+	}
+	c.snapshot = nil
+	return nil
+}
+
+type result struct {
+	id       int64
+	affected int64
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.id, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if match := insertPattern.FindStringSubmatch(query); match != nil {
+		row := map[string]driver.Value{}
+		for _, item := range c.table.columns {
+			row[item.name] = item.fallback
+		}
+		for index, name := range names(match[1], ", ") {
+			item, err := c.table.column(name)
+			if err != nil {
+				return nil, err
+			}
+			row[name] = item.convert(args[index].Value)
+		}
+		if row["id"] == nil {
+			c.table.nextID++
+			row["id"] = c.table.nextID
+		}
+		c.table.rows = append(c.table.rows, row)
+		return result{id: row["id"].(int64), affected: 1}, nil
+	}
+
+	match := updatePattern.FindStringSubmatch(query)
+	if match == nil {
+		return nil, fmt.Errorf("unsupported statement %s", query)
+	}
+	set := names(match[1], ", ")
+	values := args[:len(set)]
+	args = args[len(set):]
+
+	var affected int64
+	for _, row := range c.table.rows {
+		index, matched := 0, true
+		for _, condition := range strings.Split(match[2], " AND ") {
+			name := strings.Trim(strings.Fields(condition)[0], "`")
+			if strings.HasSuffix(condition, "IS NULL") {
+				matched = matched && row[name] == nil
+				continue
+			}
+			item, err := c.table.column(name)
+			if err != nil {
+				return nil, err
+			}
+			matched = matched && reflect.DeepEqual(row[name], item.convert(args[index].Value))
+			index++
+		}
+		if !matched {
+			continue
+		}
+		for position, name := range set {
+			item, err := c.table.column(name)
+			if err != nil {
+				return nil, err
+			}
+			row[name] = item.convert(values[position].Value)
+		}
+		affected++
+	}
+	return result{affected: affected}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	match := selectPattern.FindStringSubmatch(query)
+	if match == nil {
+		return nil, fmt.Errorf("unsupported query %s", query)
+	}
+
+	found := &rows{}
+	for _, item := range c.table.columns {
+		found.columns = append(found.columns, item.name)
+	}
+	if match[1] != "" {
+		item, err := c.table.column(match[1])
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range c.table.rows {
+			if reflect.DeepEqual(row[match[1]], item.convert(args[0].Value)) {
+				found.rows = append(found.rows, row)
+			}
+		}
+		return found, nil
+	}
+
+	sorted := append([]map[string]driver.Value{}, c.table.rows...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i]["id"].(int64) < sorted[j]["id"].(int64) })
+	limit, offset := int(args[0].Value.(int64)), int(args[1].Value.(int64))
+	for index := offset; index < len(sorted) && index < offset+limit; index++ {
+		found.rows = append(found.rows, sorted[index])
+	}
+	return found, nil
+}
+
+type rows struct {
+	columns []string
+	rows    []map[string]driver.Value
+	index   int
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.index >= len(r.rows) {
+		return io.EOF
+	}
+	for index, name := range r.columns {
+		dest[index] = r.rows[r.index][name]
+	}
+	r.index++
+	return nil
+}
+
+// verify verifica a tabela e retorna as falhas de integridade.
+func verify(t *testing.T, writer *MysqlWriter, count int) []Violation {
+	t.Helper()
+	violations, verified, err := writer.Verify(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if verified != count {
+		t.Errorf("Verify checked %d rows, want %d", verified, count)
+	}
+	return violations
+}
+
+func TestMysqlWriterRoundTrip(t *testing.T) {
+	SetSecret([]byte("test secret"))
+	products := newTable()
+	writer := NewMysqlWriter(mysql.NewDBFromClient(sql.OpenDB(products)), "product", "id")
+	ctx := context.Background()
+
+	// Colunas omitidas (valor padrão), booleanas e decimais são lidas em outro formato pelo MySQL
+	created, err := writer.Insert(ctx, map[string]interface{}{"name": "Café", "active": true, "price": 1.5}, NewAction("productCreate"))
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if *created.ID != "1" {
+		t.Errorf("ID = %s, want the AUTO_INCREMENT id", *created.ID)
+	}
+	if _, err = writer.Insert(ctx, map[string]interface{}{"id": int64(7), "name": "Chá", "price": 3}, NewAction("productCreate")); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if violations := verify(t, writer, 2); len(violations) != 0 {
+		t.Fatalf("Verify after Insert = %+v, want no violations", violations)
+	}
+
+	updated, err := writer.Update(ctx, int64(1), &params.ApiVersionInput{Expected: 1}, map[string]interface{}{"active": false, "price": 2, "name": "Café"}, NewAction("productUpdate"))
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got := diff.Paths(updated.Diff); !reflect.DeepEqual(got, []string{"active", "price"}) {
+		t.Errorf("Diff = %q, want [active price]", got)
+	}
+	if *updated.Info.Version != 2 || !reflect.DeepEqual(updated.Info.History[1].Changes, []string{"active", "price"}) {
+		t.Errorf("Info = version %d history %+v", *updated.Info.Version, updated.Info.History[1])
+	}
+	if violations := verify(t, writer, 2); len(violations) != 0 {
+		t.Fatalf("Verify after Update = %+v, want no violations", violations)
+	}
+
+	if _, err = writer.Update(ctx, int64(1), &params.ApiVersionInput{Expected: 1}, map[string]interface{}{"price": 5}, NewAction("productUpdate")); !IsConflict(err) {
+		t.Errorf("Update with an old version = %v, want CONFLICT", err)
+	}
+	if _, err = writer.Insert(ctx, map[string]interface{}{"name": "Mate", "version": 9}, NewAction("productCreate")); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+		t.Errorf("Insert with an ApiInfo column = %v, want INVALID_ARGUMENT", err)
+	}
+
+	if _, err = writer.Undo(ctx, int64(1), nil, updated.Diff, NewAction("productUndo")); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	info, err := writer.Info(ctx, int64(1))
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if *info.Version != 3 || string(products.rows[0]["price"].([]byte)) != "1.50" || products.rows[0]["active"] != int64(1) {
+		t.Errorf("after Undo: version %d price %s active %v", *info.Version, products.rows[0]["price"], products.rows[0]["active"])
+	}
+	if violations := verify(t, writer, 2); len(violations) != 0 {
+		t.Fatalf("Verify after Undo = %+v, want no violations", violations)
+	}
+
+	// Alteração feita diretamente no banco de dados
+	products.rows[1]["price"] = []byte("0.01")
+	want := []Violation{{ID: "7", Reason: ViolationContent, Index: -1}}
+	if violations := verify(t, writer, 2); !reflect.DeepEqual(violations, want) {
+		t.Errorf("Verify after tampering = %+v, want %+v", violations, want)
+	}
+}
@@ -0,0 +1,75 @@
+package info
+
+import (
+	"context"
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"time"
+)
+
+// Valores padrão das novas tentativas em conflito de versão.
+const (
+	DefaultRetryAttempts = 3
+	DefaultRetryWait     = 10 * time.Millisecond
+)
+
+// errConflict é utilizado para identificar erros de conflito de versão com errors.Is.
+var errConflict = api_error.New(api_error.CodeConflict, module, "")
+
+// conflict cria o erro de conflito de versão com a versão esperada e a versão atual do documento.
+func conflict(path string, id interface{}, expected *params.ApiVersionInput, current *params.ApiInfo) error {
+	err := api_error.New(api_error.CodeConflict, module, path, "o documento foi alterado por outra operação").
+		With("id", id)
+	if expected != nil {
+		err.With("expected", expected.Expected)
+	}
+	if current != nil && current.Version != nil {
+		err.With("version", *current.Version)
+	}
+	return err
+}
+
+// IsConflict indica se o erro é um conflito de versão.
+func IsConflict(err error) bool {
+	return errors.Is(err, errConflict)
+}
+
+// CurrentVersion retorna a versão atual do documento informada em um erro de conflito.
+func CurrentVersion(err error) (int, bool) {
+	var apiError *api_error.ApiError
+	if !errors.As(err, &apiError) || apiError.Code != api_error.CodeConflict {
+		return 0, false
+	}
+	version, ok := apiError.Variable["version"].(int)
+	return version, ok
+}
+
+// Retry executa a alteração novamente enquanto houver conflito de versão, até o limite de tentativas.
+// Deve ser utilizado somente em alterações idempotentes do servidor, em que fn lê o documento
+// e grava com a versão lida. Attempts e wait menores ou iguais a zero utilizam os valores padrão.
+// A espera antes da tentativa n é wait << (n-1); após a última tentativa o conflito é retornado sem espera.
+func Retry(ctx context.Context, attempts int, wait time.Duration, fn func(ctx context.Context) error) error {
+	if attempts <= 0 {
+		attempts = DefaultRetryAttempts
+	}
+	if wait <= 0 {
+		wait = DefaultRetryWait
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = fn(ctx); !IsConflict(err) || attempt == attempts-1 {
+			return err
+		}
+
+		timer := time.NewTimer(wait << attempt)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package info
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	errConflictTest := conflict("update", 1, &params.ApiVersionInput{Expected: 1}, nil)
+	errOther := errors.New("falha de conexão")
+
+	tests := []struct {
+		name     string
+		attempts int
+		results  []error
+		calls    int
+		err      error
+		wait     time.Duration
+	}{
+		{name: "success", attempts: 3, results: []error{nil}, calls: 1},
+		{name: "other error", attempts: 3, results: []error{errOther}, calls: 1, err: errOther},
+		{name: "conflict then success", attempts: 3, results: []error{errConflictTest, errConflictTest, nil}, calls: 3, wait: 30 * time.Millisecond},
+		{name: "conflict then other error", attempts: 3, results: []error{errConflictTest, errOther}, calls: 2, err: errOther, wait: 10 * time.Millisecond},
+		{name: "always conflict", attempts: 3, results: []error{errConflictTest, errConflictTest, errConflictTest}, calls: 3, err: errConflictTest, wait: 30 * time.Millisecond},
+		{name: "single attempt", attempts: 1, results: []error{errConflictTest}, calls: 1, err: errConflictTest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			var waited time.Duration
+			last := time.Now()
+			err := Retry(context.Background(), test.attempts, 10*time.Millisecond, func(ctx context.Context) error {
+				if calls > 0 {
+					waited += time.Since(last)
+				}
+				calls++
+				defer func() { last = time.Now() }()
+				return test.results[calls-1]
+			})
+			elapsed := time.Since(last)
+			if calls != test.calls || !errors.Is(err, test.err) {
+				t.Errorf("Retry = %v after %d calls, want %v after %d calls", err, calls, test.err, test.calls)
+			}
+			if waited < test.wait {
+				t.Errorf("Retry waited %s between the attempts, want at least %s", waited, test.wait)
+			}
+			// Após a última tentativa a espera seria de pelo menos 10ms
+			if elapsed > 5*time.Millisecond {
+				t.Errorf("Retry waited %s after the last attempt", elapsed)
+			}
+		})
+	}
+}
+
+func TestRetryDefaults(t *testing.T) {
+	calls := 0
+	start := time.Now()
+	err := Retry(context.Background(), 0, 0, func(ctx context.Context) error {
+		calls++
+		return conflict("update", 1, nil, nil)
+	})
+	if calls != DefaultRetryAttempts || !IsConflict(err) {
+		t.Errorf("Retry = %v after %d calls, want CONFLICT after %d calls", err, calls, DefaultRetryAttempts)
+	}
+	// 10ms + 20ms entre as três tentativas, sem a espera de 40ms após a última
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond || elapsed >= 70*time.Millisecond {
+		t.Errorf("Retry took %s, want between 30ms and 70ms", elapsed)
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := Retry(ctx, 5, time.Hour, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return conflict("update", 1, nil, nil)
+	})
+	if calls != 1 || !errors.Is(err, context.Canceled) {
+		t.Errorf("Retry = %v after %d calls, want context.Canceled after 1 call", err, calls)
+	}
+}
+
+func TestConflict(t *testing.T) {
+	version := 4
+	err := conflict("update", 7, &params.ApiVersionInput{Expected: 3}, &params.ApiInfo{Version: &version})
+	if !IsConflict(err) || !errors.Is(err, api_error.New(api_error.CodeConflict, "", "")) {
+		t.Errorf("IsConflict(%v) = false", err)
+	}
+	if current, ok := CurrentVersion(err); !ok || current != 4 {
+		t.Errorf("CurrentVersion = %d, %v, want 4", current, ok)
+	}
+	var apiError *api_error.ApiError
+	if !errors.As(err, &apiError) || apiError.Variable["expected"] != 3 || apiError.Variable["id"] != 7 {
+		t.Errorf("conflict = %+v", apiError)
+	}
+
+	if _, ok := CurrentVersion(conflict("update", 7, nil, nil)); ok {
+		t.Error("CurrentVersion without the current version = true")
+	}
+	for _, other := range []error{nil, errors.New("conflito"), api_error.New(api_error.CodeNotFound, module, "id", "não encontrado")} {
+		if IsConflict(other) {
+			t.Errorf("IsConflict(%v) = true", other)
+		}
+		if _, ok := CurrentVersion(other); ok {
+			t.Errorf("CurrentVersion(%v) = true", other)
+		}
+	}
+}
+
+func TestMysqlWriterVersion(t *testing.T) {
+	SetSecret([]byte("test secret"))
+	products := newTable()
+	writer := NewMysqlWriter(mysql.NewDBFromClient(sql.OpenDB(products)), "product", "id")
+	ctx := context.Background()
+
+	if _, err := writer.Insert(ctx, map[string]interface{}{"name": "Café"}, NewAction("productCreate")); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expected *params.ApiVersionInput
+		version  int
+		current  int
+	}{
+		{name: "expected version", expected: &params.ApiVersionInput{Expected: 1}, version: 2},
+		{name: "old version", expected: &params.ApiVersionInput{Expected: 1}, current: 2},
+		{name: "future version", expected: &params.ApiVersionInput{Expected: 9}, current: 2},
+		{name: "without version", version: 3},
+	}
+	for _, test := range tests {
+		result, err := writer.Update(ctx, int64(1), test.expected, map[string]interface{}{"name": test.name}, NewAction("productUpdate"))
+		if test.current != 0 {
+			if current, ok := CurrentVersion(err); !IsConflict(err) || !ok || current != test.current {
+				t.Errorf("%s: Update = %v, want CONFLICT with version %d", test.name, err, test.current)
+			}
+			continue
+		}
+		if err != nil || *result.Info.Version != test.version {
+			t.Errorf("%s: Update = %+v, %v, want version %d", test.name, result, err, test.version)
+		}
+	}
+	if name, _ := products.rows[0]["name"].([]byte); string(name) != "without version" {
+		t.Errorf("name = %s, want the last update", products.rows[0]["name"])
+	}
+}
@@ -0,0 +1,349 @@
+// Package lifecycle controla o ciclo de vida dos documentos pelo campo status (StatusEnabledDisabledEnum):
+// as mutações disable, enable e restore geradas para cada módulo e a condição das consultas de listas,
+// que excluem os documentos DISABLED, exceto quando solicitado por StatusEnabledDisabledSearchInput.
+//
+// Os módulos que declaram o campo "status: StatusEnabledDisabledEnum" no type.graphqls recebem as mutações
+// <projeto><Pacote>Disable, <projeto><Pacote>Enable e <projeto><Pacote>Restore geradas por main/schemas.go
+// e main/resolvers.go. Somente o dono do documento (ApiInfo.owner) e administradores podem alterar o status,
+// exceto quando o módulo registra um Authorizer próprio.
+// A coleção/tabela do módulo precisa ser registrada no Service padrão:
+//
+//	service := lifecycle.NewService(lifecycle.NewMongoStore(db, "app"))
+//	service.Register("app/account", lifecycle.Target{Name: "account", UseObjectID: true})
+//	lifecycle.SetDefault(service)
+package lifecycle
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"sync"
+	"time"
+)
+
+const module = "api_connect/lifecycle"
+
+// Field é o campo (MongoDB) ou coluna (MySQL) com o status do documento.
+const Field = "status"
+
+// Ações do ciclo de vida, registradas no histórico do ApiInfo.
+const (
+	ActionDisable = "disable"
+	ActionEnable  = "enable"
+	ActionRestore = "restore"
+)
+
+// transition define os status de origem permitidos e o status de destino de uma ação.
+type transition struct {
+	from []status.StatusEnabledDisabledEnum
+	to   status.StatusEnabledDisabledEnum
+}
+
+// transitions são as transições permitidas: enable ativa documentos ainda não definidos,
+// disable desabilita documentos não definidos ou ativos e restore reativa documentos desabilitados.
+var transitions = map[string]transition{
+	ActionDisable: {
+		from: []status.StatusEnabledDisabledEnum{status.StatusEnabledDisabledEnumUndefined, status.StatusEnabledDisabledEnumEnabled},
+		to:   status.StatusEnabledDisabledEnumDisabled,
+	},
+	ActionEnable: {
+		from: []status.StatusEnabledDisabledEnum{status.StatusEnabledDisabledEnumUndefined},
+		to:   status.StatusEnabledDisabledEnumEnabled,
+	},
+	ActionRestore: {
+		from: []status.StatusEnabledDisabledEnum{status.StatusEnabledDisabledEnumDisabled},
+		to:   status.StatusEnabledDisabledEnumEnabled,
+	},
+}
+
+// ApiLifecycleInput é a representação Go do input GraphQL ApiLifecycleInput.
+type ApiLifecycleInput struct {
+	ID      string `json:"_id"`
+	Version *int   `json:"version"`
+}
+
+// Target é a coleção/tabela de um módulo.
+type Target struct {
+	// Name é o nome da coleção (MongoDB) ou tabela (MySQL).
+	Name string
+
+	// UseObjectID indica que os identificadores da coleção são ObjectID (MongoDB).
+	UseObjectID bool
+
+	// IDColumn é a coluna identificadora da tabela (MySQL). Se vazia, utiliza "id".
+	IDColumn string
+
+	// Authorize verifica se o usuário da sessão pode executar a ação no documento. Se nil, utiliza OwnerOrAdmin.
+	Authorize Authorizer
+}
+
+// Document é o estado do documento lido antes da alteração do status.
+type Document struct {
+	// Status é o status atual. Documentos sem status retornam UNDEFINED.
+	Status status.StatusEnabledDisabledEnum
+
+	// Version é a versão atual (ApiInfo.version).
+	Version *int
+
+	// Owner é o identificador do dono do documento (ApiInfo.owner).
+	Owner string
+}
+
+// Authorizer verifica se o usuário da sessão pode executar a ação (disable, enable ou restore) no documento,
+// retornando um erro, por exemplo CodePermissionDenied, quando não pode.
+type Authorizer func(ctx context.Context, action string, id string, document *Document) error
+
+// OwnerOrAdmin permite a ação somente ao dono do documento e aos administradores.
+func OwnerOrAdmin(ctx context.Context, action string, id string, document *Document) error {
+	user, ok := middleware.ForContext(ctx)
+	if !ok {
+		return api_error.New(api_error.CodeUnauthenticated, module, action, "usuário não autenticado")
+	}
+	if user.IsAdmin || (document.Owner != "" && document.Owner == user.ID) {
+		return nil
+	}
+	return api_error.New(api_error.CodePermissionDenied, module, action, "somente o dono ou um administrador pode alterar o status do documento").
+		With("id", id)
+}
+
+// Store lê e altera o status dos documentos em um banco de dados.
+type Store interface {
+	// Read retorna o status, a versão e o dono do documento, ou um erro CodeNotFound.
+	Read(ctx context.Context, target Target, id string) (*Document, error)
+
+	// Update altera o status do documento mantendo o ApiInfo, condicionado à versão esperada.
+	Update(ctx context.Context, target Target, id string, expected *params.ApiVersionInput, value status.StatusEnabledDisabledEnum, action info.Action) (*params.ApiChangeResult, error)
+}
+
+// Service altera o status dos documentos dos módulos registrados.
+type Service struct {
+	store   Store
+	mu      sync.RWMutex
+	targets map[string]Target
+}
+
+// NewService cria um Service com o Store informado (NewMongoStore ou NewMysqlStore).
+func NewService(store Store) *Service {
+	return &Service{store: store, targets: map[string]Target{}}
+}
+
+// Register registra a coleção/tabela de um módulo, identificado pelo projeto/pacote (ex.: "app/account").
+func (s *Service) Register(moduleName string, target Target) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.targets[moduleName] = target
+}
+
+// Disable desabilita o documento.
+func (s *Service) Disable(ctx context.Context, moduleName string, id string, version *int) (*params.ApiChangeResult, error) {
+	return s.change(ctx, moduleName, id, version, ActionDisable)
+}
+
+// Enable ativa o documento ainda não definido.
+func (s *Service) Enable(ctx context.Context, moduleName string, id string, version *int) (*params.ApiChangeResult, error) {
+	return s.change(ctx, moduleName, id, version, ActionEnable)
+}
+
+// Restore reativa o documento desabilitado.
+func (s *Service) Restore(ctx context.Context, moduleName string, id string, version *int) (*params.ApiChangeResult, error) {
+	return s.change(ctx, moduleName, id, version, ActionRestore)
+}
+
+// change verifica a permissão do usuário e a transição pelo status atual e altera o status condicionado à versão lida.
+// Se a versão não for informada, a operação é repetida quando o documento é alterado por outra operação.
+func (s *Service) change(ctx context.Context, moduleName string, id string, version *int, name string) (*params.ApiChangeResult, error) {
+	s.mu.RLock()
+	target, ok := s.targets[moduleName]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "module", "módulo não registrado").
+			With("module", moduleName)
+	}
+
+	authorize := target.Authorize
+	if authorize == nil {
+		authorize = OwnerOrAdmin
+	}
+
+	rule := transitions[name]
+	apply := func(ctx context.Context) (*params.ApiChangeResult, error) {
+		document, err := s.store.Read(ctx, target, id)
+		if err != nil {
+			return nil, err
+		}
+		if err = authorize(ctx, name, id, document); err != nil {
+			return nil, err
+		}
+		if !allowed(rule.from, document.Status) {
+			return nil, api_error.New(api_error.CodeFailedPrecondition, module, name, "transição de status não permitida").
+				With("id", id).
+				With("status", string(document.Status)).
+				With("action", name)
+		}
+
+		var expected *params.ApiVersionInput
+		if version != nil {
+			expected = &params.ApiVersionInput{Expected: *version}
+		} else if document.Version != nil {
+			expected = &params.ApiVersionInput{Expected: *document.Version}
+		}
+		return s.store.Update(ctx, target, id, expected, rule.to, info.NewAction(name))
+	}
+
+	if version != nil {
+		return apply(ctx)
+	}
+
+	var result *params.ApiChangeResult
+	err := info.Retry(ctx, 0, 0, func(ctx context.Context) error {
+		var err error
+		result, err = apply(ctx)
+		return err
+	})
+	return result, err
+}
+
+// allowed verifica se o status atual está entre os status de origem da transição.
+func allowed(from []status.StatusEnabledDisabledEnum, current status.StatusEnabledDisabledEnum) bool {
+	for _, item := range from {
+		if item == current {
+			return true
+		}
+	}
+	return false
+}
+
+// ApiDisableMutation implementa a mutação de desabilitar, retornando o envelope ApiChangeResponse.
+func (s *Service) ApiDisableMutation(ctx context.Context, moduleName string, input ApiLifecycleInput) (*params.ApiChangeResponse, error) {
+	return s.response(ctx, moduleName, input, ActionDisable)
+}
+
+// ApiEnableMutation implementa a mutação de ativar, retornando o envelope ApiChangeResponse.
+func (s *Service) ApiEnableMutation(ctx context.Context, moduleName string, input ApiLifecycleInput) (*params.ApiChangeResponse, error) {
+	return s.response(ctx, moduleName, input, ActionEnable)
+}
+
+// ApiRestoreMutation implementa a mutação de reativar, retornando o envelope ApiChangeResponse.
+func (s *Service) ApiRestoreMutation(ctx context.Context, moduleName string, input ApiLifecycleInput) (*params.ApiChangeResponse, error) {
+	return s.response(ctx, moduleName, input, ActionRestore)
+}
+
+// response executa a ação e monta o envelope ApiChangeResponse.
+func (s *Service) response(ctx context.Context, moduleName string, input ApiLifecycleInput, name string) (*params.ApiChangeResponse, error) {
+	_timeStart := time.Now()
+
+	_result, err := s.change(ctx, moduleName, input.ID, input.Version, name)
+	if err != nil {
+		return nil, err
+	}
+
+	_response := params.ApiChangeResponse{
+		Result:      _result,
+		Success:     true,
+		ElapsedTime: time.Since(_timeStart).String(),
+	}
+	return &_response, nil
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultService *Service
+)
+
+// SetDefault define o Service utilizado pelas mutações geradas, normalmente na inicialização do servidor.
+func SetDefault(service *Service) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultService = service
+}
+
+// Default retorna o Service padrão, ou nil se não foi definido.
+func Default() *Service {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultService
+}
+
+// Disable desabilita o documento com o Service padrão.
+func Disable(ctx context.Context, moduleName string, id string, version *int) (*params.ApiChangeResult, error) {
+	service, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return service.Disable(ctx, moduleName, id, version)
+}
+
+// Enable ativa o documento com o Service padrão.
+func Enable(ctx context.Context, moduleName string, id string, version *int) (*params.ApiChangeResult, error) {
+	service, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return service.Enable(ctx, moduleName, id, version)
+}
+
+// Restore reativa o documento com o Service padrão.
+func Restore(ctx context.Context, moduleName string, id string, version *int) (*params.ApiChangeResult, error) {
+	service, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return service.Restore(ctx, moduleName, id, version)
+}
+
+// current retorna o Service padrão ou um erro se não foi definido.
+func current() (*Service, error) {
+	service := Default()
+	if service == nil {
+		return nil, api_error.New(api_error.CodeInternal, module, "default", "serviço de ciclo de vida não configurado")
+	}
+	return service, nil
+}
+
+// Expression retorna a condição do campo status para as consultas de listas.
+// Sem search, ou sem a opção ENABLED ou DISABLED, os documentos ativos e não definidos são incluídos
+// e os desabilitados são excluídos. A lista de campos permitidos do módulo precisa conter Field.
+func Expression(search *status.StatusEnabledDisabledSearchInput) (filter.Expression, error) {
+	enabled, disabled := true, false
+	if search != nil {
+		if search.Enabled != nil {
+			enabled = *search.Enabled == status.StatusIncludeNotIncludeEnumInclude
+		}
+		if search.Disabled != nil {
+			disabled = *search.Disabled == status.StatusIncludeNotIncludeEnumInclude
+		}
+	}
+
+	switch {
+	case enabled && disabled:
+		return filter.Expression{}, nil
+	case enabled:
+		return filter.Condition(Field, params.ComparisonQueryOperatorsNotEqualNe, string(status.StatusEnabledDisabledEnumDisabled)), nil
+	case disabled:
+		return filter.Condition(Field, params.ComparisonQueryOperatorsEqualEq, string(status.StatusEnabledDisabledEnumDisabled)), nil
+	}
+	return filter.Expression{}, api_error.New(api_error.CodeInvalidArgument, module, "status", "a consulta precisa incluir documentos ENABLED ou DISABLED")
+}
+
+// And adiciona a condição do campo status à expressão de filtro da consulta de lista,
+// por exemplo lifecycle.And(filter.FromInput(input), search).
+func And(expression filter.Expression, search *status.StatusEnabledDisabledSearchInput) (filter.Expression, error) {
+	condition, err := Expression(search)
+	if err != nil {
+		return filter.Expression{}, err
+	}
+	if condition.IsEmpty() {
+		return expression, nil
+	}
+	if expression.IsEmpty() {
+		return condition, nil
+	}
+	return filter.And(expression, condition), nil
+}
+
+// compiler compila somente a condição do campo status.
+var compiler = filter.NewCompiler(filter.Fields{Field: Field})
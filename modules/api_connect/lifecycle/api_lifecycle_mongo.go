@@ -0,0 +1,99 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"github.com/coocree/coocree_apiconnect_go/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore altera o status dos documentos em coleções do MongoDB.
+type MongoStore struct {
+	db       *mongo.MongoDB
+	database string
+}
+
+// NewMongoStore cria um Store para o banco de dados MongoDB informado.
+func NewMongoStore(db *mongo.MongoDB, database string) *MongoStore {
+	return &MongoStore{db: db, database: database}
+}
+
+// Read retorna o status, a versão e o dono do documento.
+func (s *MongoStore) Read(ctx context.Context, target Target, id string) (*Document, error) {
+	key, err := mongoID(target, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var document struct {
+		Status *string `bson:"status"`
+		Info   *struct {
+			Version *int    `bson:"version"`
+			Owner   *string `bson:"owner"`
+		} `bson:"_info"`
+	}
+	findOptions := options.FindOne().SetProjection(bson.D{
+		{Key: Field, Value: 1},
+		{Key: info.Field + ".version", Value: 1},
+		{Key: info.Field + ".owner", Value: 1},
+	})
+	err = s.db.Collection(s.database, target.Name).FindOne(ctx, bson.D{{Key: "_id", Value: key}}, findOptions).Decode(&document)
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return nil, api_error.New(api_error.CodeNotFound, module, "status", "documento não encontrado").
+			With("id", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Document{Status: status.StatusEnabledDisabledEnumUndefined}
+	if document.Status != nil && *document.Status != "" {
+		result.Status = status.StatusEnabledDisabledEnum(*document.Status)
+	}
+	if document.Info != nil {
+		result.Version = document.Info.Version
+		if document.Info.Owner != nil {
+			result.Owner = *document.Info.Owner
+		}
+	}
+	return result, nil
+}
+
+// Update altera o status do documento pelo info.MongoWriter.
+func (s *MongoStore) Update(ctx context.Context, target Target, id string, expected *params.ApiVersionInput, value status.StatusEnabledDisabledEnum, action info.Action) (*params.ApiChangeResult, error) {
+	key, err := mongoID(target, id)
+	if err != nil {
+		return nil, err
+	}
+	writer := info.NewMongoWriter(s.db.Collection(s.database, target.Name))
+	return writer.UpdateByID(ctx, key, expected, bson.M{Field: string(value)}, action)
+}
+
+// mongoID converte o identificador para ObjectID quando a coleção utiliza ObjectID.
+func mongoID(target Target, id string) (interface{}, error) {
+	if !target.UseObjectID {
+		return id, nil
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "_id", "identificador inválido").
+			With("id", id)
+	}
+	return objectID, nil
+}
+
+// Mongo retorna o filtro BSON do campo status para as consultas de listas.
+func Mongo(search *status.StatusEnabledDisabledSearchInput) (bson.D, error) {
+	expression, err := Expression(search)
+	if err != nil {
+		return nil, err
+	}
+	return compiler.Mongo(expression)
+}
@@ -0,0 +1,82 @@
+package lifecycle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+)
+
+// MysqlStore altera o status das linhas em tabelas do MySQL.
+type MysqlStore struct {
+	db *mysql.MysqlDB
+}
+
+// NewMysqlStore cria um Store para o MysqlDB informado.
+func NewMysqlStore(db *mysql.MysqlDB) *MysqlStore {
+	return &MysqlStore{db: db}
+}
+
+// Read retorna o status, a versão e o dono da linha.
+func (s *MysqlStore) Read(ctx context.Context, target Target, id string) (*Document, error) {
+	table, err := mysql.QuoteIdentifier(target.Name)
+	if err != nil {
+		return nil, err
+	}
+	idColumn, err := mysql.QuoteIdentifier(mysqlIDColumn(target))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		value   sql.NullString
+		version sql.NullInt64
+		owner   sql.NullString
+	)
+	query := "SELECT `" + Field + "`, `" + info.ColumnVersion + "`, `" + info.ColumnOwner + "` FROM " + table + " WHERE " + idColumn + " = ?"
+	err = s.db.Client().QueryRowContext(ctx, query, id).Scan(&value, &version, &owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, api_error.New(api_error.CodeNotFound, module, "status", "documento não encontrado").
+			With("id", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Document{Status: status.StatusEnabledDisabledEnumUndefined, Owner: owner.String}
+	if value.Valid && value.String != "" {
+		result.Status = status.StatusEnabledDisabledEnum(value.String)
+	}
+	if version.Valid {
+		currentVersion := int(version.Int64)
+		result.Version = &currentVersion
+	}
+	return result, nil
+}
+
+// Update altera o status da linha pelo info.MysqlWriter.
+func (s *MysqlStore) Update(ctx context.Context, target Target, id string, expected *params.ApiVersionInput, value status.StatusEnabledDisabledEnum, action info.Action) (*params.ApiChangeResult, error) {
+	writer := info.NewMysqlWriter(s.db, target.Name, mysqlIDColumn(target))
+	return writer.Update(ctx, id, expected, map[string]interface{}{Field: string(value)}, action)
+}
+
+// mysqlIDColumn retorna a coluna identificadora da tabela.
+func mysqlIDColumn(target Target) string {
+	if target.IDColumn == "" {
+		return "id"
+	}
+	return target.IDColumn
+}
+
+// Mysql retorna a condição WHERE parametrizada da coluna status para as consultas de listas.
+func Mysql(search *status.StatusEnabledDisabledSearchInput) (string, []interface{}, error) {
+	expression, err := Expression(search)
+	if err != nil {
+		return "", nil, err
+	}
+	return compiler.Mysql(expression)
+}
@@ -0,0 +1,210 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// authenticator identifica todas as requisições com o usuário informado.
+type authenticator struct {
+	user middleware.User
+}
+
+func (a authenticator) Authenticate(http.ResponseWriter, *http.Request) (middleware.User, bool, error) {
+	return a.user, true, nil
+}
+
+// contextFor retorna o contexto de uma requisição autenticada pelo middleware com o usuário informado.
+func contextFor(user middleware.User) context.Context {
+	var ctx context.Context
+	handler := middleware.Authenticate(authenticator{user: user})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	return ctx
+}
+
+// memoryStore guarda um documento por identificador e registra as alterações.
+type memoryStore struct {
+	documents map[string]*Document
+	updated   []status.StatusEnabledDisabledEnum
+	expected  []*params.ApiVersionInput
+}
+
+func (s *memoryStore) Read(ctx context.Context, target Target, id string) (*Document, error) {
+	document, ok := s.documents[id]
+	if !ok {
+		return nil, api_error.New(api_error.CodeNotFound, module, "id", "documento não encontrado")
+	}
+	return document, nil
+}
+
+func (s *memoryStore) Update(ctx context.Context, target Target, id string, expected *params.ApiVersionInput, value status.StatusEnabledDisabledEnum, action info.Action) (*params.ApiChangeResult, error) {
+	s.updated = append(s.updated, value)
+	s.expected = append(s.expected, expected)
+	return &params.ApiChangeResult{ID: &id}, nil
+}
+
+func TestTransitions(t *testing.T) {
+	const (
+		undefined = status.StatusEnabledDisabledEnumUndefined
+		enabled   = status.StatusEnabledDisabledEnumEnabled
+		disabled  = status.StatusEnabledDisabledEnumDisabled
+	)
+	tests := []struct {
+		action  string
+		current status.StatusEnabledDisabledEnum
+		want    status.StatusEnabledDisabledEnum
+	}{
+		{ActionDisable, undefined, disabled},
+		{ActionDisable, enabled, disabled},
+		{ActionDisable, disabled, ""},
+		{ActionEnable, undefined, enabled},
+		{ActionEnable, enabled, ""},
+		{ActionEnable, disabled, ""},
+		{ActionRestore, undefined, ""},
+		{ActionRestore, enabled, ""},
+		{ActionRestore, disabled, enabled},
+	}
+	admin := contextFor(middleware.User{ID: "admin", IsAdmin: true})
+	for _, test := range tests {
+		t.Run(test.action+" "+string(test.current), func(t *testing.T) {
+			version := 3
+			store := &memoryStore{documents: map[string]*Document{"1": {Status: test.current, Version: &version}}}
+			service := NewService(store)
+			service.Register("app/product", Target{Name: "product"})
+
+			result, err := service.change(admin, "app/product", "1", nil, test.action)
+			if test.want == "" {
+				if !errors.Is(err, api_error.New(api_error.CodeFailedPrecondition, "", "")) {
+					t.Errorf("change = %v, want FAILED_PRECONDITION", err)
+				}
+				if len(store.updated) != 0 {
+					t.Errorf("store updated to %v", store.updated)
+				}
+				return
+			}
+			if err != nil || *result.ID != "1" {
+				t.Fatalf("change = %v, %v", result, err)
+			}
+			if len(store.updated) != 1 || store.updated[0] != test.want {
+				t.Errorf("updated = %v, want %s", store.updated, test.want)
+			}
+			if store.expected[0] == nil || store.expected[0].Expected != version {
+				t.Errorf("expected version = %v, want the version read", store.expected[0])
+			}
+		})
+	}
+}
+
+func TestChangeVersion(t *testing.T) {
+	store := &memoryStore{documents: map[string]*Document{"1": {Status: status.StatusEnabledDisabledEnumEnabled}}}
+	service := NewService(store)
+	service.Register("app/product", Target{Name: "product"})
+	admin := contextFor(middleware.User{ID: "admin", IsAdmin: true})
+
+	version := 5
+	if _, err := service.Disable(admin, "app/product", "1", &version); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+	if store.expected[0] == nil || store.expected[0].Expected != 5 {
+		t.Errorf("expected version = %v, want 5", store.expected[0])
+	}
+
+	if _, err := service.Disable(admin, "app/unknown", "1", nil); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+		t.Errorf("Disable on an unknown module = %v, want INVALID_ARGUMENT", err)
+	}
+	if _, err := service.Disable(admin, "app/product", "2", nil); !errors.Is(err, api_error.New(api_error.CodeNotFound, "", "")) {
+		t.Errorf("Disable on an unknown document = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestOwnerOrAdmin(t *testing.T) {
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		owner string
+		code  string
+	}{
+		{name: "owner", ctx: contextFor(middleware.User{ID: "user-1"}), owner: "user-1"},
+		{name: "admin", ctx: contextFor(middleware.User{ID: "admin", IsAdmin: true}), owner: "user-1"},
+		{name: "admin without owner", ctx: contextFor(middleware.User{ID: "admin", IsAdmin: true})},
+		{name: "other user", ctx: contextFor(middleware.User{ID: "user-2"}), owner: "user-1", code: api_error.CodePermissionDenied},
+		{name: "without owner", ctx: contextFor(middleware.User{ID: "user-1"}), code: api_error.CodePermissionDenied},
+		{name: "anonymous", ctx: context.Background(), owner: "user-1", code: api_error.CodeUnauthenticated},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := OwnerOrAdmin(test.ctx, ActionDisable, "1", &Document{Owner: test.owner})
+			if test.code == "" {
+				if err != nil {
+					t.Errorf("OwnerOrAdmin = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, api_error.New(test.code, "", "")) {
+				t.Errorf("OwnerOrAdmin = %v, want %s", err, test.code)
+			}
+		})
+	}
+
+	// O Authorizer do módulo substitui OwnerOrAdmin
+	store := &memoryStore{documents: map[string]*Document{"1": {Status: status.StatusEnabledDisabledEnumEnabled, Owner: "user-1"}}}
+	service := NewService(store)
+	service.Register("app/product", Target{Name: "product", Authorize: func(ctx context.Context, action string, id string, document *Document) error {
+		return nil
+	}})
+	if _, err := service.Disable(contextFor(middleware.User{ID: "user-2"}), "app/product", "1", nil); err != nil {
+		t.Errorf("Disable with the module Authorizer = %v", err)
+	}
+	service.Register("app/product", Target{Name: "product"})
+	if _, err := service.Disable(contextFor(middleware.User{ID: "user-2"}), "app/product", "1", nil); !errors.Is(err, api_error.New(api_error.CodePermissionDenied, "", "")) {
+		t.Errorf("Disable by another user = %v, want PERMISSION_DENIED", err)
+	}
+}
+
+func TestExpression(t *testing.T) {
+	include, noInclude := status.StatusIncludeNotIncludeEnumInclude, status.StatusIncludeNotIncludeEnumNoInclude
+	tests := []struct {
+		name   string
+		search *status.StatusEnabledDisabledSearchInput
+		where  string
+		args   []interface{}
+		fails  bool
+	}{
+		{name: "nil", search: nil, where: "(`status` <> ? OR `status` IS NULL)", args: []interface{}{"DISABLED"}},
+		{name: "empty", search: &status.StatusEnabledDisabledSearchInput{}, where: "(`status` <> ? OR `status` IS NULL)", args: []interface{}{"DISABLED"}},
+		{name: "ENABLED INCLUDE, DISABLED INCLUDE", search: &status.StatusEnabledDisabledSearchInput{Enabled: &include, Disabled: &include}, where: "1 = 1"},
+		{name: "ENABLED INCLUDE, DISABLED NO_INCLUDE", search: &status.StatusEnabledDisabledSearchInput{Enabled: &include, Disabled: &noInclude}, where: "(`status` <> ? OR `status` IS NULL)", args: []interface{}{"DISABLED"}},
+		{name: "ENABLED NO_INCLUDE, DISABLED INCLUDE", search: &status.StatusEnabledDisabledSearchInput{Enabled: &noInclude, Disabled: &include}, where: "`status` = ?", args: []interface{}{"DISABLED"}},
+		{name: "ENABLED NO_INCLUDE, DISABLED NO_INCLUDE", search: &status.StatusEnabledDisabledSearchInput{Enabled: &noInclude, Disabled: &noInclude}, fails: true},
+		{name: "DISABLED INCLUDE", search: &status.StatusEnabledDisabledSearchInput{Disabled: &include}, where: "1 = 1"},
+		{name: "ENABLED NO_INCLUDE", search: &status.StatusEnabledDisabledSearchInput{Enabled: &noInclude}, fails: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expression, err := Expression(test.search)
+			if test.fails {
+				if !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+					t.Errorf("Expression = %v, want INVALID_ARGUMENT", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expression: %v", err)
+			}
+			where, args, err := compiler.Mysql(expression)
+			if err != nil || where != test.where || len(args) != len(test.args) || (len(args) > 0 && args[0] != test.args[0]) {
+				t.Errorf("Expression = %q %v, %v, want %q %v", where, args, err, test.where, test.args)
+			}
+		})
+	}
+}
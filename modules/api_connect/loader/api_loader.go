@@ -0,0 +1,290 @@
+// Package loader implementa carregadores com agrupamento (batching) e cache por requisição, no estilo DataLoader.
+// As consultas por identificador feitas durante um intervalo curto (Wait) são agrupadas em uma única
+// consulta $in (MongoDB) ou IN (...) (MySQL), evitando o problema N+1 nos resolvers.
+package loader
+
+import (
+	"context"
+	"fmt"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const module = "api_connect/loader"
+
+// Valores padrão de agrupamento.
+const (
+	DefaultWait     = 2 * time.Millisecond
+	DefaultMaxBatch = 100
+)
+
+// A private key for context that only this package can access.
+var loadersCtxKey = &contextKey{"loaders"}
+
+type contextKey struct {
+	name string
+}
+
+// BatchFunc carrega os documentos dos identificadores informados.
+// Retorna um mapa indexado pelo identificador; identificadores ausentes resultam em nil.
+type BatchFunc func(ctx context.Context, ids []string) (map[string]interface{}, error)
+
+// entry é o resultado, em cache, do carregamento de um identificador.
+type entry struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// batch é um grupo de identificadores aguardando o carregamento.
+type batch struct {
+	ids     []string
+	entries []*entry
+	timer   *time.Timer
+}
+
+// Loader agrupa e armazena em cache os carregamentos por identificador.
+// Deve ser criado por requisição, pois o cache não é invalidado automaticamente.
+type Loader struct {
+	ctx      context.Context
+	batchFn  BatchFunc
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	cache   map[string]*entry
+	pending *batch
+}
+
+// New cria um Loader. Wait e maxBatch menores ou iguais a zero utilizam os valores padrão.
+func New(ctx context.Context, batchFn BatchFunc, wait time.Duration, maxBatch int) *Loader {
+	if wait <= 0 {
+		wait = DefaultWait
+	}
+	if maxBatch <= 0 {
+		maxBatch = DefaultMaxBatch
+	}
+	return &Loader{
+		ctx:      ctx,
+		batchFn:  batchFn,
+		wait:     wait,
+		maxBatch: maxBatch,
+		cache:    map[string]*entry{},
+	}
+}
+
+// Load carrega o documento do identificador, agrupando-o com os demais carregamentos do intervalo.
+func (l *Loader) Load(ctx context.Context, id string) (interface{}, error) {
+	item := l.enqueue(id)
+	select {
+	case <-item.done:
+		return item.value, item.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// LoadMany carrega os documentos dos identificadores, retornando-os na ordem solicitada.
+func (l *Loader) LoadMany(ctx context.Context, ids []string) ([]interface{}, error) {
+	items := make([]*entry, len(ids))
+	for index, id := range ids {
+		items[index] = l.enqueue(id)
+	}
+
+	result := make([]interface{}, len(ids))
+	for index, item := range items {
+		select {
+		case <-item.done:
+			if item.err != nil {
+				return nil, item.err
+			}
+			result[index] = item.value
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return result, nil
+}
+
+// LoadInput carrega os documentos de um ApiIdentifiersSearchInput.
+func (l *Loader) LoadInput(ctx context.Context, input *params.ApiIdentifiersSearchInput) ([]interface{}, error) {
+	if input == nil {
+		return []interface{}{}, nil
+	}
+	return l.LoadMany(ctx, input.Ids)
+}
+
+// Prime adiciona um documento ao cache, por exemplo após uma consulta de lista.
+func (l *Loader) Prime(id string, value interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.cache[id]; ok {
+		return
+	}
+	item := &entry{done: make(chan struct{}), value: value}
+	close(item.done)
+	l.cache[id] = item
+}
+
+// Clear remove um identificador do cache, por exemplo após uma mutação.
+func (l *Loader) Clear(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.cache, id)
+}
+
+// enqueue retorna a entrada em cache do identificador ou a adiciona ao grupo pendente.
+func (l *Loader) enqueue(id string) *entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if item, ok := l.cache[id]; ok {
+		return item
+	}
+
+	item := &entry{done: make(chan struct{})}
+	l.cache[id] = item
+
+	if l.pending == nil {
+		current := &batch{}
+		current.timer = time.AfterFunc(l.wait, func() {
+			l.dispatch(current)
+		})
+		l.pending = current
+	}
+	l.pending.ids = append(l.pending.ids, id)
+	l.pending.entries = append(l.pending.entries, item)
+
+	if len(l.pending.ids) >= l.maxBatch {
+		current := l.pending
+		current.timer.Stop()
+		l.pending = nil
+		go l.execute(current)
+	}
+	return item
+}
+
+// dispatch executa o grupo quando o intervalo de espera termina.
+func (l *Loader) dispatch(current *batch) {
+	l.mu.Lock()
+	if l.pending != current {
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mu.Unlock()
+	l.execute(current)
+}
+
+// execute chama a BatchFunc e distribui os resultados às entradas do grupo.
+func (l *Loader) execute(current *batch) {
+	values, err := l.call(current.ids)
+	for index, item := range current.entries {
+		if err != nil {
+			item.err = err
+		} else {
+			item.value = values[current.ids[index]]
+		}
+		close(item.done)
+	}
+
+	// Erros não ficam em cache, permitindo uma nova tentativa
+	if err != nil {
+		l.mu.Lock()
+		for index, id := range current.ids {
+			if l.cache[id] == current.entries[index] {
+				delete(l.cache, id)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// call executa a BatchFunc, convertendo um panic em erro para que as entradas do grupo sejam sempre concluídas.
+func (l *Loader) call(ids []string) (values map[string]interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Falha ao carregar os documentos %v: panic: %v", ids, recovered)
+			values = nil
+			err = api_error.New(api_error.CodeInternal, module, "batch", "falha ao carregar os documentos").
+				With("panic", fmt.Sprint(recovered))
+		}
+	}()
+	return l.batchFn(l.ctx, ids)
+}
+
+// Registry registra as BatchFunc por coleção/tabela e cria os Loader de cada requisição.
+type Registry struct {
+	mu       sync.RWMutex
+	batches  map[string]BatchFunc
+	wait     time.Duration
+	maxBatch int
+}
+
+// NewRegistry cria um registro vazio. Wait e maxBatch menores ou iguais a zero utilizam os valores padrão.
+func NewRegistry(wait time.Duration, maxBatch int) *Registry {
+	return &Registry{batches: map[string]BatchFunc{}, wait: wait, maxBatch: maxBatch}
+}
+
+// Register registra a BatchFunc com o nome da coleção/tabela.
+func (r *Registry) Register(name string, batchFn BatchFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches[name] = batchFn
+}
+
+// Loaders são os Loader de uma requisição, criados sob demanda a partir do Registry.
+type Loaders struct {
+	ctx      context.Context
+	registry *Registry
+	mu       sync.Mutex
+	loaders  map[string]*Loader
+}
+
+// Middleware adiciona ao contexto de cada requisição um novo conjunto de Loaders.
+func (r *Registry) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(r.WithLoaders(req.Context())))
+		})
+	}
+}
+
+// WithLoaders retorna um novo contexto com um conjunto de Loaders vazio.
+func (r *Registry) WithLoaders(ctx context.Context) context.Context {
+	loaders := &Loaders{registry: r, loaders: map[string]*Loader{}}
+	ctx = context.WithValue(ctx, loadersCtxKey, loaders)
+	loaders.ctx = ctx
+	return ctx
+}
+
+// For retorna o Loader da coleção/tabela para a requisição do contexto.
+// Retorna nil se o middleware não foi executado ou se o nome não foi registrado.
+func For(ctx context.Context, name string) *Loader {
+	loaders, ok := ctx.Value(loadersCtxKey).(*Loaders)
+	if !ok {
+		return nil
+	}
+
+	loaders.mu.Lock()
+	defer loaders.mu.Unlock()
+
+	if loader, ok := loaders.loaders[name]; ok {
+		return loader
+	}
+
+	loaders.registry.mu.RLock()
+	batchFn, ok := loaders.registry.batches[name]
+	loaders.registry.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	loader := New(loaders.ctx, batchFn, loaders.registry.wait, loaders.registry.maxBatch)
+	loaders.loaders[name] = loader
+	return loader
+}
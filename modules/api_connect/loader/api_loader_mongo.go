@@ -0,0 +1,83 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoBatch cria uma BatchFunc que carrega os documentos com uma única consulta {_id: {$in: ids}}
+// em uma coleção obtida por MongoDB.Collection.
+// Se useObjectID for verdadeiro, os identificadores são convertidos para ObjectID.
+// decode converte cada documento; quando nil, os documentos são retornados como bson.Raw.
+func MongoBatch(collection *mongo.Collection, useObjectID bool, decode func(document bson.Raw) (interface{}, error)) BatchFunc {
+	return func(ctx context.Context, ids []string) (map[string]interface{}, error) {
+		values, requested := mongoIDs(ids, useObjectID)
+
+		result := make(map[string]interface{}, len(ids))
+		if len(values) == 0 {
+			return result, nil
+		}
+
+		cursor, err := collection.Find(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: values}}}})
+		if err != nil {
+			return nil, err
+		}
+
+		var documents []bson.Raw
+		if err = cursor.All(ctx, &documents); err != nil {
+			return nil, err
+		}
+
+		for _, document := range documents {
+			var value interface{} = document
+			if decode != nil {
+				if value, err = decode(document); err != nil {
+					return nil, err
+				}
+			}
+			for _, id := range requested[mongoKey(document.Lookup("_id"))] {
+				result[id] = value
+			}
+		}
+		return result, nil
+	}
+}
+
+// mongoIDs converte os identificadores solicitados para os valores de _id da consulta, sem repetições.
+// requested associa a chave de cada _id, como retornada por mongoKey, aos identificadores solicitados:
+// ObjectID.Hex retorna letras minúsculas, então "65A1..." e "65a1..." correspondem ao mesmo documento.
+// Identificadores que não são ObjectID válidos são ignorados quando useObjectID é verdadeiro.
+func mongoIDs(ids []string, useObjectID bool) (bson.A, map[string][]string) {
+	values := make(bson.A, 0, len(ids))
+	requested := make(map[string][]string, len(ids))
+	for _, id := range ids {
+		key := id
+		var value interface{} = id
+		if useObjectID {
+			objectID, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				continue
+			}
+			key, value = objectID.Hex(), objectID
+		}
+		if _, ok := requested[key]; !ok {
+			values = append(values, value)
+		}
+		requested[key] = append(requested[key], id)
+	}
+	return values, requested
+}
+
+// mongoKey converte o _id do documento para o identificador utilizado no Loader.
+func mongoKey(value bson.RawValue) string {
+	if objectID, ok := value.ObjectIDOK(); ok {
+		return objectID.Hex()
+	}
+	if text, ok := value.StringValueOK(); ok {
+		return text
+	}
+	return fmt.Sprint(value)
+}
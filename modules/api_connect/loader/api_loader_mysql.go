@@ -0,0 +1,52 @@
+package loader
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"strings"
+)
+
+// MysqlBatch cria uma BatchFunc que carrega as linhas com uma única consulta WHERE id IN (...).
+// As linhas são retornadas como map[string]interface{}.
+func MysqlBatch(db *mysql.MysqlDB, table string, idColumn string) BatchFunc {
+	return func(ctx context.Context, ids []string) (map[string]interface{}, error) {
+		result := make(map[string]interface{}, len(ids))
+		if len(ids) == 0 {
+			return result, nil
+		}
+
+		tableQuoted, err := mysql.QuoteIdentifier(table)
+		if err != nil {
+			return nil, err
+		}
+		idQuoted, err := mysql.QuoteIdentifier(idColumn)
+		if err != nil {
+			return nil, err
+		}
+
+		args := make([]interface{}, len(ids))
+		for index, id := range ids {
+			args[index] = id
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+
+		rows, err := db.Client().QueryContext(ctx, "SELECT * FROM "+tableQuoted+" WHERE "+idQuoted+" IN ("+placeholders+")", args...)
+		if err != nil {
+			return nil, err
+		}
+		defer func(rows *sql.Rows) {
+			_ = rows.Close()
+		}(rows)
+
+		documents, err := mysql.ScanMaps(rows)
+		if err != nil {
+			return nil, err
+		}
+		for _, document := range documents {
+			result[fmt.Sprint(document[idColumn])] = document
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,99 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadManyBatches(t *testing.T) {
+	var mu sync.Mutex
+	var calls [][]string
+	loader := New(context.Background(), func(ctx context.Context, ids []string) (map[string]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		sorted := append([]string(nil), ids...)
+		sort.Strings(sorted)
+		calls = append(calls, sorted)
+		values := map[string]interface{}{}
+		for _, id := range ids {
+			if id != "missing" {
+				values[id] = "doc-" + id
+			}
+		}
+		return values, nil
+	}, 10*time.Millisecond, 0)
+
+	values, err := loader.LoadMany(context.Background(), []string{"b", "a", "missing", "a"})
+	if err != nil {
+		t.Fatalf("LoadMany: %v", err)
+	}
+	if want := []interface{}{"doc-b", "doc-a", nil, "doc-a"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("LoadMany = %v, want %v", values, want)
+	}
+	if value, _ := loader.Load(context.Background(), "a"); value != "doc-a" {
+		t.Errorf("Load = %v, want doc-a from the cache", value)
+	}
+	if want := [][]string{{"a", "b", "missing"}}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestLoadRecoversPanic(t *testing.T) {
+	calls := 0
+	loader := New(context.Background(), func(ctx context.Context, ids []string) (map[string]interface{}, error) {
+		calls++
+		if calls == 1 {
+			panic("connection lost")
+		}
+		return map[string]interface{}{"a": "doc-a"}, nil
+	}, 0, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// O grupo completo é executado em outra goroutine, sem o intervalo de espera
+	_, err := loader.LoadMany(ctx, []string{"a", "b"})
+	if !errors.Is(err, api_error.New(api_error.CodeInternal, "", "")) {
+		t.Fatalf("LoadMany = %v, want CodeInternal", err)
+	}
+
+	value, err := loader.Load(ctx, "a")
+	if err != nil || value != "doc-a" {
+		t.Errorf("Load after panic = %v, %v, want doc-a", value, err)
+	}
+}
+
+func TestMongoIDs(t *testing.T) {
+	const lower, upper = "65a1b2c3d4e5f60718293a4b", "65A1B2C3D4E5F60718293A4B"
+	objectID, _ := primitive.ObjectIDFromHex(lower)
+
+	values, requested := mongoIDs([]string{upper, lower, "invalido", upper}, true)
+	if !reflect.DeepEqual(values, bson.A{objectID}) {
+		t.Errorf("values = %v, want [%s]", values, lower)
+	}
+	if want := map[string][]string{lower: {upper, lower, upper}}; !reflect.DeepEqual(requested, want) {
+		t.Errorf("requested = %v, want %v", requested, want)
+	}
+
+	// A chave do documento retornado corresponde a todos os identificadores solicitados
+	document, err := bson.Marshal(bson.D{{Key: "_id", Value: objectID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := mongoKey(bson.Raw(document).Lookup("_id")); !reflect.DeepEqual(requested[key], []string{upper, lower, upper}) {
+		t.Errorf("requested[%s] = %v", key, requested[key])
+	}
+
+	values, requested = mongoIDs([]string{"a", "A", "a"}, false)
+	if !reflect.DeepEqual(values, bson.A{"a", "A"}) || !reflect.DeepEqual(requested, map[string][]string{"a": {"a", "a"}, "A": {"A"}}) {
+		t.Errorf("mongoIDs without ObjectID = %v, %v", values, requested)
+	}
+}
@@ -0,0 +1,68 @@
+// Package order converte listas de ApiSortInput em ordenações do MongoDB e cláusulas ORDER BY do MySQL.
+// Somente campos presentes na lista de campos ordenáveis (Fields) são aceitos e um campo único de desempate
+// é adicionado ao final, mantendo a paginação determinística.
+package order
+
+import (
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+)
+
+// MaxFields é a quantidade máxima de campos de ordenação.
+const MaxFields = 5
+
+const module = "api_connect/order"
+
+// Fields é a lista de campos ordenáveis: o nome exposto ao cliente e o campo/coluna correspondente no banco.
+type Fields map[string]string
+
+// Key é um campo de ordenação já validado.
+type Key struct {
+	Field string
+	Desc  bool
+}
+
+// Keys valida a lista de ApiSortInput e adiciona o campo de desempate (tiebreaker) na direção do último campo.
+func Keys(inputs []*params.ApiSortInput, fields Fields, tiebreaker string) ([]Key, error) {
+	if len(inputs) > MaxFields {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "orderBy", "quantidade de campos de ordenação excede o limite").
+			With("max", MaxFields)
+	}
+
+	keys := make([]Key, 0, len(inputs)+1)
+	seen := map[string]bool{}
+	for _, input := range inputs {
+		field, ok := fields[input.Field]
+		if !ok {
+			return nil, api_error.New(api_error.CodeInvalidArgument, module, "orderBy", "campo não permitido na ordenação").
+				With("field", input.Field)
+		}
+		if seen[field] {
+			return nil, api_error.New(api_error.CodeInvalidArgument, module, "orderBy", "campo de ordenação repetido").
+				With("field", input.Field)
+		}
+
+		var desc bool
+		switch input.Direction {
+		case status.StatusDirectionSortEnumAsc:
+		case status.StatusDirectionSortEnumDesc:
+			desc = true
+		default:
+			return nil, api_error.New(api_error.CodeInvalidArgument, module, "orderBy", "direção de ordenação inválida").
+				With("direction", string(input.Direction))
+		}
+
+		seen[field] = true
+		keys = append(keys, Key{Field: field, Desc: desc})
+	}
+
+	if tiebreaker != "" && !seen[tiebreaker] {
+		desc := false
+		if len(keys) > 0 {
+			desc = keys[len(keys)-1].Desc
+		}
+		keys = append(keys, Key{Field: tiebreaker, Desc: desc})
+	}
+	return keys, nil
+}
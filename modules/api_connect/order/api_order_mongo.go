@@ -0,0 +1,24 @@
+package order
+
+import (
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Mongo converte a ordenação em um documento sort do MongoDB. O desempate padrão é "_id".
+func Mongo(inputs []*params.ApiSortInput, fields Fields) (bson.D, error) {
+	keys, err := Keys(inputs, fields, "_id")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(bson.D, 0, len(keys))
+	for _, key := range keys {
+		direction := 1
+		if key.Desc {
+			direction = -1
+		}
+		result = append(result, bson.E{Key: key.Field, Value: direction})
+	}
+	return result, nil
+}
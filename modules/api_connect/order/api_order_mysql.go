@@ -0,0 +1,32 @@
+package order
+
+import (
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"strings"
+)
+
+// Mysql converte a ordenação em uma cláusula "ORDER BY ...", utilizando idColumn como desempate.
+func Mysql(inputs []*params.ApiSortInput, fields Fields, idColumn string) (string, error) {
+	keys, err := Keys(inputs, fields, idColumn)
+	if err != nil {
+		return "", err
+	}
+	if len(keys) == 0 {
+		return "", nil
+	}
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		column, err := mysql.QuoteIdentifier(key.Field)
+		if err != nil {
+			return "", err
+		}
+		if key.Desc {
+			parts = append(parts, column+" DESC")
+		} else {
+			parts = append(parts, column+" ASC")
+		}
+	}
+	return "ORDER BY " + strings.Join(parts, ", "), nil
+}
@@ -0,0 +1,103 @@
+package order
+
+import (
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"go.mongodb.org/mongo-driver/bson"
+	"reflect"
+	"testing"
+)
+
+var fields = Fields{"name": "name", "createdAt": "_info.createdAt", "id": "_id"}
+
+// sort cria um ApiSortInput.
+func sort(field string, direction status.StatusDirectionSortEnum) *params.ApiSortInput {
+	return &params.ApiSortInput{Field: field, Direction: direction}
+}
+
+func TestMongo(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs []*params.ApiSortInput
+		want   bson.D
+	}{
+		{"empty", nil, bson.D{{Key: "_id", Value: 1}}},
+		{
+			name:   "tiebreaker in the last direction",
+			inputs: []*params.ApiSortInput{sort("name", status.StatusDirectionSortEnumAsc), sort("createdAt", status.StatusDirectionSortEnumDesc)},
+			want:   bson.D{{Key: "name", Value: 1}, {Key: "_info.createdAt", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			name:   "tiebreaker already present",
+			inputs: []*params.ApiSortInput{sort("id", status.StatusDirectionSortEnumDesc), sort("name", status.StatusDirectionSortEnumAsc)},
+			want:   bson.D{{Key: "_id", Value: -1}, {Key: "name", Value: 1}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Mongo(test.inputs, fields)
+			if err != nil {
+				t.Fatalf("Mongo: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Mongo = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMysql(t *testing.T) {
+	columns := Fields{"name": "name", "createdAt": "created_at"}
+	tests := []struct {
+		name     string
+		inputs   []*params.ApiSortInput
+		idColumn string
+		want     string
+	}{
+		{"empty", nil, "id", "ORDER BY `id` ASC"},
+		{"without tiebreaker", nil, "", ""},
+		{
+			name:     "fields and tiebreaker",
+			inputs:   []*params.ApiSortInput{sort("createdAt", status.StatusDirectionSortEnumDesc), sort("name", status.StatusDirectionSortEnumAsc)},
+			idColumn: "id",
+			want:     "ORDER BY `created_at` DESC, `name` ASC, `id` ASC",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Mysql(test.inputs, columns, test.idColumn)
+			if err != nil {
+				t.Fatalf("Mysql: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("Mysql = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestKeysInvalid(t *testing.T) {
+	tooMany := make([]*params.ApiSortInput, MaxFields+1)
+	for index := range tooMany {
+		tooMany[index] = sort("name", status.StatusDirectionSortEnumAsc)
+	}
+
+	tests := []struct {
+		name   string
+		inputs []*params.ApiSortInput
+	}{
+		{"too many fields", tooMany},
+		{"field not allowed", []*params.ApiSortInput{sort("password", status.StatusDirectionSortEnumAsc)}},
+		{"repeated field", []*params.ApiSortInput{sort("name", status.StatusDirectionSortEnumAsc), sort("name", status.StatusDirectionSortEnumDesc)}},
+		{"invalid direction", []*params.ApiSortInput{sort("name", "UP")}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Keys(test.inputs, fields, "_id"); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+				t.Errorf("Keys = %v, want CodeInvalidArgument", err)
+			}
+		})
+	}
+}
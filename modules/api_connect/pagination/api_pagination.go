@@ -0,0 +1,91 @@
+// Package pagination interpreta ApiPaginationInput e produz ApiPageInfo para os adaptadores MongoDB e MySQL.
+package pagination
+
+import (
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+)
+
+// Limites padrão de tamanho de página.
+const (
+	DefaultSize = 20
+	MaxSize     = 100
+)
+
+const module = "api_connect/pagination"
+
+// Page é a representação normalizada de uma ApiPaginationInput.
+type Page struct {
+	Size   int
+	Page   int
+	Offset int
+}
+
+// Normalize valida a ApiPaginationInput e calcula o deslocamento da página.
+// Se input for nil, retorna a primeira página com DefaultSize documentos.
+// Se maxSize for menor ou igual a zero, utiliza MaxSize.
+func Normalize(input *params.ApiPaginationInput, maxSize int) (Page, error) {
+	if maxSize <= 0 {
+		maxSize = MaxSize
+	}
+	if input == nil {
+		size := DefaultSize
+		if size > maxSize {
+			size = maxSize
+		}
+		return Page{Size: size, Page: 1}, nil
+	}
+
+	if input.Page < 1 {
+		return Page{}, api_error.New(api_error.CodeInvalidArgument, module, "page", "page deve ser maior ou igual a 1").
+			With("page", input.Page)
+	}
+	if input.Size < 1 || input.Size > maxSize {
+		return Page{}, api_error.New(api_error.CodeInvalidArgument, module, "size", "size deve estar entre 1 e o limite permitido").
+			With("size", input.Size).
+			With("max", maxSize)
+	}
+
+	return Page{
+		Size:   input.Size,
+		Page:   input.Page,
+		Offset: (input.Page - 1) * input.Size,
+	}, nil
+}
+
+// Paginator retorna o ApiPaginator correspondente à página, com o número de documentos retornados.
+func (p Page) Paginator(length int) *params.ApiPaginator {
+	limit := p.Size
+	offset := p.Offset
+	return &params.ApiPaginator{
+		Length: &length,
+		Limit:  &limit,
+		Offset: &offset,
+	}
+}
+
+// PageInfo monta o ApiPageInfo a partir do total de documentos encontrados.
+func (p Page) PageInfo(total int) *params.ApiPageInfo {
+	size := p.Size
+	page := p.Page
+	nextPage := p.Offset+p.Size < total
+	previousPage := p.Page > 1
+	return &params.ApiPageInfo{
+		Has: &params.ApiCursorSearch{
+			NextPage:     &nextPage,
+			PreviousPage: &previousPage,
+		},
+		Total: &total,
+		Size:  &size,
+		Page:  &page,
+	}
+}
+
+// knownTotal evita a consulta de contagem quando o total pode ser deduzido dos documentos retornados,
+// ou seja, quando a página não está cheia e não está além do fim da lista.
+func (p Page) knownTotal(length int) (int, bool) {
+	if length < p.Size && (length > 0 || p.Page == 1) {
+		return p.Offset + length, true
+	}
+	return 0, false
+}
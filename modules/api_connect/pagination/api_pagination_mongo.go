@@ -0,0 +1,72 @@
+package pagination
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"reflect"
+)
+
+// FindOptions retorna as opções skip/limit do MongoDB para a página.
+func (p Page) FindOptions() *options.FindOptions {
+	return options.Find().SetSkip(int64(p.Offset)).SetLimit(int64(p.Size))
+}
+
+// CountMongo retorna o total de documentos do filtro.
+// Para filtros vazios utiliza EstimatedDocumentCount, que lê os metadados da coleção.
+func CountMongo(ctx context.Context, collection *mongo.Collection, filter interface{}) (int, error) {
+	if isEmptyFilter(filter) {
+		total, err := collection.EstimatedDocumentCount(ctx)
+		return int(total), err
+	}
+	total, err := collection.CountDocuments(ctx, filter)
+	return int(total), err
+}
+
+// FindMongo executa uma consulta paginada em uma coleção obtida por MongoDB.Collection.
+// Os documentos são decodificados em results, que deve ser um ponteiro para slice.
+// Opções adicionais (ordenação, projeção) podem ser informadas em opts.
+func FindMongo(ctx context.Context, collection *mongo.Collection, filter interface{}, input *params.ApiPaginationInput, results interface{}, opts ...*options.FindOptions) (*params.ApiPageInfo, error) {
+	page, err := Normalize(input, MaxSize)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		filter = bson.D{}
+	}
+
+	findOptions := append([]*options.FindOptions{}, opts...)
+	findOptions = append(findOptions, page.FindOptions())
+	cursor, err := collection.Find(ctx, filter, findOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, results); err != nil {
+		return nil, err
+	}
+
+	length := reflect.Indirect(reflect.ValueOf(results)).Len()
+	total, ok := page.knownTotal(length)
+	if !ok {
+		if total, err = CountMongo(ctx, collection, filter); err != nil {
+			return nil, err
+		}
+	}
+
+	return page.PageInfo(total), nil
+}
+
+// isEmptyFilter verifica se o filtro não possui condições.
+func isEmptyFilter(filter interface{}) bool {
+	switch value := filter.(type) {
+	case nil:
+		return true
+	case bson.D:
+		return len(value) == 0
+	case bson.M:
+		return len(value) == 0
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package pagination
+
+import (
+	"context"
+	"database/sql"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+)
+
+// LimitOffset adiciona LIMIT/OFFSET à consulta SQL e retorna os argumentos com os valores da página.
+func (p Page) LimitOffset(query string, args []interface{}) (string, []interface{}) {
+	result := make([]interface{}, 0, len(args)+2)
+	result = append(result, args...)
+	result = append(result, p.Size, p.Offset)
+	return query + " LIMIT ? OFFSET ?", result
+}
+
+// CountMysql retorna o total de linhas da consulta SQL informada.
+func CountMysql(ctx context.Context, db *mysql.MysqlDB, query string, args []interface{}) (int, error) {
+	var total int
+	err := db.Client().QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+query+") AS _pagination", args...).Scan(&total)
+	return total, err
+}
+
+// QueryMysql executa uma consulta paginada no MysqlDB.
+// A consulta não deve conter LIMIT/OFFSET; scan é chamado para cada linha retornada.
+func QueryMysql(ctx context.Context, db *mysql.MysqlDB, query string, args []interface{}, input *params.ApiPaginationInput, scan func(rows *sql.Rows) error) (*params.ApiPageInfo, error) {
+	page, err := Normalize(input, MaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	pageQuery, pageArgs := page.LimitOffset(query, args)
+	rows, err := db.Client().QueryContext(ctx, pageQuery, pageArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	length := 0
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return nil, err
+		}
+		length++
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	total, ok := page.knownTotal(length)
+	if !ok {
+		if total, err = CountMysql(ctx, db, query, args); err != nil {
+			return nil, err
+		}
+	}
+
+	return page.PageInfo(total), nil
+}
@@ -0,0 +1,144 @@
+package pagination
+
+import (
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   *params.ApiPaginationInput
+		maxSize int
+		want    Page
+		fails   bool
+	}{
+		{name: "nil", input: nil, maxSize: 0, want: Page{Size: DefaultSize, Page: 1}},
+		{name: "nil with a smaller limit", input: nil, maxSize: 5, want: Page{Size: 5, Page: 1}},
+		{name: "first page", input: &params.ApiPaginationInput{Size: 10, Page: 1}, want: Page{Size: 10, Page: 1}},
+		{name: "third page", input: &params.ApiPaginationInput{Size: 10, Page: 3}, want: Page{Size: 10, Page: 3, Offset: 20}},
+		{name: "size at MaxSize", input: &params.ApiPaginationInput{Size: MaxSize, Page: 2}, want: Page{Size: MaxSize, Page: 2, Offset: MaxSize}},
+		{name: "size above MaxSize", input: &params.ApiPaginationInput{Size: MaxSize + 1, Page: 1}, fails: true},
+		{name: "size above the limit", input: &params.ApiPaginationInput{Size: 6, Page: 1}, maxSize: 5, fails: true},
+		{name: "size zero", input: &params.ApiPaginationInput{Size: 0, Page: 1}, fails: true},
+		{name: "negative size", input: &params.ApiPaginationInput{Size: -1, Page: 1}, fails: true},
+		{name: "page zero", input: &params.ApiPaginationInput{Size: 10, Page: 0}, fails: true},
+		{name: "negative page", input: &params.ApiPaginationInput{Size: 10, Page: -2}, fails: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Normalize(test.input, test.maxSize)
+			if test.fails {
+				if !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+					t.Errorf("Normalize = %+v, %v, want INVALID_ARGUMENT", got, err)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("Normalize = %+v, %v, want %+v", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestKnownTotal(t *testing.T) {
+	tests := []struct {
+		name   string
+		page   Page
+		length int
+		total  int
+		known  bool
+	}{
+		{name: "empty list", page: Page{Size: 10, Page: 1}, length: 0, total: 0, known: true},
+		{name: "partial first page", page: Page{Size: 10, Page: 1}, length: 4, total: 4, known: true},
+		{name: "last partial page", page: Page{Size: 10, Page: 3, Offset: 20}, length: 7, total: 27, known: true},
+		{name: "full page", page: Page{Size: 10, Page: 2, Offset: 10}, length: 10, known: false},
+		{name: "past the end", page: Page{Size: 10, Page: 5, Offset: 40}, length: 0, known: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			total, known := test.page.knownTotal(test.length)
+			if total != test.total || known != test.known {
+				t.Errorf("knownTotal(%d) = %d, %v, want %d, %v", test.length, total, known, test.total, test.known)
+			}
+		})
+	}
+}
+
+func TestPageInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     Page
+		total    int
+		next     bool
+		previous bool
+	}{
+		{name: "single page", page: Page{Size: 10, Page: 1}, total: 10},
+		{name: "first of two pages", page: Page{Size: 10, Page: 1}, total: 11, next: true},
+		{name: "last partial page", page: Page{Size: 10, Page: 2, Offset: 10}, total: 11, previous: true},
+		{name: "middle page", page: Page{Size: 10, Page: 2, Offset: 10}, total: 30, next: true, previous: true},
+		{name: "past the end", page: Page{Size: 10, Page: 5, Offset: 40}, total: 30, previous: true},
+		{name: "empty list", page: Page{Size: 10, Page: 1}, total: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := test.page.PageInfo(test.total)
+			if *info.Has.NextPage != test.next || *info.Has.PreviousPage != test.previous {
+				t.Errorf("Has = next %v previous %v, want next %v previous %v", *info.Has.NextPage, *info.Has.PreviousPage, test.next, test.previous)
+			}
+			if *info.Total != test.total || *info.Size != test.page.Size || *info.Page != test.page.Page {
+				t.Errorf("PageInfo = total %d size %d page %d", *info.Total, *info.Size, *info.Page)
+			}
+		})
+	}
+}
+
+func TestPaginator(t *testing.T) {
+	paginator := Page{Size: 10, Page: 3, Offset: 20}.Paginator(7)
+	if *paginator.Length != 7 || *paginator.Limit != 10 || *paginator.Offset != 20 {
+		t.Errorf("Paginator = length %d limit %d offset %d", *paginator.Length, *paginator.Limit, *paginator.Offset)
+	}
+}
+
+func TestLimitOffset(t *testing.T) {
+	args := []interface{}{"ENABLED"}
+	query, got := Page{Size: 10, Page: 3, Offset: 20}.LimitOffset("SELECT * FROM `products` WHERE `status` = ?", args)
+	if query != "SELECT * FROM `products` WHERE `status` = ? LIMIT ? OFFSET ?" {
+		t.Errorf("query = %q", query)
+	}
+	if want := []interface{}{"ENABLED", 10, 20}; !reflect.DeepEqual(got, want) {
+		t.Errorf("args = %v, want %v", got, want)
+	}
+	if len(args) != 1 {
+		t.Errorf("LimitOffset changed the original args: %v", args)
+	}
+}
+
+func TestFindOptions(t *testing.T) {
+	options := Page{Size: 10, Page: 3, Offset: 20}.FindOptions()
+	if *options.Skip != 20 || *options.Limit != 10 {
+		t.Errorf("FindOptions = skip %d limit %d, want skip 20 limit 10", *options.Skip, *options.Limit)
+	}
+}
+
+func TestIsEmptyFilter(t *testing.T) {
+	tests := []struct {
+		filter interface{}
+		want   bool
+	}{
+		{nil, true},
+		{bson.D{}, true},
+		{bson.M{}, true},
+		{bson.D{{Key: "status", Value: "ENABLED"}}, false},
+		{bson.M{"status": "ENABLED"}, false},
+	}
+	for _, test := range tests {
+		if got := isEmptyFilter(test.filter); got != test.want {
+			t.Errorf("isEmptyFilter(%v) = %v, want %v", test.filter, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Package params contém a representação Go dos tipos GraphQL definidos em modules/api_connect/params/schemas.
+//
+// Para que o gqlgen utilize os tipos deste pacote, configure no gqlgen.yml, por exemplo:
+//
+//	models:
+//	  ApiPageInfo:
+//	    model: github.com/coocree/coocree_apiconnect_go/modules/api_connect/params.ApiPageInfo
+package params
+
+import (
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"time"
+)
+
+// ApiPaginationInput define o tamanho e a página de uma consulta paginada.
+type ApiPaginationInput struct {
+	Size int `json:"size"`
+	Page int `json:"page"`
+}
+
+// ApiPaginator descreve o intervalo de documentos retornados em uma consulta paginada.
+type ApiPaginator struct {
+	Length *int `json:"length"`
+	Limit  *int `json:"limit"`
+	Offset *int `json:"offset"`
+}
+
+// ApiCursorSearch indica se há mais itens para paginar para frente ou para trás.
+type ApiCursorSearch struct {
+	NextPage     *bool `json:"nextPage"`
+	PreviousPage *bool `json:"previousPage"`
+}
+
+// ApiPageInfo retorna as informações de paginação de uma consulta.
+type ApiPageInfo struct {
+	Has   *ApiCursorSearch `json:"has"`
+	Total *int             `json:"total"`
+	Size  *int             `json:"size"`
+	Page  *int             `json:"page"`
+}
+
+// CursorPositionEnum indica a direção da paginação por cursor.
+type CursorPositionEnum string
+
+const (
+	CursorPositionEnumPrevious CursorPositionEnum = "previous"
+	CursorPositionEnumNext     CursorPositionEnum = "next"
+)
+
+// ApiPositionSearchInput filtra documentos pelo posicionamento do cursor de busca.
+type ApiPositionSearchInput struct {
+	First  *int    `json:"first"`
+	Last   *int    `json:"last"`
+	After  *string `json:"after"`
+	Before *string `json:"before"`
+}
+
+// ApiConnectionPageInfo retorna as informações de paginação por cursor no formato Relay.
+type ApiConnectionPageInfo struct {
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+}
+
+// ApiConnectionEdge é um elemento de uma lista paginada por cursor.
+type ApiConnectionEdge struct {
+	Cursor string      `json:"cursor"`
+	Node   interface{} `json:"node"`
+}
+
+// ApiConnection é uma lista paginada por cursor no formato Relay.
+type ApiConnection struct {
+	Edges    []*ApiConnectionEdge   `json:"edges"`
+	PageInfo *ApiConnectionPageInfo `json:"pageInfo"`
+}
+
+// StatusCloneTypeEnum indica se o documento pode ser clonado por outros usuários.
+type StatusCloneTypeEnum string
+
+const (
+	StatusCloneTypeEnumPublic  StatusCloneTypeEnum = "public"
+	StatusCloneTypeEnumPrivate StatusCloneTypeEnum = "private"
+)
+
+// ComparisonQueryOperators são os operadores de comparação de filtros. O sufixo do valor é o operador do MongoDB.
+type ComparisonQueryOperators string
+
+const (
+	ComparisonQueryOperatorsEqualEq               ComparisonQueryOperators = "EQUAL_eq"
+	ComparisonQueryOperatorsGreaterThanGt         ComparisonQueryOperators = "GREATER_THAN_gt"
+	ComparisonQueryOperatorsGreaterThanOrEqualGte ComparisonQueryOperators = "GREATER_THAN_OR_EQUAL_gte"
+	ComparisonQueryOperatorsInArrayIn             ComparisonQueryOperators = "IN_ARRAY_in"
+	ComparisonQueryOperatorsLessThanLt            ComparisonQueryOperators = "LESS_THAN_lt"
+	ComparisonQueryOperatorsLessThanOrEqualLte    ComparisonQueryOperators = "LESS_THAN_OR_EQUAL_lte"
+	ComparisonQueryOperatorsNotEqualNe            ComparisonQueryOperators = "NOT_EQUAL_ne"
+	ComparisonQueryOperatorsNotInArrayNin         ComparisonQueryOperators = "NOT_IN_ARRAY_nin"
+)
+
+// Operator retorna o operador MongoDB codificado no sufixo do valor, por exemplo "$gte".
+func (e ComparisonQueryOperators) Operator() string {
+	value := string(e)
+	for index := len(value) - 1; index >= 0; index-- {
+		if value[index] == '_' {
+			return "$" + value[index+1:]
+		}
+	}
+	return ""
+}
+
+// ApiFilterConditionInput é uma condição de filtro de um campo.
+type ApiFilterConditionInput struct {
+	Field    string                   `json:"field"`
+	Operator ComparisonQueryOperators `json:"operator"`
+	Value    interface{}              `json:"value"`
+}
+
+// ApiFilterInput é um grupo de condições de filtro combinadas por AND/OR.
+type ApiFilterInput struct {
+	And    []*ApiFilterConditionInput `json:"and"`
+	Or     []*ApiFilterConditionInput `json:"or"`
+	Groups []*ApiFilterInput          `json:"groups"`
+}
+
+// DateStartComparisonOperators são os operadores de comparação da data inicial de um intervalo.
+type DateStartComparisonOperators string
+
+const (
+	DateStartComparisonOperatorsEqualEq               DateStartComparisonOperators = "EQUAL_eq"
+	DateStartComparisonOperatorsGreaterThanGt         DateStartComparisonOperators = "GREATER_THAN_gt"
+	DateStartComparisonOperatorsGreaterThanOrEqualGte DateStartComparisonOperators = "GREATER_THAN_OR_EQUAL_gte"
+)
+
+// DateEndComparisonOperators são os operadores de comparação da data final de um intervalo.
+type DateEndComparisonOperators string
+
+const (
+	DateEndComparisonOperatorsEqualEq            DateEndComparisonOperators = "EQUAL_eq"
+	DateEndComparisonOperatorsLessThanLt         DateEndComparisonOperators = "LESS_THAN_lt"
+	DateEndComparisonOperatorsLessThanOrEqualLte DateEndComparisonOperators = "LESS_THAN_OR_EQUAL_lte"
+)
+
+// ApiDateStartSearchInput é a data inicial de um intervalo de busca.
+type ApiDateStartSearchInput struct {
+	Date     time.Time                    `json:"date"`
+	Operator DateStartComparisonOperators `json:"operator"`
+}
+
+// ApiDateEndSearchInput é a data final de um intervalo de busca.
+type ApiDateEndSearchInput struct {
+	Date     time.Time                  `json:"date"`
+	Operator DateEndComparisonOperators `json:"operator"`
+}
+
+// ApiDateOptionsInput é um intervalo de datas com início obrigatório e fim opcional.
+type ApiDateOptionsInput struct {
+	AStart *ApiDateStartSearchInput `json:"A_start"`
+	BEnd   *ApiDateEndSearchInput   `json:"B_end"`
+}
+
+// ApiDateSearchInput filtra documentos criados ou atualizados entre datas especificadas.
+type ApiDateSearchInput struct {
+	Created *ApiDateOptionsInput `json:"created"`
+	Updated *ApiDateOptionsInput `json:"updated"`
+}
+
+// ApiSearchRegexInput é uma busca por expressão regular com as suas opções (i, m, s).
+type ApiSearchRegexInput struct {
+	Pattern string `json:"pattern"`
+	Options string `json:"options"`
+}
+
+// ApiIdentifiersSearchInput filtra documentos por uma lista de identificadores.
+type ApiIdentifiersSearchInput struct {
+	Ids []string `json:"ids"`
+}
+
+// ApiSortInput é a ordenação por um campo.
+type ApiSortInput struct {
+	Field     string                         `json:"field"`
+	Direction status.StatusDirectionSortEnum `json:"direction"`
+}
+
+// ApiVersionInput é a versão esperada do documento, utilizada no controle de concorrência otimista.
+type ApiVersionInput struct {
+	Expected int `json:"expected"`
+}
+
+// ApiExistResult informa se um valor já existe em um campo.
+type ApiExistResult struct {
+	Field  *string `json:"field"`
+	Exists *bool   `json:"exists"`
+	Value  *string `json:"value"`
+}
+
+// ApiExistResponse é o response da verificação de existência de um valor.
+type ApiExistResponse struct {
+	Success     bool                    `json:"success"`
+	Result      *ApiExistResult         `json:"result"`
+	Error       *api_error.ApiErrorType `json:"error"`
+	ElapsedTime string                  `json:"elapsedTime"`
+}
+
+// ApiHistory é uma interação realizada no documento.
+type ApiHistory struct {
+	Action    *string    `json:"action" bson:"action,omitempty"`
+	Checksum  *string    `json:"checksum" bson:"checksum,omitempty"`
+	CreatedAt *time.Time `json:"createdAt" bson:"createdAt,omitempty"`
+	Log       *string    `json:"log" bson:"log,omitempty"`
+	Changes   []string   `json:"changes" bson:"changes,omitempty"`
+}
+
+// ApiInfo são as informações de sistema sobre o documento, armazenadas no campo _info (MongoDB)
+// ou nas colunas created_at, changed_at, owner, version, checksum e history (MySQL).
+type ApiInfo struct {
+	ChangedAt *time.Time    `json:"changedAt" bson:"changedAt,omitempty"`
+	Checksum  *string       `json:"checksum" bson:"checksum,omitempty"`
+	CreatedAt *time.Time    `json:"createdAt" bson:"createdAt,omitempty"`
+	History   []*ApiHistory `json:"history" bson:"history,omitempty"`
+	Owner     *string       `json:"owner" bson:"owner,omitempty"`
+	Version   *int          `json:"version" bson:"version,omitempty"`
+}
+
+// ApiPatchOperation é uma operação JSON Patch (RFC 6902): add, remove ou replace.
+type ApiPatchOperation struct {
+	Op    string      `json:"op" bson:"op"`
+	Path  string      `json:"path" bson:"path"`
+	Value interface{} `json:"value,omitempty" bson:"value,omitempty"`
+}
+
+// ApiChangeDiff são os campos alterados de um documento: o JSON Patch e os valores anteriores e
+// posteriores, indexados pelo caminho do campo com pontos (ex.: "address.city").
+type ApiChangeDiff struct {
+	Patch  []*ApiPatchOperation   `json:"patch" bson:"patch"`
+	Before map[string]interface{} `json:"before" bson:"before"`
+	After  map[string]interface{} `json:"after" bson:"after"`
+}
+
+// ApiChangeResult é o resultado de uma alteração de estado de um documento.
+type ApiChangeResult struct {
+	ID     *string        `json:"_id"`
+	Info   *ApiInfo       `json:"_info"`
+	Values interface{}    `json:"values"`
+	Diff   *ApiChangeDiff `json:"diff"`
+}
+
+// ApiChangeResponse é o response de uma alteração de estado de um documento.
+type ApiChangeResponse struct {
+	Success     bool             `json:"success"`
+	Result      *ApiChangeResult `json:"result"`
+	ElapsedTime string           `json:"elapsedTime"`
+}
+
+// TextValue é a representação Go do tipo GraphQL TextValue: um valor numérico e o seu texto formatado.
+type TextValue struct {
+	Text  string  `json:"text" bson:"text"`
+	Value float64 `json:"value" bson:"value"`
+}
+
+// Distance é a representação Go do tipo GraphQL Distance: a distância em metros e a duração estimada em segundos.
+type Distance struct {
+	Distance *TextValue `json:"distance" bson:"distance,omitempty"`
+	Duration *TextValue `json:"duration" bson:"duration,omitempty"`
+}
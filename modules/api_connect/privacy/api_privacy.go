@@ -0,0 +1,248 @@
+// Package privacy aplica as regras de visibilidade de StatusPrivacyEnum aos documentos, de acordo com o usuário da sessão:
+// adiciona a condição de visibilidade às consultas do MongoDB e do MySQL e verifica a leitura de um documento.
+//
+// A matriz de visibilidade, pelo valor do campo privacy do documento, é:
+//
+//	PUBLIC              qualquer pessoa, inclusive usuários não autenticados
+//	PRIVATE             usuários autenticados
+//	PROTECTED           somente o dono do documento
+//	DIRECT_CONNECTIONS  o dono e os usuários conectados diretamente a ele (Policy.Connections)
+//	CUSTOM_LISTS        o dono e os usuários inscritos nas suas listas personalizadas (Policy.Lists)
+//
+// Documentos sem privacy ou UNDEFINED seguem Policy.Default. O dono é o ApiInfo.owner do documento,
+// comparado com o identificador do usuário (User.ID), nunca com o nome.
+// Administradores (User.IsAdmin) visualizam todos os documentos e o acesso é registrado no log da aplicação.
+package privacy
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/audit"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"time"
+)
+
+const module = "api_connect/privacy"
+
+// Campos utilizados nas condições de visibilidade. A lista de campos permitidos do módulo precisa conter
+// os dois campos, por exemplo com MongoFields ou MysqlFields.
+const (
+	Field      = "privacy"
+	OwnerField = "owner"
+)
+
+// ActionBypass é a ação registrada no log da aplicação quando um administrador ignora as regras de visibilidade.
+const ActionBypass = "privacyBypass"
+
+// MongoFields e MysqlFields relacionam os campos das condições aos campos/colunas do banco.
+var (
+	MongoFields = filter.Fields{Field: "privacy", OwnerField: "_info.owner"}
+	MysqlFields = filter.Fields{Field: "privacy", OwnerField: "owner"}
+)
+
+// Resolver retorna os identificadores dos donos de documentos cujo conteúdo o usuário (User.ID) pode visualizar
+// por uma relação, por exemplo os usuários conectados diretamente a ele ou que o inscreveram em uma lista personalizada.
+type Resolver interface {
+	Owners(ctx context.Context, user string) ([]string, error)
+}
+
+// ResolverFunc permite utilizar uma função como Resolver.
+type ResolverFunc func(ctx context.Context, user string) ([]string, error)
+
+// Owners executa a função.
+func (f ResolverFunc) Owners(ctx context.Context, user string) ([]string, error) {
+	return f(ctx, user)
+}
+
+// Policy aplica as regras de visibilidade aos documentos de um módulo.
+type Policy struct {
+	// Module é o projeto/pacote do módulo, registrado no log da aplicação (ex.: "app/account").
+	Module string
+
+	// Default é a regra dos documentos sem privacy ou UNDEFINED. Se vazia, utiliza PROTECTED.
+	Default status.StatusPrivacyEnum
+
+	// Connections retorna os usuários conectados diretamente ao usuário da sessão.
+	// Se nil, DIRECT_CONNECTIONS é visível somente ao dono.
+	Connections Resolver
+
+	// Lists retorna os usuários que inscreveram o usuário da sessão em suas listas personalizadas.
+	// Se nil, CUSTOM_LISTS é visível somente ao dono.
+	Lists Resolver
+}
+
+// NewPolicy cria uma Policy para o módulo com os resolvers de conexões e de listas personalizadas, que podem ser nil.
+func NewPolicy(moduleName string, connections Resolver, lists Resolver) *Policy {
+	return &Policy{Module: moduleName, Connections: connections, Lists: lists}
+}
+
+// defaultPrivacy retorna a regra dos documentos sem privacy.
+func (p *Policy) defaultPrivacy() status.StatusPrivacyEnum {
+	if p.Default == "" || p.Default == status.StatusPrivacyEnumUndefined {
+		return status.StatusPrivacyEnumProtected
+	}
+	return p.Default
+}
+
+// Expression retorna a condição de visibilidade dos documentos para o usuário da sessão.
+// Para administradores retorna uma expressão vazia e registra o acesso no log da aplicação.
+func (p *Policy) Expression(ctx context.Context) (filter.Expression, error) {
+	user, _ := middleware.ForContext(ctx)
+	if user.IsAdmin {
+		p.bypass(ctx, "list", "")
+		return filter.Expression{}, nil
+	}
+
+	conditions := []filter.Expression{p.level(status.StatusPrivacyEnumPublic)}
+	if user.Anonymous {
+		return filter.Or(conditions...), nil
+	}
+
+	conditions = append(conditions,
+		p.level(status.StatusPrivacyEnumPrivate),
+		filter.Condition(OwnerField, params.ComparisonQueryOperatorsEqualEq, user.ID),
+	)
+
+	relations := []struct {
+		privacy  status.StatusPrivacyEnum
+		resolver Resolver
+	}{
+		{status.StatusPrivacyEnumDirectConnections, p.Connections},
+		{status.StatusPrivacyEnumCustomLists, p.Lists},
+	}
+	for _, relation := range relations {
+		if relation.resolver == nil {
+			continue
+		}
+		owners, err := relation.resolver.Owners(ctx, user.ID)
+		if err != nil {
+			return filter.Expression{}, err
+		}
+		if len(owners) == 0 {
+			continue
+		}
+		conditions = append(conditions, filter.And(
+			p.level(relation.privacy),
+			filter.Condition(OwnerField, params.ComparisonQueryOperatorsInArrayIn, owners),
+		))
+	}
+	return filter.Or(conditions...), nil
+}
+
+// level retorna a condição dos documentos com a regra informada, incluindo os documentos
+// sem privacy ou UNDEFINED quando a regra é a padrão.
+func (p *Policy) level(privacy status.StatusPrivacyEnum) filter.Expression {
+	values := []string{string(privacy)}
+	if privacy != p.defaultPrivacy() {
+		return filter.Condition(Field, params.ComparisonQueryOperatorsInArrayIn, values)
+	}
+	values = append(values, string(status.StatusPrivacyEnumUndefined))
+	return filter.Or(
+		filter.Condition(Field, params.ComparisonQueryOperatorsInArrayIn, values),
+		filter.Condition(Field, params.ComparisonQueryOperatorsEqualEq, nil),
+	)
+}
+
+// And adiciona a condição de visibilidade à expressão de filtro da consulta de lista,
+// por exemplo policy.And(ctx, filter.FromInput(input)).
+func (p *Policy) And(ctx context.Context, expression filter.Expression) (filter.Expression, error) {
+	condition, err := p.Expression(ctx)
+	if err != nil {
+		return filter.Expression{}, err
+	}
+	if condition.IsEmpty() {
+		return expression, nil
+	}
+	if expression.IsEmpty() {
+		return condition, nil
+	}
+	return filter.And(expression, condition), nil
+}
+
+// CanView verifica se o usuário da sessão pode visualizar um documento com a regra e o dono informados.
+// Administradores sempre podem visualizar.
+func (p *Policy) CanView(ctx context.Context, privacy status.StatusPrivacyEnum, owner string) (bool, error) {
+	user, _ := middleware.ForContext(ctx)
+	if user.IsAdmin {
+		return true, nil
+	}
+	return p.allowed(ctx, user, privacy, owner)
+}
+
+// Check verifica a leitura de um documento pelo usuário da sessão, retornando um erro CodePermissionDenied
+// quando o documento não é visível. A leitura de um administrador que não seria permitida é registrada no log da aplicação.
+func (p *Policy) Check(ctx context.Context, id string, privacy status.StatusPrivacyEnum, owner string) error {
+	user, _ := middleware.ForContext(ctx)
+	visible, err := p.allowed(ctx, user, privacy, owner)
+	if err != nil {
+		return err
+	}
+	if visible {
+		return nil
+	}
+	if user.IsAdmin {
+		p.bypass(ctx, "read", id)
+		return nil
+	}
+	return api_error.New(api_error.CodePermissionDenied, module, "read", "documento não visível para o usuário").
+		With("id", id)
+}
+
+// allowed aplica a matriz de visibilidade para o usuário, sem considerar o acesso de administrador.
+func (p *Policy) allowed(ctx context.Context, user middleware.User, privacy status.StatusPrivacyEnum, owner string) (bool, error) {
+	if privacy == "" || privacy == status.StatusPrivacyEnumUndefined {
+		privacy = p.defaultPrivacy()
+	}
+	if privacy == status.StatusPrivacyEnumPublic {
+		return true, nil
+	}
+	if user.Anonymous {
+		return false, nil
+	}
+	if owner == user.ID {
+		return true, nil
+	}
+
+	var resolver Resolver
+	switch privacy {
+	case status.StatusPrivacyEnumPrivate:
+		return true, nil
+	case status.StatusPrivacyEnumDirectConnections:
+		resolver = p.Connections
+	case status.StatusPrivacyEnumCustomLists:
+		resolver = p.Lists
+	}
+	if resolver == nil || owner == "" {
+		return false, nil
+	}
+
+	owners, err := resolver.Owners(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range owners {
+		if item == owner {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// bypass registra no log da aplicação o acesso de um administrador que ignora as regras de visibilidade.
+func (p *Policy) bypass(ctx context.Context, operation string, id string) {
+	entry := &audit.Entry{
+		Action:      ActionBypass,
+		Module:      p.Module,
+		Arguments:   map[string]interface{}{"operation": operation},
+		Success:     true,
+		ElapsedTime: time.Duration(0).String(),
+		AffectedIds: []string{},
+	}
+	if id != "" {
+		entry.AffectedIds = append(entry.AffectedIds, id)
+	}
+	audit.Record(ctx, entry)
+}
@@ -0,0 +1,37 @@
+package privacy
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// mongoCompiler compila somente as condições de visibilidade no MongoDB.
+var mongoCompiler = filter.NewCompiler(MongoFields)
+
+// Mongo retorna o filtro BSON de visibilidade dos documentos para o usuário da sessão.
+func (p *Policy) Mongo(ctx context.Context) (bson.D, error) {
+	expression, err := p.Expression(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mongoCompiler.Mongo(expression)
+}
+
+// CheckMongo verifica a leitura de um documento do MongoDB pelos campos privacy e _info.owner.
+func (p *Policy) CheckMongo(ctx context.Context, id string, document bson.M) error {
+	privacy, _ := document[Field].(string)
+	var owner string
+	switch item := document["_info"].(type) {
+	case bson.M:
+		owner, _ = item["owner"].(string)
+	case bson.D:
+		for _, element := range item {
+			if element.Key == "owner" {
+				owner, _ = element.Value.(string)
+			}
+		}
+	}
+	return p.Check(ctx, id, status.StatusPrivacyEnum(privacy), owner)
+}
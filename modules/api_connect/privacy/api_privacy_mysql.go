@@ -0,0 +1,35 @@
+package privacy
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+)
+
+// mysqlCompiler compila somente as condições de visibilidade no MySQL.
+var mysqlCompiler = filter.NewCompiler(MysqlFields)
+
+// Mysql retorna a condição WHERE parametrizada de visibilidade das linhas para o usuário da sessão.
+func (p *Policy) Mysql(ctx context.Context) (string, []interface{}, error) {
+	expression, err := p.Expression(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	return mysqlCompiler.Mysql(expression)
+}
+
+// CheckMysql verifica a leitura de uma linha do MySQL, lida com mysql.ScanMaps, pelas colunas privacy e owner.
+func (p *Policy) CheckMysql(ctx context.Context, id string, row map[string]interface{}) error {
+	return p.Check(ctx, id, status.StatusPrivacyEnum(text(row[Field])), text(row[OwnerField]))
+}
+
+// text converte o valor da coluna para texto.
+func text(value interface{}) string {
+	switch item := value.(type) {
+	case string:
+		return item
+	case []byte:
+		return string(item)
+	}
+	return ""
+}
@@ -0,0 +1,319 @@
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"go.mongodb.org/mongo-driver/bson"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	ownerID      = "user-owner"
+	strangerID   = "user-stranger"
+	connectionID = "user-connection"
+	memberID     = "user-member"
+	adminID      = "user-admin"
+)
+
+// authenticator identifica todas as requisições com o usuário informado.
+type authenticator struct {
+	user middleware.User
+}
+
+func (a authenticator) Authenticate(http.ResponseWriter, *http.Request) (middleware.User, bool, error) {
+	return a.user, true, nil
+}
+
+// contextFor retorna o contexto de uma requisição autenticada pelo middleware com o usuário informado.
+func contextFor(t *testing.T, user *middleware.User) context.Context {
+	t.Helper()
+	if user == nil {
+		return context.Background()
+	}
+	var ctx context.Context
+	handler := middleware.Authenticate(authenticator{user: *user})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	return ctx
+}
+
+// relation retorna um Resolver em que somente o usuário informado se relaciona com o dono.
+func relation(user string) Resolver {
+	return ResolverFunc(func(ctx context.Context, current string) ([]string, error) {
+		if current == user {
+			return []string{ownerID}, nil
+		}
+		return nil, nil
+	})
+}
+
+// matchMongo avalia o filtro compilado para o MongoDB no documento, com os operadores gerados pela Policy.
+func matchMongo(t *testing.T, where bson.D, document map[string]interface{}) bool {
+	t.Helper()
+	for _, element := range where {
+		switch element.Key {
+		case "$and", "$or":
+			list, ok := element.Value.(bson.A)
+			if !ok {
+				t.Fatalf("%s: lista inválida %#v", element.Key, element.Value)
+			}
+			any := false
+			all := true
+			for _, item := range list {
+				matched := matchMongo(t, item.(bson.D), document)
+				any = any || matched
+				all = all && matched
+			}
+			if (element.Key == "$and" && !all) || (element.Key == "$or" && !any) {
+				return false
+			}
+		default:
+			condition := element.Value.(bson.D)[0]
+			value, exists := document[element.Key]
+			switch condition.Key {
+			case "$eq":
+				if condition.Value == nil {
+					if exists && value != nil {
+						return false
+					}
+				} else if !exists || value != condition.Value {
+					return false
+				}
+			case "$in":
+				found := false
+				for _, item := range condition.Value.([]interface{}) {
+					if exists && fmt.Sprint(item) == fmt.Sprint(value) {
+						found = true
+					}
+				}
+				if !found {
+					return false
+				}
+			default:
+				t.Fatalf("operador não suportado: %s", condition.Key)
+			}
+		}
+	}
+	return true
+}
+
+// sqlParser avalia a condição compilada para o MySQL em uma linha, com a gramática gerada pela Policy.
+type sqlParser struct {
+	t      *testing.T
+	tokens []string
+	args   []interface{}
+	row    map[string]interface{}
+}
+
+func matchMysql(t *testing.T, where string, args []interface{}, row map[string]interface{}) bool {
+	t.Helper()
+	replacer := strings.NewReplacer("(", " ( ", ")", " ) ", ",", " , ")
+	parser := &sqlParser{t: t, tokens: strings.Fields(replacer.Replace(where)), args: args, row: row}
+	result := parser.or()
+	if len(parser.tokens) > 0 || len(parser.args) > 0 {
+		t.Fatalf("condição não consumida: %q %v", parser.tokens, parser.args)
+	}
+	return result
+}
+
+func (p *sqlParser) next() string {
+	if len(p.tokens) == 0 {
+		p.t.Fatal("fim inesperado da condição")
+	}
+	token := p.tokens[0]
+	p.tokens = p.tokens[1:]
+	return token
+}
+
+func (p *sqlParser) peek(token string) bool {
+	return len(p.tokens) > 0 && p.tokens[0] == token
+}
+
+func (p *sqlParser) arg() interface{} {
+	if p.next() != "?" {
+		p.t.Fatal("parâmetro esperado")
+	}
+	value := p.args[0]
+	p.args = p.args[1:]
+	return value
+}
+
+func (p *sqlParser) or() bool {
+	result := p.and()
+	for p.peek("OR") {
+		p.next()
+		right := p.and()
+		result = result || right
+	}
+	return result
+}
+
+func (p *sqlParser) and() bool {
+	result := p.primary()
+	for p.peek("AND") {
+		p.next()
+		right := p.primary()
+		result = result && right
+	}
+	return result
+}
+
+func (p *sqlParser) primary() bool {
+	token := p.next()
+	if token == "(" {
+		result := p.or()
+		if p.next() != ")" {
+			p.t.Fatal("parêntese não fechado")
+		}
+		return result
+	}
+	if token == "1" {
+		p.next()
+		return p.next() == "1"
+	}
+
+	value, exists := p.row[strings.Trim(token, "`")]
+	isNull := !exists || value == nil
+	column := text(value)
+	switch operator := p.next(); operator {
+	case "=":
+		arg := p.arg()
+		return !isNull && fmt.Sprint(arg) == column
+	case "IS":
+		if p.peek("NOT") {
+			p.next()
+			p.next()
+			return !isNull
+		}
+		p.next()
+		return isNull
+	case "IN":
+		p.next()
+		found := false
+		for {
+			if arg := p.arg(); !isNull && fmt.Sprint(arg) == column {
+				found = true
+			}
+			if p.next() == ")" {
+				return found
+			}
+		}
+	default:
+		p.t.Fatalf("operador não suportado: %s", operator)
+	}
+	return false
+}
+
+func TestPolicy(t *testing.T) {
+	users := map[string]*middleware.User{
+		"anonymous":  nil,
+		"owner":      {ID: ownerID, Name: "Owner"},
+		"stranger":   {ID: strangerID, Name: "Owner"},
+		"connection": {ID: connectionID},
+		"member":     {ID: memberID},
+		"admin":      {ID: adminID, IsAdmin: true},
+	}
+
+	tests := []struct {
+		privacy status.StatusPrivacyEnum
+		visible []string
+	}{
+		{status.StatusPrivacyEnumPublic, []string{"anonymous", "owner", "stranger", "connection", "member", "admin"}},
+		{status.StatusPrivacyEnumPrivate, []string{"owner", "stranger", "connection", "member", "admin"}},
+		{status.StatusPrivacyEnumProtected, []string{"owner", "admin"}},
+		{status.StatusPrivacyEnumDirectConnections, []string{"owner", "connection", "admin"}},
+		{status.StatusPrivacyEnumCustomLists, []string{"owner", "member", "admin"}},
+		{status.StatusPrivacyEnumUndefined, []string{"owner", "admin"}},
+		{"", []string{"owner", "admin"}},
+	}
+
+	policy := NewPolicy("app/account", relation(connectionID), relation(memberID))
+	for _, test := range tests {
+		visible := map[string]bool{}
+		for _, name := range test.visible {
+			visible[name] = true
+		}
+
+		document := map[string]interface{}{"_info.owner": ownerID}
+		row := map[string]interface{}{"owner": []byte(ownerID), "privacy": nil}
+		if test.privacy != "" {
+			document["privacy"] = string(test.privacy)
+			row["privacy"] = []byte(test.privacy)
+		}
+
+		for name, user := range users {
+			t.Run(fmt.Sprintf("%s/%s", test.privacy, name), func(t *testing.T) {
+				ctx := contextFor(t, user)
+				want := visible[name]
+
+				err := policy.Check(ctx, "1", test.privacy, ownerID)
+				if got := err == nil; got != want {
+					t.Errorf("Check = %v, want visible %v", err, want)
+				}
+
+				mongoWhere, err := policy.Mongo(ctx)
+				if err != nil {
+					t.Fatalf("Mongo: %v", err)
+				}
+				if got := matchMongo(t, mongoWhere, document); got != want {
+					t.Errorf("Mongo %v = %v, want %v", mongoWhere, got, want)
+				}
+
+				mysqlWhere, args, err := policy.Mysql(ctx)
+				if err != nil {
+					t.Fatalf("Mysql: %v", err)
+				}
+				if got := matchMysql(t, mysqlWhere, args, row); got != want {
+					t.Errorf("Mysql %s %v = %v, want %v", mysqlWhere, args, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestPolicyDefault(t *testing.T) {
+	policy := &Policy{Module: "app/account", Default: status.StatusPrivacyEnumPrivate}
+	ctx := contextFor(t, &middleware.User{ID: strangerID})
+
+	if err := policy.Check(ctx, "1", "", ownerID); err != nil {
+		t.Errorf("Check = %v, want visible", err)
+	}
+	where, args, err := policy.Mysql(ctx)
+	if err != nil {
+		t.Fatalf("Mysql: %v", err)
+	}
+	if !matchMysql(t, where, args, map[string]interface{}{"owner": ownerID}) {
+		t.Errorf("Mysql %s %v: documento sem privacy não visível", where, args)
+	}
+	if err = policy.Check(contextFor(t, nil), "1", "", ownerID); err == nil {
+		t.Error("Check anônimo = nil, want CodePermissionDenied")
+	}
+}
+
+func TestPolicyCheckRow(t *testing.T) {
+	policy := NewPolicy("app/account", nil, nil)
+	ctx := contextFor(t, &middleware.User{ID: ownerID})
+	stranger := contextFor(t, &middleware.User{ID: strangerID})
+
+	document := bson.M{"privacy": "PROTECTED", "_info": bson.D{{Key: "owner", Value: ownerID}}}
+	if err := policy.CheckMongo(ctx, "1", document); err != nil {
+		t.Errorf("CheckMongo owner = %v", err)
+	}
+	if err := policy.CheckMongo(stranger, "1", document); err == nil {
+		t.Error("CheckMongo stranger = nil, want CodePermissionDenied")
+	}
+
+	row := map[string]interface{}{"privacy": []byte("PROTECTED"), "owner": []byte(ownerID)}
+	if err := policy.CheckMysql(ctx, "1", row); err != nil {
+		t.Errorf("CheckMysql owner = %v", err)
+	}
+	if err := policy.CheckMysql(stranger, "1", row); err == nil {
+		t.Error("CheckMysql stranger = nil, want CodePermissionDenied")
+	}
+}
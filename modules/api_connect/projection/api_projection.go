@@ -0,0 +1,209 @@
+// Package projection monta a projeção do MongoDB ou a lista de colunas do MySQL a partir de um input
+// de inclusão/exclusão (StatusIncludeNotIncludeEnum) ou dos campos selecionados pelo cliente na consulta GraphQL.
+//
+// Com o gqlgen, os campos selecionados podem ser obtidos no resolver, por exemplo:
+//
+//	var selection []string
+//	for _, field := range graphql.CollectFieldsCtx(ctx, nil) { ... }
+package projection
+
+import (
+	"encoding/json"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"sort"
+	"strings"
+)
+
+// Campos obrigatórios usuais dos módulos, necessários ao envelope de resposta e às verificações de acesso,
+// por exemplo NewBuilder(fields, projection.DefaultRequired...).
+var (
+	DefaultRequired      = []string{"_id", "_info.owner", "status", "privacy"}
+	DefaultMysqlRequired = []string{"id", "owner", "status", "privacy"}
+)
+
+// envelopeFields são os campos do envelope de resposta, ignorados na seleção.
+var envelopeFields = map[string]bool{"success": true, "elapsedTime": true, "error": true, "errors": true, "pageInfo": true}
+
+// Fields é a lista de campos projetáveis: o nome exposto ao cliente e o campo/coluna correspondente no banco.
+type Fields map[string]string
+
+// Projection é o resultado de uma projeção: campos incluídos ou campos excluídos.
+type Projection struct {
+	include  []string
+	exclude  []string
+	fields   Fields
+	required []string
+}
+
+// IsAll indica que todos os campos devem ser retornados.
+func (p Projection) IsAll() bool {
+	return len(p.include) == 0 && len(p.exclude) == 0
+}
+
+// Builder monta projeções validando os campos pela lista de campos projetáveis.
+type Builder struct {
+	fields   Fields
+	required []string
+}
+
+// NewBuilder cria um Builder com os campos obrigatórios do módulo, sempre mantidos na projeção.
+// Os campos obrigatórios são nomes de campos/colunas do banco e somente os contidos na lista de campos
+// projetáveis são utilizados, por exemplo "_info.owner" quando há o campo "_info".
+func NewBuilder(fields Fields, required ...string) *Builder {
+	var projectable []string
+	for _, field := range required {
+		for _, value := range fields {
+			if covers(value, field) {
+				projectable = append(projectable, field)
+				break
+			}
+		}
+	}
+	return &Builder{fields: fields, required: projectable}
+}
+
+// FromInput monta a projeção a partir de um input de campos com StatusIncludeNotIncludeEnum.
+// Se houver algum INCLUDE, somente os campos incluídos são retornados; caso contrário, os campos NO_INCLUDE são excluídos.
+// Campos fora da lista de campos projetáveis são ignorados.
+func (b *Builder) FromInput(input map[string]status.StatusIncludeNotIncludeEnum) Projection {
+	var include, exclude []string
+	for name, value := range input {
+		field, ok := b.resolve(name)
+		if !ok {
+			continue
+		}
+		switch value {
+		case status.StatusIncludeNotIncludeEnumInclude:
+			include = append(include, field)
+		case status.StatusIncludeNotIncludeEnumNoInclude:
+			exclude = append(exclude, field)
+		}
+	}
+
+	if len(include) > 0 {
+		return b.including(include)
+	}
+	return b.excluding(exclude)
+}
+
+// FromStruct converte um input gerado pelo gqlgen (struct com campos *StatusIncludeNotIncludeEnum) e monta a projeção.
+func (b *Builder) FromStruct(input interface{}) (Projection, error) {
+	if input == nil {
+		return Projection{fields: b.fields}, nil
+	}
+	content, err := json.Marshal(input)
+	if err != nil {
+		return Projection{}, err
+	}
+	values := map[string]status.StatusIncludeNotIncludeEnum{}
+	if err = json.Unmarshal(content, &values); err != nil {
+		return Projection{}, err
+	}
+	return b.FromInput(values), nil
+}
+
+// FromSelection monta a projeção a partir dos caminhos dos campos selecionados pelo cliente,
+// por exemplo "result.name" ou "result._info.owner". O prefixo "result." e os campos do envelope são ignorados.
+func (b *Builder) FromSelection(selection []string) Projection {
+	var include []string
+	for _, path := range selection {
+		path = strings.TrimPrefix(path, "result.")
+		if path == "result" || envelopeFields[strings.Split(path, ".")[0]] {
+			continue
+		}
+		if field, ok := b.resolve(path); ok {
+			include = append(include, field)
+		}
+	}
+	if len(include) == 0 {
+		return Projection{fields: b.fields}
+	}
+	return b.including(include)
+}
+
+// resolve procura o caminho na lista de campos projetáveis, do caminho completo ao primeiro segmento.
+func (b *Builder) resolve(path string) (string, bool) {
+	for {
+		if field, ok := b.fields[path]; ok {
+			return field, true
+		}
+		index := strings.LastIndex(path, ".")
+		if index < 0 {
+			return "", false
+		}
+		path = path[:index]
+	}
+}
+
+// including monta uma projeção de inclusão com os campos obrigatórios.
+func (b *Builder) including(include []string) Projection {
+	return Projection{include: unique(append(include, b.required...)), fields: b.fields}
+}
+
+// excluding monta uma projeção de exclusão, preservando os campos obrigatórios. Quando um campo excluído contém
+// um campo obrigatório (ex.: "_info" e "_info.owner"), a projeção é convertida para a inclusão dos demais campos
+// projetáveis e dos campos obrigatórios.
+func (b *Builder) excluding(exclude []string) Projection {
+	var result []string
+	partial := false
+	for _, field := range exclude {
+		if contains(b.required, field) {
+			continue
+		}
+		for _, item := range b.required {
+			if covers(field, item) {
+				partial = true
+			}
+		}
+		result = append(result, field)
+	}
+
+	if partial {
+		var include []string
+		for _, field := range b.fields {
+			excluded := false
+			for _, item := range result {
+				excluded = excluded || covers(item, field)
+			}
+			if !excluded {
+				include = append(include, field)
+			}
+		}
+		return b.including(include)
+	}
+	return Projection{exclude: unique(result), fields: b.fields, required: b.required}
+}
+
+// covers indica se o campo é igual ao caminho ou o contém (ex.: "_info" contém "_info.owner").
+func covers(field, path string) bool {
+	return field == path || strings.HasPrefix(path, field+".")
+}
+
+// contains indica se o campo está na lista.
+func contains(fields []string, field string) bool {
+	for _, item := range fields {
+		if item == field {
+			return true
+		}
+	}
+	return false
+}
+
+// unique remove os campos repetidos e os campos contidos em outro campo incluído (ex.: "_info.owner" quando há "_info").
+func unique(fields []string) []string {
+	sort.Strings(fields)
+	var result []string
+	for _, field := range fields {
+		covered := false
+		for _, item := range result {
+			if covers(item, field) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			result = append(result, field)
+		}
+	}
+	return result
+}
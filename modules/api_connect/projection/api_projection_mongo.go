@@ -0,0 +1,41 @@
+package projection
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Mongo retorna a projeção do MongoDB: {campo: 1} para inclusão ou {campo: 0} para exclusão.
+// Retorna nil quando todos os campos devem ser retornados.
+func (p Projection) Mongo() bson.D {
+	if p.IsAll() {
+		return nil
+	}
+
+	projection := bson.D{}
+	for _, field := range p.include {
+		projection = append(projection, bson.E{Key: field, Value: 1})
+	}
+	for _, field := range p.exclude {
+		projection = append(projection, bson.E{Key: field, Value: 0})
+	}
+	return projection
+}
+
+// FindOptions retorna as opções de consulta com a projeção, para uso em Find.
+func (p Projection) FindOptions() *options.FindOptions {
+	opts := options.Find()
+	if projection := p.Mongo(); projection != nil {
+		opts.SetProjection(projection)
+	}
+	return opts
+}
+
+// FindOneOptions retorna as opções de consulta com a projeção, para uso em FindOne.
+func (p Projection) FindOneOptions() *options.FindOneOptions {
+	opts := options.FindOne()
+	if projection := p.Mongo(); projection != nil {
+		opts.SetProjection(projection)
+	}
+	return opts
+}
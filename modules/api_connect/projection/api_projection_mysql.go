@@ -0,0 +1,51 @@
+package projection
+
+import (
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"sort"
+	"strings"
+)
+
+// Mysql retorna a lista de colunas para o SELECT, com os nomes validados e entre crases.
+// Na exclusão, as colunas são todas as colunas projetáveis e obrigatórias exceto as excluídas. Retorna "*" quando
+// todos os campos devem ser retornados.
+func (p Projection) Mysql() (string, error) {
+	if p.IsAll() {
+		return "*", nil
+	}
+
+	columns := p.include
+	if len(columns) == 0 {
+		excluded := map[string]bool{}
+		for _, field := range p.exclude {
+			excluded[field] = true
+		}
+		seen := map[string]bool{}
+		for _, field := range append(p.fieldValues(), p.required...) {
+			if !excluded[field] && !seen[field] {
+				seen[field] = true
+				columns = append(columns, field)
+			}
+		}
+		sort.Strings(columns)
+	}
+
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		value, err := mysql.QuoteIdentifier(column)
+		if err != nil {
+			return "", err
+		}
+		quoted = append(quoted, value)
+	}
+	return strings.Join(quoted, ", "), nil
+}
+
+// fieldValues retorna as colunas da lista de campos projetáveis.
+func (p Projection) fieldValues() []string {
+	values := make([]string, 0, len(p.fields))
+	for _, field := range p.fields {
+		values = append(values, field)
+	}
+	return values
+}
@@ -0,0 +1,82 @@
+package projection
+
+import (
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"go.mongodb.org/mongo-driver/bson"
+	"reflect"
+	"testing"
+)
+
+var mongoFields = Fields{"name": "name", "email": "email", "status": "status", "_info": "_info"}
+
+func TestMongo(t *testing.T) {
+	builder := NewBuilder(mongoFields, DefaultRequired...)
+
+	tests := []struct {
+		name  string
+		input map[string]status.StatusIncludeNotIncludeEnum
+		want  bson.D
+	}{
+		{
+			name:  "all fields",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name:  "include keeps the projectable required fields",
+			input: map[string]status.StatusIncludeNotIncludeEnum{"name": status.StatusIncludeNotIncludeEnumInclude},
+			want:  bson.D{{Key: "_info.owner", Value: 1}, {Key: "name", Value: 1}, {Key: "status", Value: 1}},
+		},
+		{
+			name:  "exclude a required field",
+			input: map[string]status.StatusIncludeNotIncludeEnum{"status": status.StatusIncludeNotIncludeEnumNoInclude, "email": status.StatusIncludeNotIncludeEnumNoInclude},
+			want:  bson.D{{Key: "email", Value: 0}},
+		},
+		{
+			name:  "exclude the parent of a required field",
+			input: map[string]status.StatusIncludeNotIncludeEnum{"_info": status.StatusIncludeNotIncludeEnumNoInclude},
+			want:  bson.D{{Key: "_info.owner", Value: 1}, {Key: "email", Value: 1}, {Key: "name", Value: 1}, {Key: "status", Value: 1}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := builder.FromInput(test.input).Mongo(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Mongo = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMysql(t *testing.T) {
+	builder := NewBuilder(Fields{"name": "name", "email": "email", "owner": "owner"}, DefaultMysqlRequired...)
+
+	tests := []struct {
+		name  string
+		input map[string]status.StatusIncludeNotIncludeEnum
+		want  string
+	}{
+		{"all fields", nil, "*"},
+		{"include", map[string]status.StatusIncludeNotIncludeEnum{"name": status.StatusIncludeNotIncludeEnumInclude}, "`name`, `owner`"},
+		{"exclude", map[string]status.StatusIncludeNotIncludeEnum{"email": status.StatusIncludeNotIncludeEnumNoInclude, "owner": status.StatusIncludeNotIncludeEnumNoInclude}, "`name`, `owner`"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := builder.FromInput(test.input).Mysql()
+			if err != nil {
+				t.Fatalf("Mysql: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("Mysql = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFromSelection(t *testing.T) {
+	builder := NewBuilder(mongoFields)
+	got := builder.FromSelection([]string{"success", "result", "result.name", "result._info.owner", "pageInfo.next"}).Mongo()
+	want := bson.D{{Key: "_info", Value: 1}, {Key: "name", Value: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Mongo = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,507 @@
+// Package queue executa tarefas em segundo plano ordenadas por urgência (StatusUrgencyEnum) e prioridade
+// (StatusPriorityEnum). As tarefas são gravadas em um Store (coleção do MongoDB, tabela do MySQL ou memória,
+// utilizado em testes) e executadas pelos handlers registrados para o nome da tarefa.
+//
+// Uma tarefa em execução fica invisível para os outros workers até o fim do tempo de visibilidade. Se o worker
+// falhar ou não concluir a tarefa nesse período, a tarefa volta para a fila. As falhas são repetidas com espera
+// exponencial até Options.MaxAttempts; depois disso a tarefa é movida para a fila de mortas (DEAD), onde pode ser
+// consultada pela query apiJobs e recolocada na fila pela mutação apiJobRetry.
+//
+//	jobs := queue.NewQueue(queue.NewMongoStore(db.Collection("app", "jobs")), queue.Options{})
+//	jobs.Handle("mailSend", func(ctx context.Context, job *queue.Job) error { ... })
+//	jobs.Start(ctx, 4)
+//	queue.SetDefault(jobs)
+package queue
+
+import (
+	"context"
+	"fmt"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"sync"
+	"time"
+)
+
+const module = "api_connect/queue"
+
+// Valores padrão de Options.
+const (
+	DefaultVisibility   = 5 * time.Minute
+	DefaultMaxAttempts  = 5
+	DefaultBackoff      = 10 * time.Second
+	DefaultMaxBackoff   = time.Hour
+	DefaultPollInterval = time.Second
+)
+
+// JobStateEnum são os estados de uma tarefa.
+type JobStateEnum string
+
+const (
+	// JobStateEnumPending aguarda a execução, a partir de RunAt.
+	JobStateEnumPending JobStateEnum = "PENDING"
+
+	// JobStateEnumRunning está em execução por um worker até LockedUntil.
+	JobStateEnumRunning JobStateEnum = "RUNNING"
+
+	// JobStateEnumSucceeded foi concluída com sucesso.
+	JobStateEnumSucceeded JobStateEnum = "SUCCEEDED"
+
+	// JobStateEnumDead excedeu as tentativas e está na fila de mortas.
+	JobStateEnumDead JobStateEnum = "DEAD"
+)
+
+// Job é uma tarefa da fila, representação Go do tipo GraphQL ApiJobResult.
+type Job struct {
+	ID          string                    `json:"_id" bson:"_id"`
+	Name        string                    `json:"name" bson:"name"`
+	Payload     map[string]interface{}    `json:"payload" bson:"payload"`
+	Priority    status.StatusPriorityEnum `json:"priority" bson:"priority"`
+	Urgency     status.StatusUrgencyEnum  `json:"urgency" bson:"urgency"`
+	Weight      int                       `json:"weight" bson:"weight"`
+	State       JobStateEnum              `json:"state" bson:"state"`
+	Attempts    int                       `json:"attempts" bson:"attempts"`
+	MaxAttempts int                       `json:"maxAttempts" bson:"maxAttempts"`
+	Error       string                    `json:"error,omitempty" bson:"error,omitempty"`
+	Lock        string                    `json:"-" bson:"lock,omitempty"`
+	LockedUntil *time.Time                `json:"lockedUntil,omitempty" bson:"lockedUntil,omitempty"`
+	RunAt       time.Time                 `json:"runAt" bson:"runAt"`
+	CreatedAt   time.Time                 `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time                 `json:"updatedAt" bson:"updatedAt"`
+}
+
+// urgencyWeights e priorityWeights definem a ordem de execução: a urgência é comparada primeiro e a prioridade desempata.
+// UNDEFINED equivale a NORMAL.
+var (
+	urgencyWeights = map[status.StatusUrgencyEnum]int{
+		status.StatusUrgencyEnumNone:       0,
+		status.StatusUrgencyEnumUndefined:  1,
+		status.StatusUrgencyEnumNormal:     1,
+		status.StatusUrgencyEnumUrgent:     2,
+		status.StatusUrgencyEnumVeryUrgent: 3,
+	}
+	priorityWeights = map[status.StatusPriorityEnum]int{
+		status.StatusPriorityEnumNone:      0,
+		status.StatusPriorityEnumLow:       1,
+		status.StatusPriorityEnumUndefined: 2,
+		status.StatusPriorityEnumNormal:    2,
+		status.StatusPriorityEnumHigh:      3,
+		status.StatusPriorityEnumVeryHigh:  4,
+	}
+)
+
+// Weight retorna o peso de ordenação da tarefa. Tarefas de maior peso são executadas primeiro.
+func Weight(urgency status.StatusUrgencyEnum, priority status.StatusPriorityEnum) int {
+	return urgencyWeights[urgency]*10 + priorityWeights[priority]
+}
+
+// Handler executa uma tarefa. Um erro faz a tarefa ser repetida até Options.MaxAttempts.
+type Handler func(ctx context.Context, job *Job) error
+
+// ApiJobFilter é a representação Go do input GraphQL ApiJobFilter.
+type ApiJobFilter struct {
+	Name     *string                    `json:"name"`
+	State    *JobStateEnum              `json:"state"`
+	Priority *status.StatusPriorityEnum `json:"priority"`
+	Urgency  *status.StatusUrgencyEnum  `json:"urgency"`
+}
+
+// ApiJobsResult é a representação Go do tipo GraphQL ApiJobsResult.
+type ApiJobsResult struct {
+	Items    []*Job              `json:"items"`
+	PageInfo *params.ApiPageInfo `json:"pageInfo"`
+}
+
+// ApiJobsResponse é a representação Go do tipo GraphQL ApiJobsResponse.
+type ApiJobsResponse struct {
+	Success     bool           `json:"success"`
+	Result      *ApiJobsResult `json:"result"`
+	ElapsedTime string         `json:"elapsedTime"`
+}
+
+// ApiJobResponse é a representação Go do tipo GraphQL ApiJobResponse.
+type ApiJobResponse struct {
+	Success     bool   `json:"success"`
+	Result      *Job   `json:"result"`
+	ElapsedTime string `json:"elapsedTime"`
+}
+
+// Query é uma consulta às tarefas.
+type Query struct {
+	Filter     *ApiJobFilter
+	Pagination *params.ApiPaginationInput
+}
+
+// expression converte o filtro da consulta em uma expressão sobre os campos name, state, priority e urgency.
+func (q Query) expression() filter.Expression {
+	var expressions []filter.Expression
+	if q.Filter != nil {
+		if q.Filter.Name != nil {
+			expressions = append(expressions, filter.Condition("name", params.ComparisonQueryOperatorsEqualEq, *q.Filter.Name))
+		}
+		if q.Filter.State != nil {
+			expressions = append(expressions, filter.Condition("state", params.ComparisonQueryOperatorsEqualEq, string(*q.Filter.State)))
+		}
+		if q.Filter.Priority != nil {
+			expressions = append(expressions, filter.Condition("priority", params.ComparisonQueryOperatorsEqualEq, string(*q.Filter.Priority)))
+		}
+		if q.Filter.Urgency != nil {
+			expressions = append(expressions, filter.Condition("urgency", params.ComparisonQueryOperatorsEqualEq, string(*q.Filter.Urgency)))
+		}
+	}
+	return filter.And(expressions...)
+}
+
+// match verifica se a tarefa atende ao filtro da consulta.
+func (q Query) match(job *Job) bool {
+	if q.Filter == nil {
+		return true
+	}
+	return (q.Filter.Name == nil || *q.Filter.Name == job.Name) &&
+		(q.Filter.State == nil || *q.Filter.State == job.State) &&
+		(q.Filter.Priority == nil || *q.Filter.Priority == job.Priority) &&
+		(q.Filter.Urgency == nil || *q.Filter.Urgency == job.Urgency)
+}
+
+// Store grava e consulta as tarefas.
+type Store interface {
+	// Enqueue grava uma nova tarefa.
+	Enqueue(ctx context.Context, job *Job) error
+
+	// Claim reserva a próxima tarefa disponível em now, de maior peso e menor RunAt, incrementando Attempts
+	// e bloqueando-a com lock até lockedUntil. Tarefas RUNNING com o bloqueio expirado também são disponíveis.
+	// Retorna nil se não houver tarefa disponível.
+	Claim(ctx context.Context, now time.Time, lock string, lockedUntil time.Time) (*Job, error)
+
+	// Finish grava o estado, RunAt e Error da tarefa e remove o bloqueio, se a tarefa ainda estiver bloqueada por lock.
+	// Retorna false se o bloqueio expirou e a tarefa foi reservada por outro worker.
+	Finish(ctx context.Context, job *Job, lock string) (bool, error)
+
+	// Requeue recoloca na fila, a partir de now, a tarefa DEAD, reiniciando Attempts. Retorna false se a tarefa não está DEAD.
+	Requeue(ctx context.Context, id string, now time.Time) (bool, error)
+
+	// Get retorna a tarefa, ou um erro CodeNotFound.
+	Get(ctx context.Context, id string) (*Job, error)
+
+	// Find consulta as tarefas, das mais recentes às mais antigas.
+	Find(ctx context.Context, query Query) ([]*Job, *params.ApiPageInfo, error)
+}
+
+// Options são as configurações da fila. Valores menores ou iguais a zero utilizam os valores padrão.
+type Options struct {
+	// Visibility é o tempo em que uma tarefa em execução fica invisível para os outros workers.
+	Visibility time.Duration
+
+	// MaxAttempts é a quantidade de tentativas antes de mover a tarefa para a fila de mortas.
+	MaxAttempts int
+
+	// Backoff é a espera após a primeira falha, dobrada a cada nova falha.
+	Backoff time.Duration
+
+	// MaxBackoff é a espera máxima entre as tentativas.
+	MaxBackoff time.Duration
+
+	// PollInterval é o intervalo de consulta dos workers quando a fila está vazia.
+	PollInterval time.Duration
+}
+
+// Queue enfileira e executa as tarefas com os handlers registrados.
+type Queue struct {
+	store    Store
+	options  Options
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewQueue cria uma Queue com o Store informado (NewMongoStore, NewMysqlStore ou NewMemoryStore).
+func NewQueue(store Store, options Options) *Queue {
+	if options.Visibility <= 0 {
+		options.Visibility = DefaultVisibility
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultMaxAttempts
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = DefaultBackoff
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = DefaultMaxBackoff
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+	return &Queue{store: store, options: options, handlers: map[string]Handler{}}
+}
+
+// Handle registra o handler das tarefas com o nome informado.
+func (q *Queue) Handle(name string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[name] = handler
+}
+
+// Enqueue grava a tarefa na fila. Name é obrigatório; ID, State, Attempts e as datas são preenchidos pela fila.
+// Se RunAt não for informado, a tarefa fica disponível imediatamente. Se MaxAttempts não for informado,
+// utiliza Options.MaxAttempts.
+func (q *Queue) Enqueue(ctx context.Context, job *Job) (*Job, error) {
+	if job.Name == "" {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "name", "o nome da tarefa é obrigatório")
+	}
+	now := time.Now().UTC()
+	job.ID = primitive.NewObjectID().Hex()
+	if job.Priority == "" {
+		job.Priority = status.StatusPriorityEnumUndefined
+	}
+	if job.Urgency == "" {
+		job.Urgency = status.StatusUrgencyEnumUndefined
+	}
+	job.Weight = Weight(job.Urgency, job.Priority)
+	job.State = JobStateEnumPending
+	job.Attempts = 0
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = q.options.MaxAttempts
+	}
+	job.Error = ""
+	job.Lock = ""
+	job.LockedUntil = nil
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	job.RunAt = job.RunAt.UTC()
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	if err := q.store.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Work reserva e executa a próxima tarefa disponível. Retorna false se a fila estiver vazia.
+func (q *Queue) Work(ctx context.Context) (bool, error) {
+	now := time.Now().UTC()
+	lock := primitive.NewObjectID().Hex()
+	job, err := q.store.Claim(ctx, now, lock, now.Add(q.options.Visibility))
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Name]
+	q.mu.RUnlock()
+
+	switch {
+	case !ok:
+		err = api_error.New(api_error.CodeInternal, module, "handler", "tarefa sem handler registrado").
+			With("name", job.Name)
+		job.Attempts = job.MaxAttempts
+	case job.Attempts > job.MaxAttempts:
+		err = api_error.New(api_error.CodeInternal, module, "visibility", "tarefa excedeu as tentativas sem ser concluída").
+			With("name", job.Name)
+	default:
+		runCtx, cancel := context.WithDeadline(ctx, *job.LockedUntil)
+		err = run(runCtx, handler, job)
+		cancel()
+	}
+
+	job.UpdatedAt = time.Now().UTC()
+	if err == nil {
+		job.State = JobStateEnumSucceeded
+		job.Error = ""
+	} else if job.Attempts >= job.MaxAttempts {
+		job.State = JobStateEnumDead
+		job.Error = err.Error()
+	} else {
+		job.State = JobStateEnumPending
+		job.Error = err.Error()
+		job.RunAt = job.UpdatedAt.Add(q.backoff(job.Attempts))
+	}
+
+	finished, err := q.store.Finish(ctx, job, lock)
+	if err != nil {
+		return true, err
+	}
+	if !finished {
+		log.Printf("Tarefa %s (%s) reservada por outro worker após o tempo de visibilidade", job.ID, job.Name)
+	}
+	return true, nil
+}
+
+// run executa o handler, convertendo um panic em erro.
+func run(ctx context.Context, handler Handler, job *Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// backoff retorna a espera após a falha da tentativa informada: Backoff * 2^(attempts-1), limitada a MaxBackoff.
+func (q *Queue) backoff(attempts int) time.Duration {
+	wait := q.options.Backoff
+	for index := 1; index < attempts && wait < q.options.MaxBackoff; index++ {
+		wait *= 2
+	}
+	if wait > q.options.MaxBackoff {
+		wait = q.options.MaxBackoff
+	}
+	return wait
+}
+
+// Start executa a quantidade informada de workers até o contexto ser cancelado.
+// Cada worker consulta a fila a cada Options.PollInterval enquanto não houver tarefas disponíveis.
+func (q *Queue) Start(ctx context.Context, workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	for index := 0; index < workers; index++ {
+		go func() {
+			ticker := time.NewTicker(q.options.PollInterval)
+			defer ticker.Stop()
+			for {
+				worked, err := q.Work(ctx)
+				if err != nil {
+					log.Printf("Falha ao executar tarefa da fila: %v", err)
+				}
+				if worked && err == nil {
+					if ctx.Err() != nil {
+						return
+					}
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+}
+
+// Retry recoloca na fila a tarefa da fila de mortas. Somente administradores podem recolocar tarefas.
+func (q *Queue) Retry(ctx context.Context, id string) (*Job, error) {
+	if user, _ := middleware.ForContext(ctx); !user.IsAdmin {
+		return nil, api_error.New(api_error.CodePermissionDenied, module, "apiJobRetry", "somente administradores podem recolocar tarefas na fila")
+	}
+	requeued, err := q.store.Requeue(ctx, id, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	job, err := q.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !requeued {
+		return nil, api_error.New(api_error.CodeFailedPrecondition, module, "apiJobRetry", "somente tarefas DEAD podem ser recolocadas na fila").
+			With("id", id).
+			With("state", string(job.State))
+	}
+	return job, nil
+}
+
+// Get retorna a tarefa. Somente administradores podem consultar as tarefas.
+func (q *Queue) Get(ctx context.Context, id string) (*Job, error) {
+	if user, _ := middleware.ForContext(ctx); !user.IsAdmin {
+		return nil, api_error.New(api_error.CodePermissionDenied, module, "apiJob", "somente administradores podem consultar as tarefas")
+	}
+	return q.store.Get(ctx, id)
+}
+
+// Find consulta as tarefas. Somente administradores podem consultar as tarefas.
+func (q *Queue) Find(ctx context.Context, query Query) ([]*Job, *params.ApiPageInfo, error) {
+	if user, _ := middleware.ForContext(ctx); !user.IsAdmin {
+		return nil, nil, api_error.New(api_error.CodePermissionDenied, module, "apiJobs", "somente administradores podem consultar as tarefas")
+	}
+	return q.store.Find(ctx, query)
+}
+
+// ApiJobsQuery implementa a query apiJobs, retornando o envelope ApiJobsResponse.
+func (q *Queue) ApiJobsQuery(ctx context.Context, filter *ApiJobFilter, pagination *params.ApiPaginationInput) (*ApiJobsResponse, error) {
+	_timeStart := time.Now()
+
+	items, pageInfo, err := q.Find(ctx, Query{Filter: filter, Pagination: pagination})
+	if err != nil {
+		return nil, err
+	}
+
+	_response := ApiJobsResponse{
+		Result:      &ApiJobsResult{Items: items, PageInfo: pageInfo},
+		Success:     true,
+		ElapsedTime: time.Since(_timeStart).String(),
+	}
+	return &_response, nil
+}
+
+// ApiJobQuery implementa a query apiJob, retornando o envelope ApiJobResponse.
+func (q *Queue) ApiJobQuery(ctx context.Context, id string) (*ApiJobResponse, error) {
+	_timeStart := time.Now()
+
+	_result, err := q.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_response := ApiJobResponse{
+		Result:      _result,
+		Success:     true,
+		ElapsedTime: time.Since(_timeStart).String(),
+	}
+	return &_response, nil
+}
+
+// ApiJobRetryMutation implementa a mutação apiJobRetry, retornando o envelope ApiJobResponse.
+func (q *Queue) ApiJobRetryMutation(ctx context.Context, id string) (*ApiJobResponse, error) {
+	_timeStart := time.Now()
+
+	_result, err := q.Retry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_response := ApiJobResponse{
+		Result:      _result,
+		Success:     true,
+		ElapsedTime: time.Since(_timeStart).String(),
+	}
+	return &_response, nil
+}
+
+var (
+	defaultMu    sync.RWMutex
+	defaultQueue *Queue
+)
+
+// SetDefault define a Queue utilizada por Enqueue, normalmente na inicialização do servidor.
+func SetDefault(queue *Queue) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultQueue = queue
+}
+
+// Default retorna a Queue padrão, ou nil se não foi definida.
+func Default() *Queue {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultQueue
+}
+
+// Enqueue grava a tarefa na Queue padrão.
+func Enqueue(ctx context.Context, job *Job) (*Job, error) {
+	queue := Default()
+	if queue == nil {
+		return nil, api_error.New(api_error.CodeInternal, module, "default", "fila de tarefas não configurada")
+	}
+	return queue.Enqueue(ctx, job)
+}
+
+// notFound retorna o erro de tarefa não encontrada.
+func notFound(id string) error {
+	return api_error.New(api_error.CodeNotFound, module, "_id", "tarefa não encontrada").
+		With("id", id)
+}
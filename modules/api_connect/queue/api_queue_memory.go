@@ -0,0 +1,150 @@
+package queue
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/pagination"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore mantém as tarefas em memória, para testes e ambientes de desenvolvimento.
+// As tarefas são perdidas quando o processo termina.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewMemoryStore cria um Store em memória.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: map[string]*Job{}}
+}
+
+// Enqueue grava uma nova tarefa.
+func (s *MemoryStore) Enqueue(_ context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+// Claim reserva a próxima tarefa disponível.
+func (s *MemoryStore) Claim(_ context.Context, now time.Time, lock string, lockedUntil time.Time) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next *Job
+	for _, job := range s.jobs {
+		available := (job.State == JobStateEnumPending && !job.RunAt.After(now)) ||
+			(job.State == JobStateEnumRunning && job.LockedUntil != nil && !job.LockedUntil.After(now))
+		if !available {
+			continue
+		}
+		if next == nil || job.Weight > next.Weight ||
+			(job.Weight == next.Weight && (job.RunAt.Before(next.RunAt) || (job.RunAt.Equal(next.RunAt) && job.ID < next.ID))) {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	next.State = JobStateEnumRunning
+	next.Attempts++
+	next.Lock = lock
+	until := lockedUntil
+	next.LockedUntil = &until
+	next.UpdatedAt = now
+	return copyJob(next), nil
+}
+
+// Finish grava o resultado da execução da tarefa.
+func (s *MemoryStore) Finish(_ context.Context, job *Job, lock string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.jobs[job.ID]
+	if !ok || current.State != JobStateEnumRunning || current.Lock != lock {
+		return false, nil
+	}
+	current.State = job.State
+	current.Attempts = job.Attempts
+	current.RunAt = job.RunAt
+	current.Error = job.Error
+	current.UpdatedAt = job.UpdatedAt
+	current.Lock = ""
+	current.LockedUntil = nil
+	return true, nil
+}
+
+// Requeue recoloca na fila a tarefa DEAD.
+func (s *MemoryStore) Requeue(_ context.Context, id string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.jobs[id]
+	if !ok {
+		return false, notFound(id)
+	}
+	if current.State != JobStateEnumDead {
+		return false, nil
+	}
+	current.State = JobStateEnumPending
+	current.Attempts = 0
+	current.RunAt = now
+	current.UpdatedAt = now
+	return true, nil
+}
+
+// Get retorna a tarefa.
+func (s *MemoryStore) Get(_ context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.jobs[id]
+	if !ok {
+		return nil, notFound(id)
+	}
+	return copyJob(current), nil
+}
+
+// Find consulta as tarefas, das mais recentes às mais antigas.
+func (s *MemoryStore) Find(_ context.Context, query Query) ([]*Job, *params.ApiPageInfo, error) {
+	page, err := pagination.Normalize(query.Pagination, pagination.MaxSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	var found []*Job
+	for _, job := range s.jobs {
+		if query.match(job) {
+			found = append(found, copyJob(job))
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if !found[i].CreatedAt.Equal(found[j].CreatedAt) {
+			return found[i].CreatedAt.After(found[j].CreatedAt)
+		}
+		return found[i].ID > found[j].ID
+	})
+
+	result := []*Job{}
+	for position := page.Offset; position < len(found) && position < page.Offset+page.Size; position++ {
+		result = append(result, found[position])
+	}
+	return result, page.PageInfo(len(found)), nil
+}
+
+// copyJob retorna uma cópia da tarefa, para que as alterações fora do Store não alterem a tarefa armazenada.
+func copyJob(job *Job) *Job {
+	copied := *job
+	if job.LockedUntil != nil {
+		until := *job.LockedUntil
+		copied.LockedUntil = &until
+	}
+	return &copied
+}
@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/pagination"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// mongoFields são os campos filtráveis da coleção de tarefas.
+var mongoFields = filter.Fields{
+	"name":     "name",
+	"state":    "state",
+	"priority": "priority",
+	"urgency":  "urgency",
+}
+
+// MongoStore grava as tarefas em uma coleção do MongoDB.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoStore cria um Store para a coleção informada.
+func NewMongoStore(collection *mongo.Collection) *MongoStore {
+	return &MongoStore{collection: collection}
+}
+
+// EnsureIndexes cria os índices de reserva das tarefas e de consulta por estado e nome.
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "state", Value: 1}, {Key: "weight", Value: -1}, {Key: "runAt", Value: 1}}},
+		{Keys: bson.D{{Key: "state", Value: 1}, {Key: "lockedUntil", Value: 1}}},
+		{Keys: bson.D{{Key: "name", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "createdAt", Value: -1}}},
+	})
+	return err
+}
+
+// Enqueue grava uma nova tarefa.
+func (s *MongoStore) Enqueue(ctx context.Context, job *Job) error {
+	_, err := s.collection.InsertOne(ctx, job)
+	return err
+}
+
+// Claim reserva a próxima tarefa disponível com FindOneAndUpdate, de forma atômica entre os workers.
+func (s *MongoStore) Claim(ctx context.Context, now time.Time, lock string, lockedUntil time.Time) (*Job, error) {
+	where := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "state", Value: string(JobStateEnumPending)}, {Key: "runAt", Value: bson.D{{Key: "$lte", Value: now}}}},
+		bson.D{{Key: "state", Value: string(JobStateEnumRunning)}, {Key: "lockedUntil", Value: bson.D{{Key: "$lte", Value: now}}}},
+	}}}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "state", Value: string(JobStateEnumRunning)},
+			{Key: "lock", Value: lock},
+			{Key: "lockedUntil", Value: lockedUntil},
+			{Key: "updatedAt", Value: now},
+		}},
+		{Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}},
+	}
+	findOptions := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "weight", Value: -1}, {Key: "runAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job Job
+	err := s.collection.FindOneAndUpdate(ctx, where, update, findOptions).Decode(&job)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Finish grava o resultado da execução da tarefa, se ainda estiver bloqueada por lock.
+func (s *MongoStore) Finish(ctx context.Context, job *Job, lock string) (bool, error) {
+	set := bson.D{
+		{Key: "state", Value: string(job.State)},
+		{Key: "attempts", Value: job.Attempts},
+		{Key: "runAt", Value: job.RunAt},
+		{Key: "updatedAt", Value: job.UpdatedAt},
+	}
+	unset := bson.D{{Key: "lock", Value: ""}, {Key: "lockedUntil", Value: ""}}
+	if job.Error != "" {
+		set = append(set, bson.E{Key: "error", Value: job.Error})
+	} else {
+		unset = append(unset, bson.E{Key: "error", Value: ""})
+	}
+
+	where := bson.D{{Key: "_id", Value: job.ID}, {Key: "state", Value: string(JobStateEnumRunning)}, {Key: "lock", Value: lock}}
+	result, err := s.collection.UpdateOne(ctx, where, bson.D{{Key: "$set", Value: set}, {Key: "$unset", Value: unset}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// Requeue recoloca na fila a tarefa DEAD.
+func (s *MongoStore) Requeue(ctx context.Context, id string, now time.Time) (bool, error) {
+	where := bson.D{{Key: "_id", Value: id}, {Key: "state", Value: string(JobStateEnumDead)}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "state", Value: string(JobStateEnumPending)},
+		{Key: "attempts", Value: 0},
+		{Key: "runAt", Value: now},
+		{Key: "updatedAt", Value: now},
+	}}}
+	result, err := s.collection.UpdateOne(ctx, where, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// Get retorna a tarefa.
+func (s *MongoStore) Get(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	err := s.collection.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&job)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, notFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Find consulta as tarefas, das mais recentes às mais antigas.
+func (s *MongoStore) Find(ctx context.Context, query Query) ([]*Job, *params.ApiPageInfo, error) {
+	where, err := filter.NewCompiler(mongoFields).Mongo(query.expression())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	jobs := []*Job{}
+	sort := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+	pageInfo, err := pagination.FindMongo(ctx, s.collection, where, query.Pagination, &jobs, sort)
+	if err != nil {
+		return nil, nil, err
+	}
+	return jobs, pageInfo, nil
+}
@@ -0,0 +1,250 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/pagination"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"time"
+)
+
+// mysqlFields são as colunas filtráveis da tabela de tarefas.
+var mysqlFields = filter.Fields{
+	"name":     "name",
+	"state":    "state",
+	"priority": "priority",
+	"urgency":  "urgency",
+}
+
+// mysqlColumns são as colunas lidas da tabela de tarefas.
+const mysqlColumns = "`id`, `name`, `payload`, `priority`, `urgency`, `weight`, `state`, `attempts`, `max_attempts`, `error`, `lock`, `locked_until`, `run_at`, `created_at`, `updated_at`"
+
+// MysqlStore grava as tarefas em uma tabela do MySQL. A reserva utiliza SELECT ... FOR UPDATE SKIP LOCKED (MySQL 8).
+// O DSN deve conter parseTime=true para a leitura das datas.
+type MysqlStore struct {
+	db    *mysql.MysqlDB
+	table string
+}
+
+// NewMysqlStore cria um Store para a tabela informada.
+func NewMysqlStore(db *mysql.MysqlDB, table string) *MysqlStore {
+	return &MysqlStore{db: db, table: table}
+}
+
+// EnsureTable cria a tabela de tarefas, caso ainda não exista.
+func (s *MysqlStore) EnsureTable(ctx context.Context) error {
+	table, err := mysql.QuoteIdentifier(s.table)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Client().ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+table+" ("+
+		"`id` VARCHAR(24) NOT NULL PRIMARY KEY, "+
+		"`name` VARCHAR(255) NOT NULL, "+
+		"`payload` JSON NULL, "+
+		"`priority` VARCHAR(32) NOT NULL, "+
+		"`urgency` VARCHAR(32) NOT NULL, "+
+		"`weight` INT NOT NULL, "+
+		"`state` VARCHAR(32) NOT NULL, "+
+		"`attempts` INT NOT NULL, "+
+		"`max_attempts` INT NOT NULL, "+
+		"`error` TEXT NULL, "+
+		"`lock` VARCHAR(24) NULL, "+
+		"`locked_until` DATETIME(6) NULL, "+
+		"`run_at` DATETIME(6) NOT NULL, "+
+		"`created_at` DATETIME(6) NOT NULL, "+
+		"`updated_at` DATETIME(6) NOT NULL, "+
+		"INDEX `queue_state_weight_run_at` (`state`, `weight`, `run_at`), "+
+		"INDEX `queue_state_locked_until` (`state`, `locked_until`), "+
+		"INDEX `queue_name_created_at` (`name`, `created_at`), "+
+		"INDEX `queue_created_at` (`created_at`))")
+	return err
+}
+
+// Enqueue grava uma nova tarefa.
+func (s *MysqlStore) Enqueue(ctx context.Context, job *Job) error {
+	table, err := mysql.QuoteIdentifier(s.table)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(job.Payload)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Client().ExecContext(ctx, "INSERT INTO "+table+" ("+mysqlColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		job.ID, job.Name, string(payload), string(job.Priority), string(job.Urgency), job.Weight, string(job.State),
+		job.Attempts, job.MaxAttempts, nullString(job.Error), nullString(job.Lock), job.LockedUntil,
+		job.RunAt, job.CreatedAt, job.UpdatedAt)
+	return err
+}
+
+// Claim reserva a próxima tarefa disponível em uma transação, ignorando as linhas bloqueadas por outros workers.
+func (s *MysqlStore) Claim(ctx context.Context, now time.Time, lock string, lockedUntil time.Time) (*Job, error) {
+	table, err := mysql.QuoteIdentifier(s.table)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Client().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id string
+	err = tx.QueryRowContext(ctx, "SELECT `id` FROM "+table+
+		" WHERE (`state` = ? AND `run_at` <= ?) OR (`state` = ? AND `locked_until` <= ?)"+
+		" ORDER BY `weight` DESC, `run_at` ASC, `id` ASC LIMIT 1 FOR UPDATE SKIP LOCKED",
+		string(JobStateEnumPending), now, string(JobStateEnumRunning), now).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE "+table+" SET `state` = ?, `lock` = ?, `locked_until` = ?, `updated_at` = ?, `attempts` = `attempts` + 1 WHERE `id` = ?",
+		string(JobStateEnumRunning), lock, lockedUntil, now, id)
+	if err != nil {
+		return nil, err
+	}
+	job, err := s.get(ctx, tx, table, id)
+	if err != nil {
+		return nil, err
+	}
+	return job, tx.Commit()
+}
+
+// Finish grava o resultado da execução da tarefa, se ainda estiver bloqueada por lock.
+func (s *MysqlStore) Finish(ctx context.Context, job *Job, lock string) (bool, error) {
+	table, err := mysql.QuoteIdentifier(s.table)
+	if err != nil {
+		return false, err
+	}
+	result, err := s.db.Client().ExecContext(ctx, "UPDATE "+table+
+		" SET `state` = ?, `attempts` = ?, `run_at` = ?, `error` = ?, `updated_at` = ?, `lock` = NULL, `locked_until` = NULL"+
+		" WHERE `id` = ? AND `state` = ? AND `lock` = ?",
+		string(job.State), job.Attempts, job.RunAt, nullString(job.Error), job.UpdatedAt,
+		job.ID, string(JobStateEnumRunning), lock)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Requeue recoloca na fila a tarefa DEAD.
+func (s *MysqlStore) Requeue(ctx context.Context, id string, now time.Time) (bool, error) {
+	table, err := mysql.QuoteIdentifier(s.table)
+	if err != nil {
+		return false, err
+	}
+	result, err := s.db.Client().ExecContext(ctx, "UPDATE "+table+
+		" SET `state` = ?, `attempts` = 0, `run_at` = ?, `updated_at` = ? WHERE `id` = ? AND `state` = ?",
+		string(JobStateEnumPending), now, now, id, string(JobStateEnumDead))
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Get retorna a tarefa.
+func (s *MysqlStore) Get(ctx context.Context, id string) (*Job, error) {
+	table, err := mysql.QuoteIdentifier(s.table)
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, s.db.Client(), table, id)
+}
+
+// querier é implementado por *sql.DB e *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// get lê a tarefa pelo identificador.
+func (s *MysqlStore) get(ctx context.Context, db querier, table string, id string) (*Job, error) {
+	rows, err := db.QueryContext(ctx, "SELECT "+mysqlColumns+" FROM "+table+" WHERE `id` = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, notFound(id)
+	}
+	return scanJob(rows)
+}
+
+// Find consulta as tarefas, das mais recentes às mais antigas.
+func (s *MysqlStore) Find(ctx context.Context, query Query) ([]*Job, *params.ApiPageInfo, error) {
+	table, err := mysql.QuoteIdentifier(s.table)
+	if err != nil {
+		return nil, nil, err
+	}
+	where, args, err := filter.NewCompiler(mysqlFields).Mysql(query.expression())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	jobs := []*Job{}
+	sqlQuery := "SELECT " + mysqlColumns + " FROM " + table + " WHERE " + where + " ORDER BY `created_at` DESC, `id` DESC"
+	pageInfo, err := pagination.QueryMysql(ctx, s.db, sqlQuery, args, query.Pagination, func(rows *sql.Rows) error {
+		job, err := scanJob(rows)
+		if err != nil {
+			return err
+		}
+		jobs = append(jobs, job)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return jobs, pageInfo, nil
+}
+
+// scanJob lê uma tarefa da linha atual.
+func scanJob(rows *sql.Rows) (*Job, error) {
+	var (
+		job                      Job
+		payload                  []byte
+		priority, urgency, state string
+		errorText, lock          sql.NullString
+		lockedUntil              sql.NullTime
+	)
+	err := rows.Scan(&job.ID, &job.Name, &payload, &priority, &urgency, &job.Weight, &state,
+		&job.Attempts, &job.MaxAttempts, &errorText, &lock, &lockedUntil, &job.RunAt, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.Priority = status.StatusPriorityEnum(priority)
+	job.Urgency = status.StatusUrgencyEnum(urgency)
+	job.State = JobStateEnum(state)
+	job.Error = errorText.String
+	job.Lock = lock.String
+	if lockedUntil.Valid {
+		job.LockedUntil = &lockedUntil.Time
+	}
+	if len(payload) > 0 {
+		if err = json.Unmarshal(payload, &job.Payload); err != nil {
+			return nil, err
+		}
+	}
+	return &job, nil
+}
+
+// nullString converte o texto vazio para NULL.
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,269 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// authenticator identifica todas as requisições com o usuário informado.
+type authenticator struct {
+	user middleware.User
+}
+
+func (a authenticator) Authenticate(http.ResponseWriter, *http.Request) (middleware.User, bool, error) {
+	return a.user, true, nil
+}
+
+// contextFor retorna o contexto de uma requisição autenticada pelo middleware com o usuário informado.
+func contextFor(user middleware.User) context.Context {
+	var ctx context.Context
+	handler := middleware.Authenticate(authenticator{user: user})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	return ctx
+}
+
+// enqueue grava a tarefa na fila, interrompendo o teste em caso de erro.
+func enqueue(t *testing.T, q *Queue, job *Job) *Job {
+	t.Helper()
+	result, err := q.Enqueue(context.Background(), job)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	return result
+}
+
+// get retorna a tarefa gravada no Store.
+func get(t *testing.T, store Store, id string) *Job {
+	t.Helper()
+	job, err := store.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	return job
+}
+
+func TestEnqueueRequiresName(t *testing.T) {
+	q := NewQueue(NewMemoryStore(), Options{})
+	if _, err := q.Enqueue(context.Background(), &Job{}); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+		t.Errorf("Enqueue = %v, want CodeInvalidArgument", err)
+	}
+}
+
+func TestClaimOrder(t *testing.T) {
+	store := NewMemoryStore()
+	q := NewQueue(store, Options{})
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	enqueue(t, q, &Job{Name: "low", Priority: status.StatusPriorityEnumLow})
+	enqueue(t, q, &Job{Name: "default"})
+	enqueue(t, q, &Job{Name: "veryHigh", Priority: status.StatusPriorityEnumVeryHigh})
+	enqueue(t, q, &Job{Name: "urgentLow", Urgency: status.StatusUrgencyEnumUrgent, Priority: status.StatusPriorityEnumLow})
+	enqueue(t, q, &Job{Name: "veryUrgent", Urgency: status.StatusUrgencyEnumVeryUrgent, RunAt: now.Add(-time.Minute)})
+	enqueue(t, q, &Job{Name: "veryUrgentOlder", Urgency: status.StatusUrgencyEnumVeryUrgent, RunAt: now.Add(-time.Hour)})
+	enqueue(t, q, &Job{Name: "future", Urgency: status.StatusUrgencyEnumVeryUrgent, RunAt: now.Add(time.Hour)})
+
+	want := []string{"veryUrgentOlder", "veryUrgent", "urgentLow", "veryHigh", "default", "low"}
+	for _, name := range want {
+		job, err := store.Claim(ctx, now.Add(time.Second), "lock", now.Add(time.Minute))
+		if err != nil {
+			t.Fatalf("Claim: %v", err)
+		}
+		if job == nil || job.Name != name {
+			t.Fatalf("Claim = %v, want %s", job, name)
+		}
+		if job.State != JobStateEnumRunning || job.Attempts != 1 {
+			t.Errorf("Claim %s: state %s attempts %d, want RUNNING 1", name, job.State, job.Attempts)
+		}
+	}
+	if job, _ := store.Claim(ctx, now.Add(time.Second), "lock", now.Add(time.Minute)); job != nil {
+		t.Errorf("Claim = %s, want nil before RunAt", job.Name)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	q := NewQueue(NewMemoryStore(), Options{Backoff: time.Minute, MaxBackoff: 3 * time.Minute})
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 3 * time.Minute},
+		{10, 3 * time.Minute},
+	}
+	for _, test := range tests {
+		if got := q.backoff(test.attempts); got != test.want {
+			t.Errorf("backoff(%d) = %s, want %s", test.attempts, got, test.want)
+		}
+	}
+}
+
+func TestWorkRetriesWithBackoff(t *testing.T) {
+	store := NewMemoryStore()
+	q := NewQueue(store, Options{Backoff: time.Minute, MaxAttempts: 3})
+	q.Handle("mailSend", func(ctx context.Context, job *Job) error {
+		return errors.New("smtp unavailable")
+	})
+	job := enqueue(t, q, &Job{Name: "mailSend"})
+
+	start := time.Now().UTC()
+	worked, err := q.Work(context.Background())
+	if !worked || err != nil {
+		t.Fatalf("Work = %v, %v", worked, err)
+	}
+
+	current := get(t, store, job.ID)
+	if current.State != JobStateEnumPending || current.Attempts != 1 || current.Error != "smtp unavailable" {
+		t.Errorf("job = %s attempts %d error %q, want PENDING 1 smtp unavailable", current.State, current.Attempts, current.Error)
+	}
+	if current.RunAt.Before(start.Add(time.Minute)) || current.RunAt.After(time.Now().UTC().Add(time.Minute)) {
+		t.Errorf("RunAt = %s, want one minute after the failure", current.RunAt)
+	}
+	if worked, _ = q.Work(context.Background()); worked {
+		t.Error("Work ran the job before the backoff")
+	}
+}
+
+func TestWorkDeadLetter(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler Handler
+		works   int
+		error   string
+	}{
+		{"error", func(ctx context.Context, job *Job) error { return errors.New("failed") }, 2, "failed"},
+		{"panic", func(ctx context.Context, job *Job) error { panic("boom") }, 2, "panic: boom"},
+		{"without handler", nil, 1, "tarefa sem handler registrado"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			q := NewQueue(store, Options{Backoff: time.Nanosecond, MaxAttempts: 2})
+			if test.handler != nil {
+				q.Handle("report", test.handler)
+			}
+			job := enqueue(t, q, &Job{Name: "report"})
+
+			for index := 0; index < test.works; index++ {
+				if current := get(t, store, job.ID); current.State != JobStateEnumPending {
+					t.Fatalf("state = %s before attempt %d, want PENDING", current.State, index+1)
+				}
+				time.Sleep(time.Millisecond)
+				if worked, err := q.Work(context.Background()); !worked || err != nil {
+					t.Fatalf("Work = %v, %v", worked, err)
+				}
+			}
+
+			current := get(t, store, job.ID)
+			if current.State != JobStateEnumDead {
+				t.Fatalf("state = %s, want DEAD", current.State)
+			}
+			if !strings.Contains(current.Error, test.error) {
+				t.Errorf("Error = %q, want %q", current.Error, test.error)
+			}
+		})
+	}
+}
+
+func TestWorkVisibilityTakeover(t *testing.T) {
+	store := NewMemoryStore()
+	q := NewQueue(store, Options{MaxAttempts: 2})
+	ran := 0
+	q.Handle("export", func(ctx context.Context, job *Job) error {
+		ran++
+		return nil
+	})
+	job := enqueue(t, q, &Job{Name: "export", RunAt: time.Now().Add(-time.Hour)})
+	ctx := context.Background()
+
+	// Um worker reservou a tarefa e não a concluiu dentro do tempo de visibilidade
+	past := time.Now().UTC().Add(-10 * time.Minute)
+	stale, err := store.Claim(ctx, past, "stale", past.Add(time.Minute))
+	if err != nil || stale == nil {
+		t.Fatalf("Claim = %v, %v", stale, err)
+	}
+
+	if worked, err := q.Work(ctx); !worked || err != nil {
+		t.Fatalf("Work = %v, %v", worked, err)
+	}
+	current := get(t, store, job.ID)
+	if ran != 1 || current.State != JobStateEnumSucceeded || current.Attempts != 2 {
+		t.Errorf("ran %d, job %s attempts %d, want 1 SUCCEEDED 2", ran, current.State, current.Attempts)
+	}
+
+	stale.State = JobStateEnumPending
+	if finished, err := store.Finish(ctx, stale, "stale"); finished || err != nil {
+		t.Errorf("Finish with the expired lock = %v, %v, want false", finished, err)
+	}
+	if current = get(t, store, job.ID); current.State != JobStateEnumSucceeded {
+		t.Errorf("state = %s after the expired worker finished, want SUCCEEDED", current.State)
+	}
+}
+
+func TestWorkExceededAttempts(t *testing.T) {
+	store := NewMemoryStore()
+	q := NewQueue(store, Options{})
+	q.Handle("import", func(ctx context.Context, job *Job) error {
+		t.Error("handler called after the attempts were exceeded")
+		return nil
+	})
+	job := enqueue(t, q, &Job{Name: "import", MaxAttempts: 1, RunAt: time.Now().Add(-time.Hour)})
+	ctx := context.Background()
+
+	past := time.Now().UTC().Add(-10 * time.Minute)
+	if _, err := store.Claim(ctx, past, "stale", past.Add(time.Minute)); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if _, err := q.Work(ctx); err != nil {
+		t.Fatalf("Work: %v", err)
+	}
+	if current := get(t, store, job.ID); current.State != JobStateEnumDead {
+		t.Errorf("state = %s, want DEAD", current.State)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	store := NewMemoryStore()
+	q := NewQueue(store, Options{MaxAttempts: 1})
+	q.Handle("sync", func(ctx context.Context, job *Job) error { return errors.New("failed") })
+	dead := enqueue(t, q, &Job{Name: "sync"})
+	if _, err := q.Work(context.Background()); err != nil {
+		t.Fatalf("Work: %v", err)
+	}
+	pending := enqueue(t, q, &Job{Name: "sync", RunAt: time.Now().Add(time.Hour)})
+
+	admin := contextFor(middleware.User{ID: "admin", IsAdmin: true})
+	user := contextFor(middleware.User{ID: "user"})
+
+	if _, err := q.Retry(user, dead.ID); !errors.Is(err, api_error.New(api_error.CodePermissionDenied, "", "")) {
+		t.Errorf("Retry by user = %v, want CodePermissionDenied", err)
+	}
+	if _, err := q.Retry(admin, pending.ID); !errors.Is(err, api_error.New(api_error.CodeFailedPrecondition, "", "")) {
+		t.Errorf("Retry PENDING = %v, want CodeFailedPrecondition", err)
+	}
+	if _, err := q.Retry(admin, "missing"); !errors.Is(err, api_error.New(api_error.CodeNotFound, "", "")) {
+		t.Errorf("Retry missing = %v, want CodeNotFound", err)
+	}
+
+	job, err := q.Retry(admin, dead.ID)
+	if err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+	if job.State != JobStateEnumPending || job.Attempts != 0 {
+		t.Errorf("job = %s attempts %d, want PENDING 0", job.State, job.Attempts)
+	}
+	if worked, _ := q.Work(context.Background()); !worked {
+		t.Error("Work did not run the requeued job")
+	}
+}
@@ -0,0 +1,142 @@
+// Package search fornece as buscas compartilhadas pelos módulos: intervalo de datas (ApiDateSearchInput)
+// e expressões regulares (ApiSearchRegexInput), traduzidas para predicados MongoDB e MySQL.
+package search
+
+import (
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/filter"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"time"
+)
+
+const module = "api_connect/search"
+
+// DateFields define os campos de data de criação e alteração.
+type DateFields struct {
+	Created string
+	Updated string
+}
+
+// Campos de data padrão, correspondentes a ApiInfo.createdAt e ApiInfo.changedAt.
+var (
+	DefaultMongoDateFields = DateFields{Created: "_info.createdAt", Updated: "_info.changedAt"}
+	DefaultMysqlDateFields = DateFields{Created: "created_at", Updated: "changed_at"}
+)
+
+// ValidateDate verifica se os intervalos de data são válidos:
+// o início deve ser anterior ao fim e EQUAL_eq não pode ser combinado com outro limite.
+func ValidateDate(input *params.ApiDateSearchInput) error {
+	if input == nil {
+		return nil
+	}
+	if err := validateDateOptions("created", input.Created); err != nil {
+		return err
+	}
+	return validateDateOptions("updated", input.Updated)
+}
+
+// validateDateOptions valida um intervalo de datas.
+func validateDateOptions(path string, options *params.ApiDateOptionsInput) error {
+	if options == nil {
+		return nil
+	}
+	if options.AStart == nil {
+		return api_error.New(api_error.CodeInvalidArgument, module, path, "a data inicial é obrigatória")
+	}
+
+	start := options.AStart
+	end := options.BEnd
+	if !isDateStartOperator(start.Operator) {
+		return api_error.New(api_error.CodeInvalidArgument, module, path, "operador da data inicial inválido").
+			With("operator", string(start.Operator))
+	}
+	if end == nil {
+		return nil
+	}
+	if !isDateEndOperator(end.Operator) {
+		return api_error.New(api_error.CodeInvalidArgument, module, path, "operador da data final inválido").
+			With("operator", string(end.Operator))
+	}
+
+	if start.Operator == params.DateStartComparisonOperatorsEqualEq || end.Operator == params.DateEndComparisonOperatorsEqualEq {
+		return api_error.New(api_error.CodeInvalidArgument, module, path, "EQUAL_eq não pode ser combinado com outro limite de data").
+			With("start", string(start.Operator)).
+			With("end", string(end.Operator))
+	}
+
+	inclusive := start.Operator == params.DateStartComparisonOperatorsGreaterThanOrEqualGte &&
+		end.Operator == params.DateEndComparisonOperatorsLessThanOrEqualLte
+	if end.Date.Before(start.Date) || (!inclusive && !start.Date.Before(end.Date)) {
+		return api_error.New(api_error.CodeInvalidArgument, module, path, "a data inicial deve ser anterior à data final").
+			With("start", start.Date.UTC().Format(time.RFC3339)).
+			With("end", end.Date.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// DateExpression valida o ApiDateSearchInput e o converte em uma expressão de filtro sobre os campos informados.
+// As datas do input incluem o fuso horário e são comparadas como instantes, convertidas para UTC.
+// Retorna CodeInvalidArgument quando o intervalo é informado para um campo não definido em fields.
+func DateExpression(input *params.ApiDateSearchInput, fields DateFields) (filter.Expression, error) {
+	if err := ValidateDate(input); err != nil {
+		return filter.Expression{}, err
+	}
+	if input == nil {
+		return filter.Expression{}, nil
+	}
+
+	created, err := dateOptionsExpression("created", fields.Created, input.Created)
+	if err != nil {
+		return filter.Expression{}, err
+	}
+	updated, err := dateOptionsExpression("updated", fields.Updated, input.Updated)
+	if err != nil {
+		return filter.Expression{}, err
+	}
+	return filter.And(append(created, updated...)...), nil
+}
+
+// dateOptionsExpression converte um intervalo de datas nas condições de filtro do campo.
+func dateOptionsExpression(path string, field string, options *params.ApiDateOptionsInput) ([]filter.Expression, error) {
+	if options == nil {
+		return nil, nil
+	}
+	if field == "" {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, path, "o módulo não permite a busca por esta data")
+	}
+	result := []filter.Expression{
+		filter.Condition(field, params.ComparisonQueryOperators(options.AStart.Operator), options.AStart.Date.UTC()),
+	}
+	if options.BEnd != nil {
+		result = append(result, filter.Condition(field, params.ComparisonQueryOperators(options.BEnd.Operator), options.BEnd.Date.UTC()))
+	}
+	return result, nil
+}
+
+// dateCompiler cria um compilador de filtros que permite somente os campos de data.
+func dateCompiler(fields DateFields) *filter.Compiler {
+	return filter.NewCompiler(filter.Fields{
+		fields.Created: fields.Created,
+		fields.Updated: fields.Updated,
+	})
+}
+
+// isDateStartOperator verifica se o operador da data inicial é conhecido.
+func isDateStartOperator(operator params.DateStartComparisonOperators) bool {
+	switch operator {
+	case params.DateStartComparisonOperatorsEqualEq, params.DateStartComparisonOperatorsGreaterThanGt,
+		params.DateStartComparisonOperatorsGreaterThanOrEqualGte:
+		return true
+	}
+	return false
+}
+
+// isDateEndOperator verifica se o operador da data final é conhecido.
+func isDateEndOperator(operator params.DateEndComparisonOperators) bool {
+	switch operator {
+	case params.DateEndComparisonOperatorsEqualEq, params.DateEndComparisonOperatorsLessThanLt,
+		params.DateEndComparisonOperatorsLessThanOrEqualLte:
+		return true
+	}
+	return false
+}
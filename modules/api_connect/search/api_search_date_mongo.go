@@ -0,0 +1,19 @@
+package search
+
+import (
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// DateMongo converte o ApiDateSearchInput em um filtro bson.D do MongoDB.
+// Se fields for vazio, utiliza DefaultMongoDateFields.
+func DateMongo(input *params.ApiDateSearchInput, fields DateFields) (bson.D, error) {
+	if fields.Created == "" && fields.Updated == "" {
+		fields = DefaultMongoDateFields
+	}
+	expression, err := DateExpression(input, fields)
+	if err != nil {
+		return nil, err
+	}
+	return dateCompiler(fields).Mongo(expression)
+}
@@ -0,0 +1,18 @@
+package search
+
+import (
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+)
+
+// DateMysql converte o ApiDateSearchInput em uma cláusula WHERE parametrizada.
+// Se fields for vazio, utiliza DefaultMysqlDateFields.
+func DateMysql(input *params.ApiDateSearchInput, fields DateFields) (string, []interface{}, error) {
+	if fields.Created == "" && fields.Updated == "" {
+		fields = DefaultMysqlDateFields
+	}
+	expression, err := DateExpression(input, fields)
+	if err != nil {
+		return "", nil, err
+	}
+	return dateCompiler(fields).Mysql(expression)
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// dateRange monta um ApiDateOptionsInput; end é omitido quando o operador final é vazio.
+func dateRange(start time.Time, startOperator params.DateStartComparisonOperators, end time.Time, endOperator params.DateEndComparisonOperators) *params.ApiDateOptionsInput {
+	options := &params.ApiDateOptionsInput{AStart: &params.ApiDateStartSearchInput{Date: start, Operator: startOperator}}
+	if endOperator != "" {
+		options.BEnd = &params.ApiDateEndSearchInput{Date: end, Operator: endOperator}
+	}
+	return options
+}
+
+func TestValidateDate(t *testing.T) {
+	may := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	const (
+		eq  = params.DateStartComparisonOperatorsEqualEq
+		gt  = params.DateStartComparisonOperatorsGreaterThanGt
+		gte = params.DateStartComparisonOperatorsGreaterThanOrEqualGte
+		eqe = params.DateEndComparisonOperatorsEqualEq
+		lt  = params.DateEndComparisonOperatorsLessThanLt
+		lte = params.DateEndComparisonOperatorsLessThanOrEqualLte
+	)
+
+	tests := []struct {
+		name    string
+		options *params.ApiDateOptionsInput
+		valid   bool
+	}{
+		{"start only", dateRange(may, gte, time.Time{}, ""), true},
+		{"EQUAL_eq without end", dateRange(may, eq, time.Time{}, ""), true},
+		{"range", dateRange(may, gte, june, lt), true},
+		{"exclusive range", dateRange(may, gt, june, lt), true},
+		{"same instant inclusive", dateRange(may, gte, may, lte), true},
+		{"same instant in another time zone", dateRange(may, gte, may.In(time.FixedZone("BRT", -3*3600)), lte), true},
+		{"same instant exclusive", dateRange(may, gt, may, lte), false},
+		{"same instant half open", dateRange(may, gte, may, lt), false},
+		{"start after end", dateRange(june, gte, may, lte), false},
+		{"start after end in another time zone", dateRange(may, gte, may.Add(-time.Hour).In(time.FixedZone("CET", 3600)), lte), false},
+		{"EQUAL_eq on the start", dateRange(may, eq, june, lt), false},
+		{"EQUAL_eq on the end", dateRange(may, gte, june, eqe), false},
+		{"EQUAL_eq on both sides", dateRange(may, eq, may, eqe), false},
+		{"unknown start operator", dateRange(may, "LESS_THAN_lt", june, lt), false},
+		{"unknown end operator", dateRange(may, gte, june, "GREATER_THAN_gt"), false},
+		{"without start", &params.ApiDateOptionsInput{BEnd: &params.ApiDateEndSearchInput{Date: june, Operator: lt}}, false},
+	}
+	for _, test := range tests {
+		for _, input := range []*params.ApiDateSearchInput{{Created: test.options}, {Updated: test.options}} {
+			err := ValidateDate(input)
+			if test.valid && err != nil {
+				t.Errorf("%s: ValidateDate = %v", test.name, err)
+			}
+			if !test.valid && !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+				t.Errorf("%s: ValidateDate = %v, want INVALID_ARGUMENT", test.name, err)
+			}
+		}
+	}
+	if err := ValidateDate(nil); err != nil {
+		t.Errorf("ValidateDate(nil) = %v", err)
+	}
+}
+
+func TestDateMysql(t *testing.T) {
+	brt := time.FixedZone("BRT", -3*3600)
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, brt)
+	end := time.Date(2024, 6, 1, 0, 0, 0, 0, brt)
+	input := &params.ApiDateSearchInput{
+		Created: dateRange(start, params.DateStartComparisonOperatorsGreaterThanOrEqualGte, end, params.DateEndComparisonOperatorsLessThanLt),
+		Updated: dateRange(start, params.DateStartComparisonOperatorsGreaterThanGt, time.Time{}, ""),
+	}
+
+	where, args, err := DateMysql(input, DateFields{})
+	if err != nil {
+		t.Fatalf("DateMysql: %v", err)
+	}
+	if want := "(`created_at` >= ? AND `created_at` < ? AND `changed_at` > ?)"; where != want {
+		t.Errorf("where = %q, want %q", where, want)
+	}
+	if want := []interface{}{start.UTC(), end.UTC(), start.UTC()}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+	if args[0].(time.Time).Location() != time.UTC || args[0].(time.Time).Hour() != 3 {
+		t.Errorf("args[0] = %v, want the instant in UTC", args[0])
+	}
+
+	where, args, err = DateMysql(nil, DateFields{})
+	if err != nil || len(args) != 0 {
+		t.Errorf("DateMysql(nil) = %q %v, %v", where, args, err)
+	}
+}
+
+func TestDateMongo(t *testing.T) {
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	input := &params.ApiDateSearchInput{Created: dateRange(day, params.DateStartComparisonOperatorsEqualEq, time.Time{}, "")}
+
+	got, err := DateMongo(input, DateFields{})
+	if err != nil {
+		t.Fatalf("DateMongo: %v", err)
+	}
+	want := bson.D{{Key: "$and", Value: bson.A{bson.D{{Key: "_info.createdAt", Value: bson.D{{Key: "$eq", Value: day}}}}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DateMongo = %v, want %v", got, want)
+	}
+}
+
+func TestDateWithoutUpdatedField(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	fields := DateFields{Created: "created_at"}
+	updated := &params.ApiDateSearchInput{Updated: dateRange(day, params.DateStartComparisonOperatorsGreaterThanGt, time.Time{}, "")}
+
+	if _, _, err := DateMysql(updated, fields); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+		t.Errorf("DateMysql by updated = %v, want INVALID_ARGUMENT", err)
+	}
+	if _, err := DateMongo(updated, DateFields{Created: "createdAt"}); !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+		t.Errorf("DateMongo by updated = %v, want INVALID_ARGUMENT", err)
+	}
+
+	created := &params.ApiDateSearchInput{Created: dateRange(day, params.DateStartComparisonOperatorsGreaterThanGt, time.Time{}, "")}
+	if where, _, err := DateMysql(created, fields); err != nil || where != "(`created_at` > ?)" {
+		t.Errorf("DateMysql by created = %q, %v", where, err)
+	}
+}
@@ -0,0 +1,263 @@
+package search
+
+import (
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// RegexLimits define os limites aplicados às expressões regulares enviadas pelos clientes.
+type RegexLimits struct {
+	// MaxLength é o tamanho máximo do padrão.
+	MaxLength int
+
+	// MaxRepeat é o maior valor permitido em repetições {n,m}.
+	MaxRepeat int
+
+	// MaxComplexity é a quantidade máxima de nós da árvore sintática do padrão.
+	MaxComplexity int
+}
+
+// DefaultRegexLimits são os limites utilizados quando nenhum limite é informado.
+var DefaultRegexLimits = RegexLimits{MaxLength: 100, MaxRepeat: 50, MaxComplexity: 40}
+
+// regexOptions são as opções aceitas e os respectivos match_type do REGEXP_LIKE do MySQL.
+var regexOptions = map[rune]string{
+	'i': "i",
+	'm': "m",
+	's': "n",
+}
+
+// Regex é uma expressão regular validada.
+type Regex struct {
+	Pattern string
+	Options string
+	prefix  string
+	isFixed bool
+}
+
+// Prefix retorna o prefixo literal de um padrão ancorado (^prefixo) sem as opções "i" e "m".
+// O segundo retorno indica se o padrão é somente o prefixo, permitindo o uso de índices.
+func (r *Regex) Prefix() (string, bool) {
+	return r.prefix, r.isFixed
+}
+
+// CompileRegex valida opções, tamanho e complexidade do padrão e rejeita construções com risco de
+// backtracking catastrófico (quantificadores aninhados, quantificadores ilimitados adjacentes que podem
+// consumir os mesmos caracteres, alternâncias repetidas, retrovisores e lookarounds).
+func CompileRegex(input *params.ApiSearchRegexInput, limits RegexLimits) (*Regex, error) {
+	if input == nil {
+		return nil, nil
+	}
+	if limits.MaxLength <= 0 {
+		limits = DefaultRegexLimits
+	}
+
+	if err := validateRegexOptions(input.Options); err != nil {
+		return nil, err
+	}
+	if input.Pattern == "" || len(input.Pattern) > limits.MaxLength {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "regex", "tamanho do padrão inválido").
+			With("length", len(input.Pattern)).
+			With("max", limits.MaxLength)
+	}
+
+	// A árvore sintática une alternâncias como (a|a) em uma classe, por isso são verificadas no texto do padrão
+	if repeatedAlternation(input.Pattern) {
+		return nil, dangerousRegex()
+	}
+
+	// A sintaxe RE2 não possui retrovisores nem lookarounds, que são rejeitados como padrões inválidos.
+	flags := syntax.Perl
+	if strings.ContainsRune(input.Options, 'i') {
+		flags |= syntax.FoldCase
+	}
+	tree, err := syntax.Parse(input.Pattern, flags)
+	if err != nil {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "regex", "padrão inválido ou não suportado").
+			With("pattern", input.Pattern)
+	}
+
+	complexity := 0
+	if err = checkRegexTree(tree, limits, false, &complexity); err != nil {
+		return nil, err
+	}
+
+	result := &Regex{Pattern: input.Pattern, Options: input.Options}
+	if !strings.ContainsAny(input.Options, "im") {
+		result.prefix, result.isFixed = regexPrefix(tree)
+	}
+	return result, nil
+}
+
+// validateRegexOptions verifica se as opções são conhecidas e não repetidas.
+func validateRegexOptions(options string) error {
+	seen := map[rune]bool{}
+	for _, option := range options {
+		if _, ok := regexOptions[option]; !ok || seen[option] {
+			return api_error.New(api_error.CodeInvalidArgument, module, "regex", "opção de expressão regular inválida").
+				With("options", options)
+		}
+		seen[option] = true
+	}
+	return nil
+}
+
+// checkRegexTree percorre a árvore sintática contando os nós e rejeitando construções perigosas.
+func checkRegexTree(tree *syntax.Regexp, limits RegexLimits, repeated bool, complexity *int) error {
+	*complexity++
+	if *complexity > limits.MaxComplexity {
+		return api_error.New(api_error.CodeInvalidArgument, module, "regex", "padrão excede a complexidade permitida").
+			With("max", limits.MaxComplexity)
+	}
+
+	isRepeat := false
+	switch tree.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		isRepeat = true
+	case syntax.OpRepeat:
+		if tree.Min > limits.MaxRepeat || tree.Max > limits.MaxRepeat {
+			return api_error.New(api_error.CodeInvalidArgument, module, "regex", "repetição excede o limite permitido").
+				With("max", limits.MaxRepeat)
+		}
+		isRepeat = tree.Max == -1 || tree.Max > 1
+	case syntax.OpAlternate:
+		if repeated {
+			return dangerousRegex()
+		}
+	case syntax.OpConcat:
+		for index := 1; index < len(tree.Sub); index++ {
+			previous, current := tree.Sub[index-1], tree.Sub[index]
+			if isUnbounded(previous) && isUnbounded(current) && overlaps(previous.Sub[0], current.Sub[0]) {
+				return dangerousRegex()
+			}
+		}
+	}
+
+	if isRepeat && repeated {
+		return dangerousRegex()
+	}
+
+	for _, sub := range tree.Sub {
+		if err := checkRegexTree(sub, limits, repeated || isRepeat, complexity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isUnbounded indica se o nó é uma repetição sem limite máximo (*, + ou {n,}).
+func isUnbounded(tree *syntax.Regexp) bool {
+	return tree.Op == syntax.OpStar || tree.Op == syntax.OpPlus || (tree.Op == syntax.OpRepeat && tree.Max == -1)
+}
+
+// overlaps indica se dois nós repetidos podem consumir o mesmo caractere. Somente nós de um caractere
+// (literal, classe ou ponto) são comparados; os demais são considerados sobrepostos.
+func overlaps(left, right *syntax.Regexp) bool {
+	leftRanges, ok := runeRanges(left)
+	if !ok {
+		return true
+	}
+	rightRanges, ok := runeRanges(right)
+	if !ok {
+		return true
+	}
+	for i := 0; i+1 < len(leftRanges); i += 2 {
+		for j := 0; j+1 < len(rightRanges); j += 2 {
+			if leftRanges[i] <= rightRanges[j+1] && rightRanges[j] <= leftRanges[i+1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runeRanges retorna os intervalos de caracteres [início, fim, ...] de um nó de um caractere.
+func runeRanges(tree *syntax.Regexp) ([]rune, bool) {
+	switch tree.Op {
+	case syntax.OpLiteral:
+		if len(tree.Rune) != 1 {
+			return nil, false
+		}
+		ranges := []rune{tree.Rune[0], tree.Rune[0]}
+		if tree.Flags&syntax.FoldCase != 0 {
+			for folded := unicode.SimpleFold(tree.Rune[0]); folded != tree.Rune[0]; folded = unicode.SimpleFold(folded) {
+				ranges = append(ranges, folded, folded)
+			}
+		}
+		return ranges, true
+	case syntax.OpCharClass:
+		return tree.Rune, true
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return []rune{0, unicode.MaxRune}, true
+	}
+	return nil, false
+}
+
+// repeatedAlternation indica se o padrão possui um grupo com alternância seguido de *, + ou {, por exemplo (a|a)*.
+func repeatedAlternation(pattern string) bool {
+	var groups []bool
+	inClass := false
+	for index := 0; index < len(pattern); index++ {
+		switch character := pattern[index]; {
+		case character == '\\':
+			index++
+		case inClass:
+			if character == ']' {
+				inClass = false
+			}
+		case character == '[':
+			inClass = true
+			if index+1 < len(pattern) && pattern[index+1] == '^' {
+				index++
+			}
+			if index+1 < len(pattern) && pattern[index+1] == ']' {
+				index++
+			}
+		case character == '(':
+			groups = append(groups, false)
+		case character == '|' && len(groups) > 0:
+			groups[len(groups)-1] = true
+		case character == ')' && len(groups) > 0:
+			alternate := groups[len(groups)-1]
+			groups = groups[:len(groups)-1]
+			if alternate && index+1 < len(pattern) && strings.IndexByte("*+{", pattern[index+1]) >= 0 {
+				return true
+			}
+			if alternate && len(groups) > 0 {
+				groups[len(groups)-1] = true
+			}
+		}
+	}
+	return false
+}
+
+// dangerousRegex retorna o erro de padrões com risco de backtracking catastrófico.
+func dangerousRegex() error {
+	return api_error.New(api_error.CodeInvalidArgument, module, "regex", "padrão com quantificadores aninhados não é permitido")
+}
+
+// regexPrefix retorna o prefixo literal de um padrão iniciado por ^ e se o padrão é somente esse prefixo
+// (opcionalmente seguido de .*).
+func regexPrefix(tree *syntax.Regexp) (string, bool) {
+	if tree.Op != syntax.OpConcat || len(tree.Sub) < 2 || tree.Sub[0].Op != syntax.OpBeginText {
+		return "", false
+	}
+	literal := tree.Sub[1]
+	if literal.Op != syntax.OpLiteral || literal.Flags&syntax.FoldCase != 0 {
+		return "", false
+	}
+
+	prefix := string(literal.Rune)
+	rest := tree.Sub[2:]
+	if len(rest) == 0 {
+		return prefix, true
+	}
+	if len(rest) == 1 && rest[0].Op == syntax.OpStar && len(rest[0].Sub) == 1 &&
+		(rest[0].Sub[0].Op == syntax.OpAnyChar || rest[0].Sub[0].Op == syntax.OpAnyCharNotNL) {
+		return prefix, true
+	}
+	return prefix, false
+}
@@ -0,0 +1,23 @@
+package search
+
+import (
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"regexp"
+)
+
+// RegexMongo valida o ApiSearchRegexInput e o converte no filtro {campo: {$regex, $options}} do MongoDB.
+// Padrões somente de prefixo (^prefixo) são enviados com o prefixo escapado, permitindo o uso de índices.
+func RegexMongo(field string, input *params.ApiSearchRegexInput, limits RegexLimits) (bson.D, error) {
+	regex, err := CompileRegex(input, limits)
+	if err != nil || regex == nil {
+		return bson.D{}, err
+	}
+
+	pattern := regex.Pattern
+	if prefix, isFixed := regex.Prefix(); isFixed {
+		pattern = "^" + regexp.QuoteMeta(prefix)
+	}
+	return bson.D{{Key: field, Value: primitive.Regex{Pattern: pattern, Options: regex.Options}}}, nil
+}
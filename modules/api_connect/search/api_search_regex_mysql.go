@@ -0,0 +1,39 @@
+package search
+
+import (
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"strings"
+)
+
+// RegexMysql valida o ApiSearchRegexInput e o converte em uma condição parametrizada do MySQL.
+// Padrões somente de prefixo (^prefixo) adicionam LIKE 'prefixo%' com a collation da coluna, que permite a busca
+// pelo índice, ao REGEXP_LIKE que confirma a comparação com diferenciação de maiúsculas e minúsculas;
+// os demais utilizam somente REGEXP_LIKE com o match_type correspondente às opções.
+func RegexMysql(column string, input *params.ApiSearchRegexInput, limits RegexLimits) (string, []interface{}, error) {
+	regex, err := CompileRegex(input, limits)
+	if err != nil || regex == nil {
+		return "1 = 1", nil, err
+	}
+	quoted, err := mysql.QuoteIdentifier(column)
+	if err != nil {
+		return "", nil, err
+	}
+
+	matchType := "c"
+	for _, option := range regex.Options {
+		matchType += regexOptions[option]
+	}
+	where := "REGEXP_LIKE(" + quoted + ", ?, ?)"
+	args := []interface{}{regex.Pattern, matchType}
+
+	if prefix, isFixed := regex.Prefix(); isFixed {
+		return "(" + quoted + " LIKE ? AND " + where + ")", append([]interface{}{escapeLike(prefix) + "%"}, args...), nil
+	}
+	return where, args, nil
+}
+
+// escapeLike escapa os caracteres especiais do LIKE.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompileRegex(t *testing.T) {
+	tests := []struct {
+		pattern string
+		options string
+		valid   bool
+	}{
+		{"^abc", "", true},
+		{"abc.*def", "i", true},
+		{`\d+-\d+`, "", true},
+		{"[a-z]+[0-9]+", "", true},
+		{".*x.*", "", true},
+		{"(ab)?c", "", true},
+		{"a|b", "", true},
+		{"[|]+", "", true},
+		{`\(a|b\)*`, "", true},
+		{"(a+)+", "", false},
+		{"(a*)*b", "", false},
+		{"(a|b)*", "", false},
+		{"(a|a)*", "", false},
+		{"(?:x|y)+z", "", false},
+		{"((a|b)c)+", "", false},
+		{".*.*.*", "", false},
+		{"a*a+", "", false},
+		{"[a-z]+[m-p]*", "", false},
+		{"a*A*", "i", false},
+		{"a{1,}.+", "", false},
+		{"a{1,100}", "", false},
+		{`(a)\1`, "", false},
+		{"(?=a)", "", false},
+		{"abc", "x", false},
+		{"abc", "ii", false},
+		{"", "", false},
+		{strings.Repeat("a", 101), "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			_, err := CompileRegex(&params.ApiSearchRegexInput{Pattern: test.pattern, Options: test.options}, RegexLimits{})
+			if test.valid && err != nil {
+				t.Errorf("CompileRegex = %v, want valid", err)
+			}
+			if !test.valid && !errors.Is(err, api_error.New(api_error.CodeInvalidArgument, "", "")) {
+				t.Errorf("CompileRegex = %v, want CodeInvalidArgument", err)
+			}
+		})
+	}
+}
+
+func TestRegexPrefix(t *testing.T) {
+	tests := []struct {
+		pattern string
+		options string
+		prefix  string
+		isFixed bool
+	}{
+		{"^abc", "", "abc", true},
+		{"^abc.*", "", "abc", true},
+		{"^abc[0-9]", "", "abc", false},
+		{"^abc", "i", "", false},
+		{"abc", "", "", false},
+	}
+	for _, test := range tests {
+		regex, err := CompileRegex(&params.ApiSearchRegexInput{Pattern: test.pattern, Options: test.options}, RegexLimits{})
+		if err != nil {
+			t.Fatalf("CompileRegex(%q): %v", test.pattern, err)
+		}
+		if prefix, isFixed := regex.Prefix(); prefix != test.prefix || isFixed != test.isFixed {
+			t.Errorf("Prefix(%q) = %q, %v, want %q, %v", test.pattern, prefix, isFixed, test.prefix, test.isFixed)
+		}
+	}
+}
+
+func TestRegexMysql(t *testing.T) {
+	where, args, err := RegexMysql("name", &params.ApiSearchRegexInput{Pattern: "^50%_off"}, RegexLimits{})
+	if err != nil {
+		t.Fatalf("RegexMysql: %v", err)
+	}
+	if want := "(`name` LIKE ? AND REGEXP_LIKE(`name`, ?, ?))"; where != want {
+		t.Errorf("where = %s, want %s", where, want)
+	}
+	if want := []interface{}{`50\%\_off%`, "^50%_off", "c"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+
+	where, args, err = RegexMysql("name", &params.ApiSearchRegexInput{Pattern: "ana", Options: "is"}, RegexLimits{})
+	if err != nil {
+		t.Fatalf("RegexMysql: %v", err)
+	}
+	if where != "REGEXP_LIKE(`name`, ?, ?)" || !reflect.DeepEqual(args, []interface{}{"ana", "cin"}) {
+		t.Errorf("RegexMysql = %s %v", where, args)
+	}
+}
+
+func TestRegexMongo(t *testing.T) {
+	got, err := RegexMongo("name", &params.ApiSearchRegexInput{Pattern: "^a.b.*"}, RegexLimits{})
+	if err != nil {
+		t.Fatalf("RegexMongo: %v", err)
+	}
+	want := bson.D{{Key: "name", Value: primitive.Regex{Pattern: "^a.b.*"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RegexMongo = %v, want %v", got, want)
+	}
+
+	got, _ = RegexMongo("name", &params.ApiSearchRegexInput{Pattern: "^a\\.b"}, RegexLimits{})
+	want = bson.D{{Key: "name", Value: primitive.Regex{Pattern: "^a\\.b"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RegexMongo = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+// Package status contém a representação Go dos enums GraphQL definidos em modules/api_connect/status/schemas.
+package status
+
+// StatusDirectionSortEnum são as direções de ordenação de um argumento orderBy.
+type StatusDirectionSortEnum string
+
+const (
+	StatusDirectionSortEnumAsc  StatusDirectionSortEnum = "ASC"
+	StatusDirectionSortEnumDesc StatusDirectionSortEnum = "DESC"
+)
+
+// StatusIncludeEnum indica que o campo selecionado deve ser incluído na consulta.
+type StatusIncludeEnum string
+
+const (
+	StatusIncludeEnumInclude StatusIncludeEnum = "INCLUDE"
+)
+
+// StatusIncludeNotIncludeEnum indica se o campo selecionado deve ou não ser incluído na consulta.
+type StatusIncludeNotIncludeEnum string
+
+const (
+	StatusIncludeNotIncludeEnumInclude   StatusIncludeNotIncludeEnum = "INCLUDE"
+	StatusIncludeNotIncludeEnumNoInclude StatusIncludeNotIncludeEnum = "NO_INCLUDE"
+)
+
+// StatusEnabledDisabledEnum indica se o documento está ativo ou desabilitado para uso.
+type StatusEnabledDisabledEnum string
+
+const (
+	StatusEnabledDisabledEnumUndefined StatusEnabledDisabledEnum = "UNDEFINED"
+	StatusEnabledDisabledEnumEnabled   StatusEnabledDisabledEnum = "ENABLED"
+	StatusEnabledDisabledEnumDisabled  StatusEnabledDisabledEnum = "DISABLED"
+)
+
+// StatusEnabledDisabledSearchInput é a representação Go do input GraphQL StatusEnabledDisabledSearchInput.
+type StatusEnabledDisabledSearchInput struct {
+	Enabled  *StatusIncludeNotIncludeEnum `json:"ENABLED"`
+	Disabled *StatusIncludeNotIncludeEnum `json:"DISABLED"`
+}
+
+// StatusPrivacyEnum são as regras de privacidade aplicadas aos dados.
+type StatusPrivacyEnum string
+
+const (
+	StatusPrivacyEnumUndefined         StatusPrivacyEnum = "UNDEFINED"
+	StatusPrivacyEnumPublic            StatusPrivacyEnum = "PUBLIC"
+	StatusPrivacyEnumPrivate           StatusPrivacyEnum = "PRIVATE"
+	StatusPrivacyEnumProtected         StatusPrivacyEnum = "PROTECTED"
+	StatusPrivacyEnumDirectConnections StatusPrivacyEnum = "DIRECT_CONNECTIONS"
+	StatusPrivacyEnumCustomLists       StatusPrivacyEnum = "CUSTOM_LISTS"
+)
+
+// StatusEvaluationEnum são os status de avaliação.
+type StatusEvaluationEnum string
+
+const (
+	StatusEvaluationEnumUndefined       StatusEvaluationEnum = "UNDEFINED"
+	StatusEvaluationEnumNoEvaluation    StatusEvaluationEnum = "NO_EVALUATION"
+	StatusEvaluationEnumNoDocuments     StatusEvaluationEnum = "NO_DOCUMENTS"
+	StatusEvaluationEnumWaitingApproval StatusEvaluationEnum = "WAITING_APPROVAL"
+	StatusEvaluationEnumEvaluation      StatusEvaluationEnum = "EVALUATION"
+	StatusEvaluationEnumApproved        StatusEvaluationEnum = "APPROVED"
+	StatusEvaluationEnumRejected        StatusEvaluationEnum = "REJECTED"
+)
+
+// StatusVerifiedEnum são os status de verificação de dados.
+type StatusVerifiedEnum string
+
+const (
+	StatusVerifiedEnumUndefined             StatusVerifiedEnum = "UNDEFINED"
+	StatusVerifiedEnumAwaitingVerification  StatusVerifiedEnum = "AWAITING_VERIFICATION"
+	StatusVerifiedEnumAwaitingDocumentation StatusVerifiedEnum = "AWAITING_DOCUMENTATION"
+	StatusVerifiedEnumApproved              StatusVerifiedEnum = "APPROVED"
+	StatusVerifiedEnumDeclined              StatusVerifiedEnum = "DECLINED"
+)
+
+// StatusPriorityEnum são as opções de prioridade.
+type StatusPriorityEnum string
+
+const (
+	StatusPriorityEnumUndefined StatusPriorityEnum = "UNDEFINED"
+	StatusPriorityEnumNone      StatusPriorityEnum = "NONE"
+	StatusPriorityEnumLow       StatusPriorityEnum = "LOW"
+	StatusPriorityEnumNormal    StatusPriorityEnum = "NORMAL"
+	StatusPriorityEnumHigh      StatusPriorityEnum = "HIGH"
+	StatusPriorityEnumVeryHigh  StatusPriorityEnum = "VERY_HIGH"
+)
+
+// StatusUrgencyEnum são as opções de urgência.
+type StatusUrgencyEnum string
+
+const (
+	StatusUrgencyEnumUndefined  StatusUrgencyEnum = "UNDEFINED"
+	StatusUrgencyEnumNone       StatusUrgencyEnum = "NONE"
+	StatusUrgencyEnumNormal     StatusUrgencyEnum = "NORMAL"
+	StatusUrgencyEnumUrgent     StatusUrgencyEnum = "URGENT"
+	StatusUrgencyEnumVeryUrgent StatusUrgencyEnum = "VERY_URGENT"
+)
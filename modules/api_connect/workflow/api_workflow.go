@@ -0,0 +1,578 @@
+// Package workflow controla as transições de status dos fluxos de aprovação (StatusEvaluationEnum, StatusVerifiedEnum
+// ou fluxos próprios dos módulos): transições permitidas, condições (papéis do usuário, campos obrigatórios e funções),
+// hooks executados antes e depois da transição e o registro de cada transição no ApiHistory do documento.
+//
+// Os fluxos são registrados para a coleção/tabela de um módulo e executados pela mutação apiTransition:
+//
+//	service := workflow.NewService(workflow.NewMongoStore(db, "app"))
+//	service.Register("app/account", workflow.Target{Name: "account", UseObjectID: true}, workflow.Evaluation("evaluation"))
+//	workflow.SetDefault(service)
+package workflow
+
+import (
+	"context"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/i18n"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/status"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const module = "api_connect/workflow"
+
+// RoleAdmin é o papel dos administradores (User.IsAdmin).
+const RoleAdmin = "ADMIN"
+
+// Undefined é o status dos documentos sem o campo do fluxo.
+const Undefined = "UNDEFINED"
+
+// Guard é uma condição adicional da transição. Retorna um erro para impedir a transição.
+type Guard func(ctx context.Context, event Event) error
+
+// Hook é executado antes ou depois da transição. Um erro em um hook Before impede a transição.
+// Os hooks Before são executados uma vez por transição, mesmo quando a gravação é repetida por conflito de versão;
+// se o status do documento for alterado por outra operação, são executados novamente para a nova transição.
+type Hook func(ctx context.Context, event Event) error
+
+// Transition é uma transição permitida do fluxo.
+type Transition struct {
+	// Name é o nome da transição, registrado no histórico como <fluxo>.<nome>, por exemplo evaluation.approve.
+	Name string
+
+	// From são os status de origem permitidos. UNDEFINED representa os documentos sem o campo do fluxo.
+	From []string
+
+	// To é o status de destino.
+	To string
+
+	// Roles são os papéis aceitos para executar a transição. Se vazio, somente o dono do documento (ApiInfo.owner)
+	// e os administradores podem executá-la, exceto quando AnyUser é verdadeiro.
+	Roles []string
+
+	// AnyUser permite a transição sem Roles a qualquer usuário autenticado, e não somente ao dono.
+	AnyUser bool
+
+	// Required são os campos que precisam estar preenchidos no documento.
+	Required []string
+
+	// Guards são as condições adicionais da transição.
+	Guards []Guard
+}
+
+// Workflow é a definição declarativa de um fluxo sobre um campo do documento.
+type Workflow struct {
+	// Name é o nome do fluxo, por exemplo evaluation.
+	Name string
+
+	// Field é o campo (MongoDB) ou coluna (MySQL) com o status do fluxo.
+	Field string
+
+	// Transitions são as transições permitidas.
+	Transitions []Transition
+
+	// Before são os hooks executados antes da gravação da transição.
+	Before []Hook
+
+	// After são os hooks executados depois da gravação da transição. Os erros são escritos no log padrão.
+	After []Hook
+}
+
+// Event é a transição em execução, informada às condições e aos hooks.
+type Event struct {
+	Workflow   string
+	Module     string
+	ID         string
+	From       string
+	To         string
+	Transition string
+
+	// Owner é o identificador do dono do documento (ApiInfo.owner).
+	Owner string
+
+	// Document são os valores do documento antes da transição.
+	Document map[string]interface{}
+
+	// Result é o resultado da gravação, informado somente aos hooks After.
+	Result *params.ApiChangeResult
+}
+
+// find retorna a transição para o status de destino a partir do status atual.
+func (w *Workflow) find(from string, to string) (*Transition, bool) {
+	for index := range w.Transitions {
+		transition := &w.Transitions[index]
+		if transition.To != to {
+			continue
+		}
+		for _, item := range transition.From {
+			if item == from {
+				return transition, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// targets retorna os status de destino permitidos a partir do status atual.
+func (w *Workflow) targets(from string) []string {
+	var result []string
+	for _, transition := range w.Transitions {
+		for _, item := range transition.From {
+			if item == from {
+				result = append(result, transition.To)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Evaluation retorna o fluxo de avaliação (StatusEvaluationEnum) sobre o campo informado:
+//
+//	request   UNDEFINED, NO_EVALUATION                         -> NO_DOCUMENTS     (dono)
+//	submit    UNDEFINED, NO_EVALUATION, NO_DOCUMENTS, REJECTED -> WAITING_APPROVAL (dono)
+//	evaluate  WAITING_APPROVAL                                 -> EVALUATION (ADMIN)
+//	approve   EVALUATION                                       -> APPROVED   (ADMIN)
+//	reject    EVALUATION                                       -> REJECTED   (ADMIN)
+func Evaluation(field string) *Workflow {
+	return &Workflow{
+		Name:  "evaluation",
+		Field: field,
+		Transitions: []Transition{
+			{
+				Name: "request",
+				From: evaluation(status.StatusEvaluationEnumUndefined, status.StatusEvaluationEnumNoEvaluation),
+				To:   string(status.StatusEvaluationEnumNoDocuments),
+			},
+			{
+				Name: "submit",
+				From: evaluation(status.StatusEvaluationEnumUndefined, status.StatusEvaluationEnumNoEvaluation, status.StatusEvaluationEnumNoDocuments, status.StatusEvaluationEnumRejected),
+				To:   string(status.StatusEvaluationEnumWaitingApproval),
+			},
+			{
+				Name:  "evaluate",
+				From:  evaluation(status.StatusEvaluationEnumWaitingApproval),
+				To:    string(status.StatusEvaluationEnumEvaluation),
+				Roles: []string{RoleAdmin},
+			},
+			{
+				Name:  "approve",
+				From:  evaluation(status.StatusEvaluationEnumEvaluation),
+				To:    string(status.StatusEvaluationEnumApproved),
+				Roles: []string{RoleAdmin},
+			},
+			{
+				Name:  "reject",
+				From:  evaluation(status.StatusEvaluationEnumEvaluation),
+				To:    string(status.StatusEvaluationEnumRejected),
+				Roles: []string{RoleAdmin},
+			},
+		},
+	}
+}
+
+// Verified retorna o fluxo de verificação de dados (StatusVerifiedEnum) sobre o campo informado:
+//
+//	request   UNDEFINED, AWAITING_DOCUMENTATION, DECLINED -> AWAITING_VERIFICATION  (dono)
+//	document  AWAITING_VERIFICATION                        -> AWAITING_DOCUMENTATION (ADMIN)
+//	approve   AWAITING_VERIFICATION                        -> APPROVED               (ADMIN)
+//	decline   AWAITING_VERIFICATION                        -> DECLINED               (ADMIN)
+func Verified(field string) *Workflow {
+	return &Workflow{
+		Name:  "verified",
+		Field: field,
+		Transitions: []Transition{
+			{
+				Name: "request",
+				From: verified(status.StatusVerifiedEnumUndefined, status.StatusVerifiedEnumAwaitingDocumentation, status.StatusVerifiedEnumDeclined),
+				To:   string(status.StatusVerifiedEnumAwaitingVerification),
+			},
+			{
+				Name:  "document",
+				From:  verified(status.StatusVerifiedEnumAwaitingVerification),
+				To:    string(status.StatusVerifiedEnumAwaitingDocumentation),
+				Roles: []string{RoleAdmin},
+			},
+			{
+				Name:  "approve",
+				From:  verified(status.StatusVerifiedEnumAwaitingVerification),
+				To:    string(status.StatusVerifiedEnumApproved),
+				Roles: []string{RoleAdmin},
+			},
+			{
+				Name:  "decline",
+				From:  verified(status.StatusVerifiedEnumAwaitingVerification),
+				To:    string(status.StatusVerifiedEnumDeclined),
+				Roles: []string{RoleAdmin},
+			},
+		},
+	}
+}
+
+// evaluation converte os status de avaliação para texto.
+func evaluation(values ...status.StatusEvaluationEnum) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, string(value))
+	}
+	return result
+}
+
+// verified converte os status de verificação para texto.
+func verified(values ...status.StatusVerifiedEnum) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, string(value))
+	}
+	return result
+}
+
+// Target é a coleção/tabela de um módulo.
+type Target struct {
+	// Name é o nome da coleção (MongoDB) ou tabela (MySQL).
+	Name string
+
+	// UseObjectID indica que os identificadores da coleção são ObjectID (MongoDB).
+	UseObjectID bool
+
+	// IDColumn é a coluna identificadora da tabela (MySQL). Se vazia, utiliza "id".
+	IDColumn string
+}
+
+// Document é o documento lido antes da transição.
+type Document struct {
+	// Values são os valores do documento.
+	Values map[string]interface{}
+
+	// Version é a versão atual (ApiInfo.version).
+	Version *int
+
+	// Owner é o identificador do dono do documento (ApiInfo.owner).
+	Owner string
+}
+
+// Store lê e altera os documentos em um banco de dados.
+type Store interface {
+	// Read retorna os valores, a versão e o dono do documento, ou um erro CodeNotFound.
+	Read(ctx context.Context, target Target, id string) (*Document, error)
+
+	// Update altera o campo do fluxo mantendo o ApiInfo, condicionado à versão esperada.
+	Update(ctx context.Context, target Target, id string, expected *params.ApiVersionInput, field string, value string, action info.Action) (*params.ApiChangeResult, error)
+}
+
+// registration é a coleção/tabela e os fluxos de um módulo.
+type registration struct {
+	target    Target
+	workflows map[string]*Workflow
+}
+
+// Service executa as transições dos fluxos dos módulos registrados.
+type Service struct {
+	store   Store
+	mu      sync.RWMutex
+	modules map[string]registration
+}
+
+// NewService cria um Service com o Store informado (NewMongoStore ou NewMysqlStore).
+func NewService(store Store) *Service {
+	return &Service{store: store, modules: map[string]registration{}}
+}
+
+// Register registra a coleção/tabela de um módulo, identificado pelo projeto/pacote (ex.: "app/account"), e os seus fluxos.
+func (s *Service) Register(moduleName string, target Target, workflows ...*Workflow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.modules[moduleName]
+	if !ok {
+		item = registration{workflows: map[string]*Workflow{}}
+	}
+	item.target = target
+	for _, workflow := range workflows {
+		item.workflows[workflow.Name] = workflow
+	}
+	s.modules[moduleName] = item
+}
+
+// ApiTransitionInput é a representação Go do input GraphQL ApiTransitionInput.
+type ApiTransitionInput struct {
+	Module   string `json:"module"`
+	Workflow string `json:"workflow"`
+	ID       string `json:"_id"`
+	To       string `json:"to"`
+	Version  *int   `json:"version"`
+}
+
+// ApiTransitionResult é a representação Go do tipo GraphQL ApiTransitionResult.
+type ApiTransitionResult struct {
+	ID         *string         `json:"_id"`
+	Workflow   string          `json:"workflow"`
+	Transition string          `json:"transition"`
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	Info       *params.ApiInfo `json:"_info"`
+}
+
+// ApiTransitionResponse é a representação Go do tipo GraphQL ApiTransitionResponse.
+type ApiTransitionResponse struct {
+	Success     bool                    `json:"success"`
+	Result      *ApiTransitionResult    `json:"result"`
+	Error       *api_error.ApiErrorType `json:"error"`
+	ElapsedTime string                  `json:"elapsedTime"`
+}
+
+// Transition executa a transição do documento para o status de destino.
+// Transições não permitidas retornam CodeFailedPrecondition com os status permitidos, papéis ausentes retornam
+// CodePermissionDenied e campos obrigatórios vazios retornam CodeInvalidArgument para cada campo.
+// Se a versão não for informada, a transição é repetida quando o documento é alterado por outra operação.
+func (s *Service) Transition(ctx context.Context, input ApiTransitionInput) (*ApiTransitionResult, error) {
+	s.mu.RLock()
+	item, ok := s.modules[input.Module]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "module", "módulo não registrado").
+			With("module", input.Module)
+	}
+	workflow, ok := item.workflows[input.Workflow]
+	if !ok {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "workflow", "fluxo não registrado").
+			With("module", input.Module).
+			With("workflow", input.Workflow)
+	}
+
+	before := map[string]bool{}
+	if input.Version != nil {
+		return s.apply(ctx, item.target, workflow, input, before)
+	}
+
+	var result *ApiTransitionResult
+	err := info.Retry(ctx, 0, 0, func(ctx context.Context) error {
+		var err error
+		result, err = s.apply(ctx, item.target, workflow, input, before)
+		return err
+	})
+	return result, err
+}
+
+// apply lê o documento, verifica a transição e as condições, executa os hooks e grava o novo status.
+// before registra as transições cujos hooks Before já foram executados nas tentativas anteriores.
+func (s *Service) apply(ctx context.Context, target Target, workflow *Workflow, input ApiTransitionInput, before map[string]bool) (*ApiTransitionResult, error) {
+	current, err := s.store.Read(ctx, target, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	document, version := current.Values, current.Version
+
+	from := Undefined
+	if value, ok := document[workflow.Field]; ok && value != nil {
+		if text := valueText(value); text != "" {
+			from = text
+		}
+	}
+
+	transition, ok := workflow.find(from, input.To)
+	if !ok {
+		return nil, api_error.New(api_error.CodeFailedPrecondition, module, "to", "transição não permitida").
+			With("workflow", workflow.Name).
+			With("id", input.ID).
+			With("from", from).
+			With("to", input.To).
+			With("allowed", workflow.targets(from))
+	}
+
+	event := Event{
+		Workflow:   workflow.Name,
+		Module:     input.Module,
+		ID:         input.ID,
+		From:       from,
+		To:         input.To,
+		Transition: transition.Name,
+		Owner:      current.Owner,
+		Document:   document,
+	}
+	if err = check(ctx, transition, event); err != nil {
+		return nil, err
+	}
+	if key := from + ">" + input.To; !before[key] {
+		for _, hook := range workflow.Before {
+			if err = hook(ctx, event); err != nil {
+				return nil, err
+			}
+		}
+		before[key] = true
+	}
+
+	var expected *params.ApiVersionInput
+	if input.Version != nil {
+		expected = &params.ApiVersionInput{Expected: *input.Version}
+	} else if version != nil {
+		expected = &params.ApiVersionInput{Expected: *version}
+	}
+	name := transition.Name
+	if name == "" {
+		name = strings.ToLower(input.To)
+	}
+	result, err := s.store.Update(ctx, target, input.ID, expected, workflow.Field, input.To, info.NewAction(workflow.Name+"."+name))
+	if err != nil {
+		return nil, err
+	}
+
+	event.Result = result
+	for _, hook := range workflow.After {
+		if err = hook(ctx, event); err != nil {
+			log.Printf("Falha no hook da transição %s.%s do documento %s: %v", workflow.Name, name, input.ID, err)
+		}
+	}
+
+	return &ApiTransitionResult{
+		ID:         result.ID,
+		Workflow:   workflow.Name,
+		Transition: name,
+		From:       from,
+		To:         input.To,
+		Info:       result.Info,
+	}, nil
+}
+
+// check verifica os papéis, ou o dono nas transições sem papéis, os campos obrigatórios e as condições adicionais da transição.
+func check(ctx context.Context, transition *Transition, event Event) error {
+	user, ok := middleware.ForContext(ctx)
+	if !ok {
+		return api_error.New(api_error.CodeUnauthenticated, module, "transition", "usuário não autenticado")
+	}
+	if len(transition.Roles) > 0 && !hasRole(Roles(ctx), transition.Roles) {
+		return api_error.New(api_error.CodePermissionDenied, module, "transition", "usuário sem papel para a transição").
+			With("transition", transition.Name).
+			With("roles", transition.Roles)
+	}
+	if len(transition.Roles) == 0 && !transition.AnyUser && !user.IsAdmin && (event.Owner == "" || event.Owner != user.ID) {
+		return api_error.New(api_error.CodePermissionDenied, module, "transition", "somente o dono do documento pode executar a transição").
+			With("transition", transition.Name).
+			With("id", event.ID)
+	}
+
+	var missing api_error.List
+	for _, field := range transition.Required {
+		if isEmpty(lookup(event.Document, field)) {
+			missing = append(missing, api_error.New(api_error.CodeInvalidArgument, module, field, "campo obrigatório para a transição").
+				With("field", field).
+				With("to", event.To))
+		}
+	}
+	if err := missing.Err(); err != nil {
+		return err
+	}
+
+	for _, guard := range transition.Guards {
+		if err := guard(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Roles retorna os papéis do usuário da sessão (User.Roles), incluindo RoleAdmin para administradores.
+func Roles(ctx context.Context) []string {
+	user, _ := middleware.ForContext(ctx)
+	roles := append([]string(nil), user.Roles...)
+	if user.IsAdmin && !hasRole(roles, []string{RoleAdmin}) {
+		roles = append(roles, RoleAdmin)
+	}
+	return roles
+}
+
+// hasRole verifica se algum dos papéis do usuário está entre os papéis aceitos.
+func hasRole(roles []string, accepted []string) bool {
+	for _, role := range roles {
+		for _, item := range accepted {
+			if role == item {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookup retorna o valor do campo pelo caminho com pontos (ex.: "documents.front").
+// Os documentos do MongoDB são convertidos para map[string]interface{} pelo MongoStore.
+func lookup(document map[string]interface{}, path string) interface{} {
+	var current interface{} = document
+	for _, key := range strings.Split(path, ".") {
+		values, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = values[key]
+	}
+	return current
+}
+
+// isEmpty verifica se o valor está vazio: nil, texto em branco ou lista vazia.
+func isEmpty(value interface{}) bool {
+	switch item := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(item) == ""
+	case []byte:
+		return len(item) == 0
+	case []interface{}:
+		return len(item) == 0
+	}
+	return false
+}
+
+// valueText converte o status armazenado para texto.
+func valueText(value interface{}) string {
+	switch item := value.(type) {
+	case string:
+		return item
+	case []byte:
+		return string(item)
+	}
+	return ""
+}
+
+// ApiTransitionMutation implementa a mutação apiTransition, retornando o envelope ApiTransitionResponse.
+// As transições rejeitadas são retornadas no campo error como ApiErrorType.
+func (s *Service) ApiTransitionMutation(ctx context.Context, input ApiTransitionInput) (*ApiTransitionResponse, error) {
+	_timeStart := time.Now()
+	_success := true
+	var _error *api_error.ApiErrorType
+
+	_result, err := s.Transition(ctx, input)
+	if err != nil {
+		_success = false
+		_error = api_error.ToType(i18n.Localize(ctx, err), module, "apiTransition")
+	}
+
+	_response := ApiTransitionResponse{
+		Result:      _result,
+		Error:       _error,
+		Success:     _success,
+		ElapsedTime: time.Since(_timeStart).String(),
+	}
+	return &_response, nil
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultService *Service
+)
+
+// SetDefault define o Service padrão, normalmente na inicialização do servidor.
+func SetDefault(service *Service) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultService = service
+}
+
+// Default retorna o Service padrão, ou nil se não foi definido.
+func Default() *Service {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultService
+}
@@ -0,0 +1,105 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoStore lê e altera os documentos em coleções do MongoDB.
+type MongoStore struct {
+	db       *mongo.MongoDB
+	database string
+}
+
+// NewMongoStore cria um Store para o banco de dados MongoDB informado.
+func NewMongoStore(db *mongo.MongoDB, database string) *MongoStore {
+	return &MongoStore{db: db, database: database}
+}
+
+// Read retorna os valores, a versão e o dono do documento.
+func (s *MongoStore) Read(ctx context.Context, target Target, id string) (*Document, error) {
+	key, err := mongoID(target, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var document bson.M
+	err = s.db.Collection(s.database, target.Name).FindOne(ctx, bson.D{{Key: "_id", Value: key}}).Decode(&document)
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return nil, api_error.New(api_error.CodeNotFound, module, "transition", "documento não encontrado").
+			With("id", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values, _ := plain(document).(map[string]interface{})
+	result := &Document{Values: values}
+	if current, ok := values[info.Field].(map[string]interface{}); ok {
+		switch value := current["version"].(type) {
+		case int32:
+			number := int(value)
+			result.Version = &number
+		case int64:
+			number := int(value)
+			result.Version = &number
+		}
+		result.Owner, _ = current["owner"].(string)
+	}
+	return result, nil
+}
+
+// Update altera o campo do fluxo pelo info.MongoWriter.
+func (s *MongoStore) Update(ctx context.Context, target Target, id string, expected *params.ApiVersionInput, field string, value string, action info.Action) (*params.ApiChangeResult, error) {
+	key, err := mongoID(target, id)
+	if err != nil {
+		return nil, err
+	}
+	writer := info.NewMongoWriter(s.db.Collection(s.database, target.Name))
+	return writer.UpdateByID(ctx, key, expected, bson.M{field: value}, action)
+}
+
+// mongoID converte o identificador para ObjectID quando a coleção utiliza ObjectID.
+func mongoID(target Target, id string) (interface{}, error) {
+	if !target.UseObjectID {
+		return id, nil
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, api_error.New(api_error.CodeInvalidArgument, module, "_id", "identificador inválido").
+			With("id", id)
+	}
+	return objectID, nil
+}
+
+// plain converte os documentos e listas BSON para map[string]interface{} e []interface{}.
+func plain(value interface{}) interface{} {
+	switch item := value.(type) {
+	case bson.M:
+		result := make(map[string]interface{}, len(item))
+		for key, field := range item {
+			result[key] = plain(field)
+		}
+		return result
+	case bson.D:
+		result := make(map[string]interface{}, len(item))
+		for _, field := range item {
+			result[field.Key] = plain(field.Value)
+		}
+		return result
+	case bson.A:
+		result := make([]interface{}, 0, len(item))
+		for _, field := range item {
+			result = append(result, plain(field))
+		}
+		return result
+	}
+	return value
+}
@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"context"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"github.com/coocree/coocree_apiconnect_go/mysql"
+	"strconv"
+)
+
+// MysqlStore lê e altera as linhas em tabelas do MySQL.
+type MysqlStore struct {
+	db *mysql.MysqlDB
+}
+
+// NewMysqlStore cria um Store para o MysqlDB informado.
+func NewMysqlStore(db *mysql.MysqlDB) *MysqlStore {
+	return &MysqlStore{db: db}
+}
+
+// Read retorna os valores, a versão e o dono da linha.
+func (s *MysqlStore) Read(ctx context.Context, target Target, id string) (*Document, error) {
+	table, err := mysql.QuoteIdentifier(target.Name)
+	if err != nil {
+		return nil, err
+	}
+	idColumn, err := mysql.QuoteIdentifier(mysqlIDColumn(target))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Client().QueryContext(ctx, "SELECT * FROM "+table+" WHERE "+idColumn+" = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items, err := mysql.ScanMaps(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, api_error.New(api_error.CodeNotFound, module, "transition", "documento não encontrado").
+			With("id", id)
+	}
+
+	row := items[0]
+	result := &Document{Values: row}
+	switch value := row[info.ColumnVersion].(type) {
+	case int64:
+		number := int(value)
+		result.Version = &number
+	case string:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		result.Version = &number
+	}
+	result.Owner, _ = row[info.ColumnOwner].(string)
+	return result, nil
+}
+
+// Update altera a coluna do fluxo pelo info.MysqlWriter.
+func (s *MysqlStore) Update(ctx context.Context, target Target, id string, expected *params.ApiVersionInput, field string, value string, action info.Action) (*params.ApiChangeResult, error) {
+	writer := info.NewMysqlWriter(s.db, target.Name, mysqlIDColumn(target))
+	return writer.Update(ctx, id, expected, map[string]interface{}{field: value}, action)
+}
+
+// mysqlIDColumn retorna a coluna identificadora da tabela.
+func mysqlIDColumn(target Target) string {
+	if target.IDColumn == "" {
+		return "id"
+	}
+	return target.IDColumn
+}
@@ -0,0 +1,273 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"github.com/coocree/coocree_apiconnect_go/middleware"
+	api_error "github.com/coocree/coocree_apiconnect_go/modules/api_connect/error"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/info"
+	"github.com/coocree/coocree_apiconnect_go/modules/api_connect/params"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// authenticator identifica todas as requisições com o usuário informado.
+type authenticator struct {
+	user middleware.User
+}
+
+func (a authenticator) Authenticate(http.ResponseWriter, *http.Request) (middleware.User, bool, error) {
+	return a.user, true, nil
+}
+
+// contextFor retorna o contexto de uma requisição autenticada pelo middleware com o usuário informado.
+func contextFor(user middleware.User) context.Context {
+	var ctx context.Context
+	handler := middleware.Authenticate(authenticator{user: user})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	return ctx
+}
+
+// memoryStore guarda um documento e registra as ações gravadas.
+// conflicts é o número de gravações que retornam conflito de versão, executando onConflict antes de cada uma.
+type memoryStore struct {
+	document   *Document
+	actions    []string
+	conflicts  int
+	onConflict func(document *Document)
+}
+
+func (s *memoryStore) Read(ctx context.Context, target Target, id string) (*Document, error) {
+	if s.document == nil {
+		return nil, api_error.New(api_error.CodeNotFound, module, "id", "documento não encontrado")
+	}
+	values := map[string]interface{}{}
+	for key, value := range s.document.Values {
+		values[key] = value
+	}
+	return &Document{Values: values, Version: s.document.Version, Owner: s.document.Owner}, nil
+}
+
+func (s *memoryStore) Update(ctx context.Context, target Target, id string, expected *params.ApiVersionInput, field string, value string, action info.Action) (*params.ApiChangeResult, error) {
+	if s.conflicts > 0 {
+		s.conflicts--
+		if s.onConflict != nil {
+			s.onConflict(s.document)
+		}
+		return nil, api_error.New(api_error.CodeConflict, module, "version", "o documento foi alterado por outra operação")
+	}
+	s.document.Values[field] = value
+	s.actions = append(s.actions, action.Name)
+	return &params.ApiChangeResult{ID: &id}, nil
+}
+
+// newService registra o módulo app/account com o fluxo informado sobre um documento do dono user-1.
+func newService(workflow *Workflow, values map[string]interface{}) (*Service, *memoryStore) {
+	version := 1
+	store := &memoryStore{document: &Document{Values: values, Version: &version, Owner: "user-1"}}
+	service := NewService(store)
+	service.Register("app/account", Target{Name: "account"}, workflow)
+	return service, store
+}
+
+func TestEvaluation(t *testing.T) {
+	owner := contextFor(middleware.User{ID: "user-1"})
+	admin := contextFor(middleware.User{ID: "admin", IsAdmin: true})
+	other := contextFor(middleware.User{ID: "user-2"})
+
+	tests := []struct {
+		from       interface{}
+		to         string
+		ctx        context.Context
+		transition string
+		code       string
+	}{
+		{nil, "NO_DOCUMENTS", owner, "request", ""},
+		{"NO_EVALUATION", "NO_DOCUMENTS", owner, "request", ""},
+		{"", "WAITING_APPROVAL", owner, "submit", ""},
+		{"NO_DOCUMENTS", "WAITING_APPROVAL", owner, "submit", ""},
+		{"REJECTED", "WAITING_APPROVAL", owner, "submit", ""},
+		{"REJECTED", "WAITING_APPROVAL", admin, "submit", ""},
+		{"REJECTED", "WAITING_APPROVAL", other, "", api_error.CodePermissionDenied},
+		{"WAITING_APPROVAL", "EVALUATION", admin, "evaluate", ""},
+		{"WAITING_APPROVAL", "EVALUATION", owner, "", api_error.CodePermissionDenied},
+		{"EVALUATION", "APPROVED", admin, "approve", ""},
+		{"EVALUATION", "REJECTED", admin, "reject", ""},
+		{"EVALUATION", "APPROVED", owner, "", api_error.CodePermissionDenied},
+		{"WAITING_APPROVAL", "APPROVED", admin, "", api_error.CodeFailedPrecondition},
+		{"APPROVED", "WAITING_APPROVAL", owner, "", api_error.CodeFailedPrecondition},
+		{"APPROVED", "REJECTED", admin, "", api_error.CodeFailedPrecondition},
+		{"NO_DOCUMENTS", "NO_DOCUMENTS", owner, "", api_error.CodeFailedPrecondition},
+		{nil, "UNKNOWN", admin, "", api_error.CodeFailedPrecondition},
+		{nil, "NO_DOCUMENTS", context.Background(), "", api_error.CodeUnauthenticated},
+	}
+	for _, test := range tests {
+		from, _ := test.from.(string)
+		t.Run(from+">"+test.to, func(t *testing.T) {
+			values := map[string]interface{}{}
+			if test.from != nil {
+				values["evaluation"] = test.from
+			}
+			service, store := newService(Evaluation("evaluation"), values)
+
+			result, err := service.Transition(test.ctx, ApiTransitionInput{Module: "app/account", Workflow: "evaluation", ID: "1", To: test.to})
+			if test.code != "" {
+				if !errors.Is(err, api_error.New(test.code, "", "")) {
+					t.Errorf("Transition = %v, want %s", err, test.code)
+				}
+				if len(store.actions) != 0 {
+					t.Errorf("store recorded %v", store.actions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Transition: %v", err)
+			}
+			if result.Transition != test.transition || result.To != test.to || store.document.Values["evaluation"] != test.to {
+				t.Errorf("Transition = %+v, want %s to %s", result, test.transition, test.to)
+			}
+			if want := []string{"evaluation." + test.transition}; !reflect.DeepEqual(store.actions, want) {
+				t.Errorf("actions = %v, want %v", store.actions, want)
+			}
+		})
+	}
+}
+
+func TestVerifiedTargets(t *testing.T) {
+	workflow := Verified("verified")
+	tests := []struct {
+		from string
+		want []string
+	}{
+		{"UNDEFINED", []string{"AWAITING_VERIFICATION"}},
+		{"DECLINED", []string{"AWAITING_VERIFICATION"}},
+		{"AWAITING_DOCUMENTATION", []string{"AWAITING_VERIFICATION"}},
+		{"AWAITING_VERIFICATION", []string{"AWAITING_DOCUMENTATION", "APPROVED", "DECLINED"}},
+		{"APPROVED", nil},
+	}
+	for _, test := range tests {
+		if got := workflow.targets(test.from); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("targets(%s) = %v, want %v", test.from, got, test.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	reviewer := contextFor(middleware.User{ID: "user-3", Roles: []string{"REVIEWER"}})
+	errGuard := api_error.New(api_error.CodeFailedPrecondition, module, "guard", "condição não atendida")
+
+	tests := []struct {
+		name       string
+		transition Transition
+		ctx        context.Context
+		document   map[string]interface{}
+		code       string
+		count      int
+	}{
+		{name: "role", transition: Transition{Roles: []string{"REVIEWER"}}, ctx: reviewer},
+		{name: "missing role", transition: Transition{Roles: []string{"MANAGER"}}, ctx: reviewer, code: api_error.CodePermissionDenied},
+		{name: "admin role", transition: Transition{Roles: []string{RoleAdmin}}, ctx: contextFor(middleware.User{ID: "admin", IsAdmin: true})},
+		{name: "any user", transition: Transition{AnyUser: true}, ctx: reviewer},
+		{name: "not the owner", transition: Transition{}, ctx: reviewer, code: api_error.CodePermissionDenied},
+		{
+			name:       "required fields",
+			transition: Transition{AnyUser: true, Required: []string{"name", "documents.front", "documents.back", "tags"}},
+			ctx:        reviewer,
+			document:   map[string]interface{}{"name": " ", "documents": map[string]interface{}{"front": "f.png"}, "tags": []interface{}{}},
+			code:       api_error.CodeInvalidArgument,
+			count:      3,
+		},
+		{
+			name:       "required fields filled",
+			transition: Transition{AnyUser: true, Required: []string{"name", "documents.front", "active"}},
+			ctx:        reviewer,
+			document:   map[string]interface{}{"name": "Ana", "documents": map[string]interface{}{"front": "f.png"}, "active": false},
+		},
+		{
+			name:       "guard",
+			transition: Transition{AnyUser: true, Guards: []Guard{func(ctx context.Context, event Event) error { return errGuard }}},
+			ctx:        reviewer,
+			code:       api_error.CodeFailedPrecondition,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := check(test.ctx, &test.transition, Event{ID: "1", Owner: "user-1", To: "DONE", Document: test.document})
+			if test.code == "" {
+				if err != nil {
+					t.Errorf("check = %v", err)
+				}
+				return
+			}
+			if test.count > 0 {
+				var list api_error.List
+				if !errors.As(err, &list) || len(list) != test.count || !errors.Is(list[0], api_error.New(test.code, "", "")) {
+					t.Errorf("check = %v, want %d %s errors", err, test.count, test.code)
+				}
+				return
+			}
+			if !errors.Is(err, api_error.New(test.code, "", "")) {
+				t.Errorf("check = %v, want %s", err, test.code)
+			}
+		})
+	}
+}
+
+func TestHooks(t *testing.T) {
+	owner := contextFor(middleware.User{ID: "user-1"})
+	var before, after []string
+	workflow := Evaluation("evaluation")
+	workflow.Before = []Hook{func(ctx context.Context, event Event) error {
+		before = append(before, event.From+">"+event.To)
+		return nil
+	}}
+	workflow.After = []Hook{func(ctx context.Context, event Event) error {
+		after = append(after, *event.Result.ID)
+		return errors.New("falha no hook")
+	}}
+
+	// A gravação repetida por conflito não executa os hooks Before novamente
+	service, store := newService(workflow, map[string]interface{}{"evaluation": "NO_DOCUMENTS"})
+	store.conflicts = 2
+	if _, err := service.Transition(owner, ApiTransitionInput{Module: "app/account", Workflow: "evaluation", ID: "1", To: "WAITING_APPROVAL"}); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if !reflect.DeepEqual(before, []string{"NO_DOCUMENTS>WAITING_APPROVAL"}) || !reflect.DeepEqual(after, []string{"1"}) {
+		t.Errorf("hooks before %v after %v", before, after)
+	}
+
+	// A alteração do status por outra operação executa os hooks Before da nova transição
+	workflow.After = nil
+	before = nil
+	service, store = newService(workflow, map[string]interface{}{"evaluation": "NO_EVALUATION"})
+	store.conflicts = 1
+	store.onConflict = func(document *Document) { document.Values["evaluation"] = "REJECTED" }
+	if _, err := service.Transition(owner, ApiTransitionInput{Module: "app/account", Workflow: "evaluation", ID: "1", To: "WAITING_APPROVAL"}); err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if want := []string{"NO_EVALUATION>WAITING_APPROVAL", "REJECTED>WAITING_APPROVAL"}; !reflect.DeepEqual(before, want) {
+		t.Errorf("hooks before = %v, want %v", before, want)
+	}
+
+	// Com a versão informada, o conflito é retornado sem repetir a transição
+	version := 1
+	service, store = newService(workflow, map[string]interface{}{"evaluation": "NO_DOCUMENTS"})
+	store.conflicts = 1
+	if _, err := service.Transition(owner, ApiTransitionInput{Module: "app/account", Workflow: "evaluation", ID: "1", To: "WAITING_APPROVAL", Version: &version}); !info.IsConflict(err) {
+		t.Errorf("Transition with version = %v, want CONFLICT", err)
+	}
+
+	// Um erro em um hook Before impede a transição
+	workflow.Before = []Hook{func(ctx context.Context, event Event) error {
+		return api_error.New(api_error.CodeFailedPrecondition, module, "hook", "bloqueado")
+	}}
+	service, store = newService(workflow, map[string]interface{}{"evaluation": "NO_DOCUMENTS"})
+	response, err := service.ApiTransitionMutation(owner, ApiTransitionInput{Module: "app/account", Workflow: "evaluation", ID: "1", To: "WAITING_APPROVAL"})
+	if err != nil || response.Success || response.Error == nil || len(store.actions) != 0 {
+		t.Errorf("ApiTransitionMutation with a failed hook = %+v, %v", response, err)
+	}
+}
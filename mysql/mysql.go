@@ -3,9 +3,15 @@ package mysql
 
 import (
 	"database/sql"
+	"fmt"
 	_ "github.com/go-sql-driver/mysql" // Importa o driver MySQL sem atribuir um nome de pacote
+	"regexp"
+	"strings"
 )
 
+// regexIdentifier valida nomes de tabelas e colunas, opcionalmente qualificados (tabela.coluna).
+var regexIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
 // MysqlDB é uma estrutura que contém um cliente *sql.DB.
 type MysqlDB struct {
 	client *sql.DB
@@ -28,6 +34,14 @@ func NewDB(dsn string) (*MysqlDB, error) {
 	}, nil
 }
 
+// NewDBFromClient cria o adaptador MySQL a partir de um cliente *sql.DB já aberto, por exemplo
+// com outro driver compatível nos testes.
+func NewDBFromClient(client *sql.DB) *MysqlDB {
+	return &MysqlDB{
+		client: client,
+	}
+}
+
 // connect é uma função auxiliar que estabelece uma conexão com o banco de dados MySQL.
 // Recebe uma string de conexão (DSN) como argumento e retorna um cliente *sql.DB e um erro, se houver algum.
 func connect(dsn string) (*sql.DB, error) {
@@ -53,3 +67,44 @@ func (db *MysqlDB) Close() error {
 	// Fecha a conexão com o cliente MySQL
 	return db.client.Close()
 }
+
+// QuoteIdentifier valida e escapa um nome de tabela ou coluna para uso em consultas SQL.
+// Retorna um erro se o nome contiver caracteres não permitidos.
+func QuoteIdentifier(name string) (string, error) {
+	if !regexIdentifier.MatchString(name) {
+		return "", fmt.Errorf("identificador MySQL inválido: %q", name)
+	}
+	return "`" + strings.ReplaceAll(name, ".", "`.`") + "`", nil
+}
+
+// ScanMaps lê todas as linhas como map[string]interface{}, indexado pelo nome da coluna.
+// Valores []byte são convertidos para string.
+func ScanMaps(rows *sql.Rows) ([]map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for index := range values {
+			pointers[index] = &values[index]
+		}
+		if err = rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for index, column := range columns {
+			if raw, ok := values[index].([]byte); ok {
+				row[column] = string(raw)
+			} else {
+				row[column] = values[index]
+			}
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}